@@ -5,10 +5,13 @@ import (
 	"flag"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/apiserver"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/webhookserver"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -16,21 +19,84 @@ import (
 func main() {
 
 	port := flag.Int("port", 8000, "API Server Port. Default: 8000")
+	grpcport := flag.Int("grpcport", 0, "Port of the gRPC API of the API Server (0 disables it). Default: 0")
 	webhookport := flag.Int("whport", 8001, "WebHook Server Port. Default: 8001")
 	listrunningauth := flag.Bool("listingauth", false, "If true, /running requires authentication. Default: false")
+	namespaces := flag.String("namespaces", "", "Comma separated list of the namespaces that the API Server will serve. Default: all namespaces")
+	tlscertfile := flag.String("tlscert", "", "TLS certificate file of the API Server. Default: plain HTTP")
+	tlskeyfile := flag.String("tlskey", "", "TLS key file of the API Server. Default: plain HTTP")
+	corsorigins := flag.String("corsorigins", "", "Comma separated list of the origins that can call the API Server from a browser (* allows all origins). Default: none")
+	ratelimit := flag.Float64("ratelimit", 20, "Requests per second allowed for each caller (0 disables the limit). Default: 20")
+	rateburst := flag.Int("rateburst", 40, "Burst size of the requests of each caller. Default: 40")
+	dgsratelimit := flag.Float64("dgsratelimit", 1, "Status updates per second sent to the Kubernetes API for each DedicatedGameServer, the updates above it are delayed and merged (0 disables the limit). Default: 1")
+	dgsrateburst := flag.Int("dgsrateburst", 5, "Burst size of the updates of each DedicatedGameServer. Default: 5")
+	maxbodysize := flag.Int64("maxbodysize", 1<<20, "Maximum size of a request body in bytes (0 disables the limit). Default: 1048576")
+	auditlog := flag.String("auditlog", "", "File that the audit log of the mutating API calls is appended to (- for stdout). Default: no audit log")
+	accesscodegraceperiod := flag.Duration("accesscodegraceperiod", 10*time.Minute, "Period during which the previous access code is accepted after a rotation. Default: 10m")
+	sidecarimage := flag.String("sidecarimage", "", "Image of the SDK sidecar that the webhook adds to the DedicatedGameServerCollections with the SDKSidecar annotation. Default: none")
 
 	flag.Parse()
 
+	shared.AccessCodeGracePeriod = *accesscodegraceperiod
+
 	// listening OS shutdown singal
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
-	apiserver := apiserver.Run(*port, *listrunningauth)
-	webhookserver := webhookserver.Run("/certificate/cert.pem", "/certificate/key.pem", *webhookport)
+	// watch the access code and DGS token signing key Secrets, so they can be changed without restarting the API Server
+	stopCh := make(chan struct{})
+	client, _, err := shared.GetClientSet()
+	if err != nil {
+		log.Errorf("Cannot watch the access code Secrets due to: %v", err)
+	} else {
+		shared.WatchAccessCodes(client, stopCh)
+		shared.WatchDGSTokenSigningKeys(client, stopCh)
+	}
+
+	var auditSink apiserver.AuditSink
+	if *auditlog != "" {
+		auditSink, err = apiserver.NewFileAuditSink(*auditlog)
+		if err != nil {
+			log.Fatalf("Cannot open audit log: %v", err)
+		}
+	}
+
+	apiserver, err := apiserver.Run(apiserver.Config{
+		Port:               *port,
+		GRPCPort:           *grpcport,
+		ListRunningAuth:    *listrunningauth,
+		Namespaces:         parseList(*namespaces),
+		TLSCertFile:        *tlscertfile,
+		TLSKeyFile:         *tlskeyfile,
+		CORSAllowedOrigins: parseList(*corsorigins),
+		RateLimit:          *ratelimit,
+		RateBurst:          *rateburst,
+		DGSRateLimit:       *dgsratelimit,
+		DGSRateBurst:       *dgsrateburst,
+		MaxBodySize:        *maxbodysize,
+		AuditSink:          auditSink,
+		StopCh:             stopCh,
+	})
+	if err != nil {
+		log.Fatalf("Cannot start API Server: %v", err)
+	}
+	webhookserver := webhookserver.Run("/certificate/cert.pem", "/certificate/key.pem", *webhookport, *sidecarimage)
 
 	<-signalChan
 
 	log.Infof("Got OS shutdown signal, shutting down webhook and API servers gracefully...")
+	close(stopCh)
 	apiserver.Shutdown(context.Background())
 	webhookserver.Shutdown(context.Background())
 }
+
+// parseList returns the items of the comma separated list
+func parseList(list string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller/autoscale"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller/dgs"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller/dgscollection"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller/notifications"
 	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 	signals "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/signals"
 
@@ -24,6 +25,8 @@ import (
 func main() {
 	podautoscalerenabled := flag.Bool("podautoscaler", false, "Determines whether Pod AutoScaler is enabled. Default: false")
 	controllerthreadiness := flag.Int("controllerthreadiness", 1, "Controller Threadiness. Default: 1")
+	notificationconfig := flag.String("notificationconfig", "", "File with the webhook subscriptions that are notified about DedicatedGameServer lifecycle transitions. Default: none")
+	notificationworkers := flag.Int("notificationworkers", 2, "Number of workers that deliver the webhook notifications. Default: 2")
 
 	flag.Parse()
 
@@ -55,9 +58,19 @@ func main() {
 		return
 	}
 
+	var notifier *notifications.Notifier
+	if *notificationconfig != "" {
+		config, err := notifications.LoadConfig(*notificationconfig)
+		if err != nil {
+			log.Panicf("Cannot load notification config due to %s", err.Error())
+		}
+		notifier = notifications.NewNotifier(config)
+		go notifier.Run(*notificationworkers, stopCh)
+	}
+
 	dgsController := dgs.NewDedicatedGameServerController(client, dgsclient,
 		dgsSharedInformerFactory.Azuregaming().V1alpha1().DedicatedGameServers(),
-		sharedInformerFactory.Core().V1().Pods(), sharedInformerFactory.Core().V1().Nodes(), portRegistry)
+		sharedInformerFactory.Core().V1().Pods(), sharedInformerFactory.Core().V1().Nodes(), portRegistry, notifier, clockwork.NewRealClock())
 
 	controllers := []controllerHelper{dgsColController, dgsController}
 
@@ -68,6 +81,9 @@ func main() {
 		controllers = append(controllers, podAutoscalerController)
 	}
 
+	// watch the DGS token signing key Secrets, so a deleted key is re-created
+	shared.WatchDGSTokenSigningKeys(client, stopCh)
+
 	go sharedInformerFactory.Start(stopCh)
 	go dgsSharedInformerFactory.Start(stopCh)
 
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/logprocessor"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk"
+	signals "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/signals"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	config := flag.String("config", "", "YAML or JSON file with the rules that map the log lines to actions. Required")
+	file := flag.String("file", "", "Log file that is tailed. Default: the lines are read from stdin and echoed to stdout")
+	statefile := flag.String("statefile", "", "File that the player count and the position in the log file are saved to, so they survive restarts. Default: none")
+	pollinterval := flag.Duration("pollinterval", 250*time.Millisecond, "Interval that the tailed file is checked for new lines at. Default: 250ms")
+
+	flag.Parse()
+
+	if *config == "" {
+		log.Fatal("The --config argument is required")
+	}
+	rules, err := logprocessor.LoadConfig(*config)
+	if err != nil {
+		log.Fatalf("Cannot load the rules due to: %v", err)
+	}
+
+	s, err := sdk.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Cannot initialize the SDK due to: %v", err)
+	}
+	defer s.Close()
+
+	processor, err := logprocessor.NewProcessor(s, rules, *statefile)
+	if err != nil {
+		log.Fatalf("Cannot initialize the log processor due to: %v", err)
+	}
+
+	// set up signals so we handle the first shutdown signal gracefully
+	stopCh := signals.SetupSignalHandler()
+
+	if *file != "" {
+		if err := processor.Tail(*file, *pollinterval, stopCh); err != nil {
+			log.Fatalf("Cannot process %s due to: %v", *file, err)
+		}
+		return
+	}
+
+	// the game server log is piped to the processor, which stops when the game server exits
+	processor.Echo = os.Stdout
+	done := make(chan error, 1)
+	go func() {
+		done <- processor.Process(os.Stdin)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Fatalf("Cannot process stdin due to: %v", err)
+		}
+	case <-stopCh:
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sidecar"
+	signals "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/signals"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	port := flag.Int("port", sidecar.DefaultPort, fmt.Sprintf("Port that the sidecar listens at on localhost. Default: %d", sidecar.DefaultPort))
+	grpcport := flag.Int("grpcport", sidecar.DefaultGRPCPort, fmt.Sprintf("Port of the gRPC API of the sidecar on localhost (0 disables it). Default: %d", sidecar.DefaultGRPCPort))
+	batchinterval := flag.Duration("batchinterval", 0, "Time that the updates are collected for before they are sent to the API Server. Default: 100ms")
+	healthpinginterval := flag.Duration("healthpinginterval", 0, "Interval that the Health of the DedicatedGameServer is reported at. Default: the heartbeat period of the DedicatedGameServer's HealthCheck, or 30s")
+	healthtimeout := flag.Duration("healthtimeout", 0, "Time after the last health ping of the game server that the DedicatedGameServer is reported as Failed. Default: 1m")
+
+	flag.Parse()
+
+	sdkConfig, err := sdk.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Cannot initialize the SDK due to: %v", err)
+	}
+	s, err := sdk.New(sdkConfig)
+	if err != nil {
+		log.Fatalf("Cannot initialize the SDK due to: %v", err)
+	}
+	// DedicatedGameServers with a HealthCheck expect heartbeats at the period of the HealthCheck
+	if *healthpinginterval == 0 && sdkConfig.Heartbeats {
+		*healthpinginterval = sdkConfig.HealthPingInterval
+	}
+
+	// set up signals so we handle the first shutdown signal gracefully
+	stopCh := signals.SetupSignalHandler()
+
+	sidecarServer := sidecar.NewServer(s, sidecar.Config{
+		BatchInterval:      *batchinterval,
+		HealthPingInterval: *healthpinginterval,
+		HealthTimeout:      *healthtimeout,
+		Heartbeats:         sdkConfig.Heartbeats,
+	})
+	runStopCh := make(chan struct{})
+	runDone := make(chan struct{})
+	go func() {
+		sidecarServer.Run(runStopCh)
+		close(runDone)
+	}()
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf("127.0.0.1:%d", *port),
+		Handler: sidecarServer.Handler(),
+	}
+	go func() {
+		log.Infof("SDK sidecar listening at %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Cannot start the SDK sidecar: %v", err)
+		}
+	}()
+
+	var grpcServer *grpc.Server
+	if *grpcport > 0 {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", *grpcport))
+		if err != nil {
+			log.Fatalf("Cannot start the gRPC API of the SDK sidecar: %v", err)
+		}
+		grpcServer = sidecarServer.GRPCServer()
+		go func() {
+			log.Infof("SDK sidecar gRPC API listening at %s", listener.Addr())
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Cannot start the gRPC API of the SDK sidecar: %v", err)
+			}
+		}()
+	}
+
+	<-stopCh
+
+	// stop accepting updates, then send the pending ones
+	log.Infof("Got OS shutdown signal, shutting down the SDK sidecar gracefully...")
+	server.Shutdown(context.Background())
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	close(runStopCh)
+	<-runDone
+}
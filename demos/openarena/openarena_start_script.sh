@@ -38,12 +38,11 @@ DAEMON_ARGS="$DEDICATED_ARG $SERVER_ARGS"
 
 DAEMON=/data/oa_ded.x86_64
 
-#initialize the connected file
-connected=0
-echo $connected > /tmp/connected
+#a new game server process has no players, so the player count of a previous run is discarded
+rm -f /tmp/logprocessor.state
 
 echo "Starting: $DAEMON $DAEMON_ARGS"
 #exec $DAEMON $DAEMON_ARGS
 #capturing line by line on bash
 #https://unix.stackexchange.com/questions/117501/in-bash-script-how-to-capture-stdout-line-by-line
-exec stdbuf -oL $DAEMON $DAEMON_ARGS 2>&1 | /opt/stdoutprocessor.sh
\ No newline at end of file
+exec stdbuf -oL $DAEMON $DAEMON_ARGS 2>&1 | /opt/logprocessor --config /opt/logprocessor.yaml --statefile /tmp/logprocessor.state
\ No newline at end of file
@@ -29,8 +29,22 @@ type DedicatedGameServer struct {
 
 // DedicatedGameServerSpec is the spec for a DedicatedGameServer resource
 type DedicatedGameServerSpec struct {
-	PortsToExpose []int32        `json:"portsToExpose"`
-	Template      corev1.PodSpec `json:"template"`
+	PortsToExpose       []int32                  `json:"portsToExpose"`
+	Template            corev1.PodSpec           `json:"template"`
+	AddressTypePriority []corev1.NodeAddressType `json:"addressTypePriority,omitempty"`
+	// HealthCheck makes the DGS Failed when its game server stops sending heartbeats, there is no heartbeat check if it is nil
+	HealthCheck *DGSHealthCheck `json:"healthCheck,omitempty"`
+}
+
+// DGSHealthCheck describes the heartbeats that a game server has to send to stay Healthy
+// The omitted fields get their default values
+type DGSHealthCheck struct {
+	// PeriodSeconds is the expected interval between two heartbeats, default is 10
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// FailureThreshold is the number of periods without a heartbeat after which the DGS is marked as Failed, default is 3
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+	// InitialDelaySeconds is the time after the start of the Pod during which no heartbeats are expected, default is 0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
 }
 
 // DedicatedGameServerStatus is the status for a DedicatedGameServer resource
@@ -40,8 +54,29 @@ type DedicatedGameServerStatus struct {
 	DGSState          DGSState        `json:"dgsState"`
 	MarkedForDeletion bool            `json:"markedForDeletion"`
 	PublicIP          string          `json:"publicIP"`
+	PublicIPv6        string          `json:"publicIPv6,omitempty"`
+	Addresses         []DGSAddress    `json:"addresses,omitempty"`
 	NodeName          string          `json:"nodeName"`
 	ActivePlayers     int             `json:"activePlayers"`
+	Ports             []DGSPort       `json:"ports,omitempty"`
+	// LastHeartbeat is the time of the last heartbeat of the game server
+	LastHeartbeat *meta_v1.Time `json:"lastHeartbeat,omitempty"`
+}
+
+// DGSAddress describes an address of the Node that the DedicatedGameServer is running on
+type DGSAddress struct {
+	Type     corev1.NodeAddressType `json:"type"`
+	Address  string                 `json:"address"`
+	IPFamily DGSIPFamily            `json:"ipFamily,omitempty"`
+}
+
+// DGSPort describes a port of the DedicatedGameServer and the way it is exposed
+type DGSPort struct {
+	Name          string              `json:"name,omitempty"`
+	Protocol      corev1.Protocol     `json:"protocol"`
+	ContainerPort int32               `json:"containerPort"`
+	HostPort      int32               `json:"hostPort,omitempty"`
+	ExposureMode  DGSPortExposureMode `json:"exposureMode"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
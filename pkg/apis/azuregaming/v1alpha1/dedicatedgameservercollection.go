@@ -36,6 +36,10 @@ type DedicatedGameServerCollectionSpec struct {
 	DGSFailBehavior                   DedicatedGameServerFailBehavior    `json:"dgsFailBehavior,omitempty"`
 	DGSMaxFailures                    int32                              `json:"dgsMaxFailures,omitempty"`
 	DGSActivePlayersAutoScalerDetails *DGSActivePlayersAutoScalerDetails `json:"dgsActivePlayersAutoScalerDetails,omitempty"`
+	// AddressTypePriority determines which Node address type is used for the DGS PublicIP, in order of preference
+	AddressTypePriority []corev1.NodeAddressType `json:"addressTypePriority,omitempty"`
+	// HealthCheck makes the DGSs of the collection Failed when their game servers stop sending heartbeats, it is copied to each DGS
+	HealthCheck *DGSHealthCheck `json:"healthCheck,omitempty"`
 }
 
 // DGSActivePlayersAutoScalerDetails contains details about the autoscaling of the dedicated game server collection
@@ -14,6 +14,19 @@ const (
 	DGSPostMatch DGSState = "PostMatch"
 )
 
+// AllDGSStates contains all the valid DGS States
+var AllDGSStates = []DGSState{DGSIdle, DGSAssigned, DGSRunning, DGSPostMatch}
+
+// IsValid returns true if the State is one of AllDGSStates
+func (s DGSState) IsValid() bool {
+	for _, state := range AllDGSStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
 // DGSHealth represents the DGS Health
 type DGSHealth string
 
@@ -26,6 +39,45 @@ const (
 	DGSFailed DGSHealth = "Failed"
 )
 
+// AllDGSHealths contains all the valid DGS Healths
+var AllDGSHealths = []DGSHealth{DGSCreating, DGSHealthy, DGSFailed}
+
+// IsValid returns true if the Health is one of AllDGSHealths
+func (h DGSHealth) IsValid() bool {
+	for _, health := range AllDGSHealths {
+		if h == health {
+			return true
+		}
+	}
+	return false
+}
+
+// DGSPortExposureMode represents the way a DGS port is exposed
+type DGSPortExposureMode string
+
+const (
+	// DGSPortHostPort represents a port that is exposed on the Node's Public IP via a HostPort
+	DGSPortHostPort DGSPortExposureMode = "HostPort"
+	// DGSPortInternal represents a port that is not exposed outside of the cluster
+	DGSPortInternal DGSPortExposureMode = "Internal"
+)
+
+// AllDGSPortExposureModes contains all the valid DGS port exposure modes
+var AllDGSPortExposureModes = []DGSPortExposureMode{DGSPortHostPort, DGSPortInternal}
+
+// DGSIPFamily represents the IP family of a DGS address
+type DGSIPFamily string
+
+const (
+	// DGSIPv4 represents an IPv4 address
+	DGSIPv4 DGSIPFamily = "IPv4"
+	// DGSIPv6 represents an IPv6 address
+	DGSIPv6 DGSIPFamily = "IPv6"
+)
+
+// AllDGSIPFamilies contains all the valid DGS IP families
+var AllDGSIPFamilies = []DGSIPFamily{DGSIPv4, DGSIPv6}
+
 // DGSColHealth represents the Health of the Collection. For it to be Healthy, all the DGS need to be Healthy
 type DGSColHealth string
 
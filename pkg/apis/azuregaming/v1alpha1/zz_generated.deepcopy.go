@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,13 +41,61 @@ func (in *DGSActivePlayersAutoScalerDetails) DeepCopy() *DGSActivePlayersAutoSca
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DGSAddress) DeepCopyInto(out *DGSAddress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DGSAddress.
+func (in *DGSAddress) DeepCopy() *DGSAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(DGSAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DGSHealthCheck) DeepCopyInto(out *DGSHealthCheck) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DGSHealthCheck.
+func (in *DGSHealthCheck) DeepCopy() *DGSHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(DGSHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DGSPort) DeepCopyInto(out *DGSPort) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DGSPort.
+func (in *DGSPort) DeepCopy() *DGSPort {
+	if in == nil {
+		return nil
+	}
+	out := new(DGSPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DedicatedGameServer) DeepCopyInto(out *DedicatedGameServer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -143,6 +192,16 @@ func (in *DedicatedGameServerCollectionSpec) DeepCopyInto(out *DedicatedGameServ
 		*out = new(DGSActivePlayersAutoScalerDetails)
 		**out = **in
 	}
+	if in.AddressTypePriority != nil {
+		in, out := &in.AddressTypePriority, &out.AddressTypePriority
+		*out = make([]v1.NodeAddressType, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(DGSHealthCheck)
+		**out = **in
+	}
 	return
 }
 
@@ -214,6 +273,16 @@ func (in *DedicatedGameServerSpec) DeepCopyInto(out *DedicatedGameServerSpec) {
 		copy(*out, *in)
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.AddressTypePriority != nil {
+		in, out := &in.AddressTypePriority, &out.AddressTypePriority
+		*out = make([]v1.NodeAddressType, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(DGSHealthCheck)
+		**out = **in
+	}
 	return
 }
 
@@ -230,6 +299,20 @@ func (in *DedicatedGameServerSpec) DeepCopy() *DedicatedGameServerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DedicatedGameServerStatus) DeepCopyInto(out *DedicatedGameServerStatus) {
 	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]DGSAddress, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]DGSPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastHeartbeat != nil {
+		in, out := &in.LastHeartbeat, &out.LastHeartbeat
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -0,0 +1,75 @@
+package apiserver
+
+import (
+	"net/http"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// dgsAllocateSubPath allocates one of the DedicatedGameServers of the namespace, it is not a DedicatedGameServer name
+const dgsAllocateSubPath = "/allocate"
+
+var allocateAttributes = authzAttributes{"update", dgsResource, "allocate"}
+
+// allocateDGSHandler allocates a ready DedicatedGameServer that passes the filters of the query parameters
+func allocateDGSHandler(w http.ResponseWriter, r *http.Request) {
+	options, err := parseDGSListOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dgs, err := allocateDGS(r, mux.Vars(r)["namespace"], options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgs)
+}
+
+// allocateDGS sets the state of an Idle DedicatedGameServer that is ready to accept players and passes the filters to Assigned, and returns it
+// The candidates are taken from the informer cache, in the order of the listing options. Each allocation is sent with the resourceVersion
+// of the cached DedicatedGameServer as a precondition, so a DedicatedGameServer that has been modified in the meantime
+// (e.g. allocated by another request) is not allocated twice, and the next candidate is tried instead
+// It returns a ServiceUnavailable error if no DedicatedGameServer could be allocated
+func allocateDGS(r *http.Request, namespace string, options *dgsListOptions) (*dgsv1alpha1.DedicatedGameServer, error) {
+	if options.state != "" && options.state != dgsv1alpha1.DGSIdle {
+		return nil, apierrors.NewBadRequest("only Idle DedicatedGameServers can be allocated")
+	}
+	options.state = dgsv1alpha1.DGSIdle
+	options.limit, options.after = 0, nil
+
+	dgss, err := readyDGSs(namespace, options.selector)
+	if err != nil {
+		return nil, err
+	}
+	candidates, _ := options.apply(dgss)
+
+	assigned := dgsv1alpha1.DGSAssigned
+	for i := range candidates {
+		candidate := &candidates[i]
+		dgs, err := statusUpdates.updateDGS(namespace, candidate.Name, shared.DGSStatusFields{
+			DGSState:        &assigned,
+			ResourceVersion: candidate.ResourceVersion,
+		})
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			log.WithField("DGSName", candidate.Name).Debugf("DedicatedGameServer has been modified since it was cached, trying the next one: %v", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the allocated DedicatedGameServer is only known now, the request was authorized for the namespace
+		if event := auditEvent(r); event != nil {
+			event.Name = dgs.Name
+		}
+		auditValues(r, candidate.Status, dgs.Status)
+		return dgs, nil
+	}
+	return nil, apierrors.NewServiceUnavailable("no DedicatedGameServer is available for allocation, please retry later")
+}
@@ -0,0 +1,126 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned/fake"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// newAllocatableDGS returns a ready DedicatedGameServer with the designated state and resourceVersion
+func newAllocatableDGS(name string, state dgsv1alpha1.DGSState, resourceVersion string) *dgsv1alpha1.DedicatedGameServer {
+	dgs := newCachedDGS("title1", name, dgsv1alpha1.DGSHealthy)
+	dgs.ResourceVersion = resourceVersion
+	dgs.Labels = map[string]string{}
+	dgs.Status.DGSState = state
+	dgs.Status.Ports = []dgsv1alpha1.DGSPort{{Name: "game", Protocol: corev1.ProtocolUDP, ContainerPort: 7777, HostPort: 20001}}
+	return dgs
+}
+
+// startAllocationCache caches the DedicatedGameServers and applies the status updates to copies of them
+func startAllocationCache(t *testing.T, stopCh chan struct{}, dgss ...*dgsv1alpha1.DedicatedGameServer) *fakeDGSUpdates {
+	updates := &fakeDGSUpdates{dgss: make(map[string]*dgsv1alpha1.DedicatedGameServer)}
+	objects := make([]runtime.Object, 0, len(dgss))
+	for _, dgs := range dgss {
+		updates.dgss[dgs.Name] = dgs.DeepCopy()
+		objects = append(objects, dgs)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	statusUpdates = newDGSUpdateCoalescer(updates.update, nil)
+	informerCache = newObjectCache(client, nil, stopCh)
+	assert.NoError(t, wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return informerCache.hasSynced(), nil
+	}))
+	return updates
+}
+
+func TestAllocateDGS(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	updates := startAllocationCache(t, stopCh,
+		newAllocatableDGS("dgs1", dgsv1alpha1.DGSIdle, "1"),
+		newAllocatableDGS("dgs2", dgsv1alpha1.DGSIdle, "2"),
+		newAllocatableDGS("dgs3", dgsv1alpha1.DGSRunning, "3"),
+	)
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		informerCache = nil
+	}()
+	// dgs1 has been modified since it was cached (e.g. allocated by another API Server)
+	updates.dgss["dgs1"].ResourceVersion = "4"
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/title1/dedicatedgameservers/allocate", nil)
+	options, err := parseDGSListOptions(r)
+	assert.NoError(t, err)
+	dgs, err := allocateDGS(r, "title1", options)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "dgs2", dgs.Name)
+		assert.Equal(t, dgsv1alpha1.DGSAssigned, dgs.Status.DGSState)
+		assert.Equal(t, int32(20001), dgs.Status.Ports[0].HostPort)
+	}
+	// the allocations are sent with the cached resourceVersion as a precondition
+	if assert.Len(t, updates.updates, 2) {
+		assert.Equal(t, "1", updates.updates[0].ResourceVersion)
+		assert.Equal(t, "2", updates.updates[1].ResourceVersion)
+	}
+	assert.Equal(t, dgsv1alpha1.DGSIdle, updates.dgss["dgs1"].Status.DGSState)
+
+	// the cache still contains the Idle dgs2, but its allocation conflicts
+	_, err = allocateDGS(r, "title1", options)
+	assert.Equal(t, http.StatusServiceUnavailable, toAPIError(err).Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/title1/dedicatedgameservers/allocate?state=Running", nil)
+	options, err = parseDGSListOptions(r)
+	assert.NoError(t, err)
+	_, err = allocateDGS(r, "title1", options)
+	assert.Equal(t, http.StatusBadRequest, toAPIError(err).Code)
+}
+
+func TestAllocateDGSRoute(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	startAllocationCache(t, stopCh,
+		newAllocatableDGS("dgs1", dgsv1alpha1.DGSIdle, "1"),
+		newAllocatableDGS("dgs2", dgsv1alpha1.DGSIdle, "2"),
+	)
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("update", &reviews)
+	sink := &fakeAuditSink{}
+	auditSink = sink
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		informerCache, tokenAuthenticator, auditSink = nil, nil, nil
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/title1/dedicatedgameservers/allocate?sortBy=-name", nil)
+	r.Header.Set("Authorization", "Bearer valid-token")
+	w := httptest.NewRecorder()
+	newRouter(false).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var dgs dgsv1alpha1.DedicatedGameServer
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &dgs))
+	assert.Equal(t, "dgs2", dgs.Name)
+	assert.Equal(t, dgsv1alpha1.DGSAssigned, dgs.Status.DGSState)
+	assert.Len(t, dgs.Status.Ports, 1)
+
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, "allocate", reviews[0].Spec.ResourceAttributes.Subresource)
+	}
+	if assert.Len(t, sink.events, 1) {
+		assert.Equal(t, "dgs2", sink.events[0].Name)
+		assert.Equal(t, "allocate", sink.events[0].Subresource)
+	}
+}
@@ -0,0 +1,250 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// accessCodeCaller is the caller of the requests that are authenticated with the access code of the namespace
+	accessCodeCaller = "access-code"
+	// dgsCallerPrefix is the prefix of the caller of the requests that are authenticated with the token of a DedicatedGameServer
+	dgsCallerPrefix = "dedicatedgameserver:"
+)
+
+// AuditEvent describes a mutating API call
+type AuditEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	// User is the caller of the request, i.e. the user of the bearer token, the DedicatedGameServer of the token
+	// or "access-code". It is empty if the request could not be authenticated
+	// For requests that modify several objects, it is empty unless all objects have the same caller (see Principals)
+	User     string `json:"user"`
+	SourceIP string `json:"sourceIP"`
+	Method   string `json:"method"`
+	// Route is the path template of the route, e.g. /api/v1/namespaces/{namespace}/dedicatedgameservers/{name}
+	Route string `json:"route,omitempty"`
+	// Path is the path of the request, or the full method name of a gRPC call (e.g. /azuregaming.apiserver.v1.DedicatedGameServers/SetState)
+	Path        string `json:"path"`
+	Verb        string `json:"verb,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	DryRun      bool   `json:"dryRun,omitempty"`
+	// Principals contains the caller of each object of a request that modifies several objects (e.g. a batch update)
+	Principals []AuditPrincipal `json:"principals,omitempty"`
+	// OldValue and NewValue contain the object (or the part of it that was modified) before and after the call
+	OldValue interface{} `json:"oldValue,omitempty"`
+	NewValue interface{} `json:"newValue,omitempty"`
+	// Code is the HTTP status code of the response and Result is "Success" or the reason of the error
+	Code    int    `json:"code"`
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+// AuditPrincipal is the caller of one of the objects of a request that modifies several objects
+type AuditPrincipal struct {
+	Name string `json:"name"`
+	// User is empty if the modification of the object could not be authenticated
+	User string `json:"user"`
+}
+
+// AuditSink receives the audit events of the API Server
+// Implementations must be safe for concurrent use
+type AuditSink interface {
+	Write(event *AuditEvent) error
+}
+
+// jsonLinesAuditSink writes each audit event as a line of JSON
+type jsonLinesAuditSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewJSONLinesAuditSink returns an AuditSink that writes each audit event as a line of JSON to the writer
+func NewJSONLinesAuditSink(writer io.Writer) AuditSink {
+	return &jsonLinesAuditSink{writer: writer}
+}
+
+// NewFileAuditSink returns an AuditSink that appends the audit events as lines of JSON to the designated file
+// "-" writes the audit events to stdout
+func NewFileAuditSink(path string) (AuditSink, error) {
+	if path == "-" {
+		return NewJSONLinesAuditSink(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLinesAuditSink(file), nil
+}
+
+func (s *jsonLinesAuditSink) Write(event *AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.writer.Write(append(line, '\n'))
+	return err
+}
+
+// auditSink receives the audit events, nil if auditing is disabled
+var auditSink AuditSink
+
+type auditContextKey struct{}
+
+// auditResponseWriter records the status code of the response for the AuditEvent
+type auditResponseWriter struct {
+	http.ResponseWriter
+	event *AuditEvent
+	code  int
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// audited wraps a mutating handler so that an AuditEvent is written to the auditSink after the request has been served
+// The handler (and the authentication) can complete the event via the request context
+func audited(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if auditSink == nil {
+			next(w, r)
+			return
+		}
+
+		event := &AuditEvent{
+			Timestamp: time.Now().UTC(),
+			SourceIP:  callerIP(r),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Namespace: mux.Vars(r)["namespace"],
+			Name:      mux.Vars(r)["name"],
+		}
+		if route := mux.CurrentRoute(r); route != nil {
+			event.Route, _ = route.GetPathTemplate()
+		}
+		event.DryRun, _ = isDryRun(r)
+
+		aw := &auditResponseWriter{ResponseWriter: w, event: event, code: http.StatusOK}
+		next(aw, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, event)))
+
+		event.Code = aw.code
+		if event.Result == "" {
+			event.Result = "Success"
+			if aw.code >= http.StatusBadRequest {
+				event.Result = http.StatusText(aw.code)
+			}
+		}
+		writeAuditEvent(event)
+	}
+}
+
+// auditedCall calls a mutating operation that is not served by an HTTP handler (e.g. a gRPC call) with the request,
+// so that an AuditEvent is written to the auditSink after the operation has returned, as audited does for the handlers
+func auditedCall(r *http.Request, call func(r *http.Request) error) error {
+	if auditSink == nil {
+		return call(r)
+	}
+
+	event := &AuditEvent{
+		Timestamp: time.Now().UTC(),
+		SourceIP:  callerIP(r),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+	}
+	err := call(r.WithContext(context.WithValue(r.Context(), auditContextKey{}, event)))
+
+	event.Code, event.Result = http.StatusOK, "Success"
+	if err != nil {
+		apiError := toAPIError(err)
+		event.Code, event.Result, event.Message = apiError.Code, apiError.Reason, apiError.Message
+	}
+	writeAuditEvent(event)
+	return err
+}
+
+func writeAuditEvent(event *AuditEvent) {
+	if err := auditSink.Write(event); err != nil {
+		log.Errorf("Cannot write audit event: %v", err)
+	}
+}
+
+// auditEvent returns the AuditEvent of the request, nil if the request is not audited
+func auditEvent(r *http.Request) *AuditEvent {
+	event, _ := r.Context().Value(auditContextKey{}).(*AuditEvent)
+	return event
+}
+
+// auditRequest records the caller and the target of the request
+func auditRequest(r *http.Request, user string, attrs authzAttributes, namespace string, name string) {
+	if event := auditEvent(r); event != nil {
+		event.User = user
+		event.Verb = attrs.verb
+		event.Resource = attrs.resource
+		event.Subresource = attrs.subresource
+		event.Namespace = namespace
+		if name != "" {
+			event.Name = name
+		}
+	}
+}
+
+// auditValues records the values before and after the request
+func auditValues(r *http.Request, oldValue interface{}, newValue interface{}) {
+	if event := auditEvent(r); event != nil {
+		event.OldValue = oldValue
+		event.NewValue = newValue
+	}
+}
+
+// auditBatchPrincipals records the caller of each object of a request that modifies several objects
+// The User of the request is set to the caller of all objects, or cleared if the objects have different callers
+func auditBatchPrincipals(r *http.Request, principals []AuditPrincipal) {
+	if event := auditEvent(r); event != nil {
+		event.Principals = principals
+		event.User = ""
+		for i, principal := range principals {
+			if i > 0 && principal.User != principals[0].User {
+				return
+			}
+		}
+		if len(principals) > 0 {
+			event.User = principals[0].User
+		}
+	}
+}
+
+// auditBatchValues records the values before and after a request that modifies several objects, keyed by their names
+func auditBatchValues(r *http.Request, oldValues map[string]interface{}, newValues map[string]interface{}) {
+	if event := auditEvent(r); event != nil {
+		// the name of the last authenticated object is not the target of the request
+		event.Name = ""
+		event.OldValue = oldValues
+		event.NewValue = newValues
+	}
+}
+
+// withoutAudit returns a context in which the request is not audited, e.g. for the parts of a request that is audited as a whole
+func withoutAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, (*AuditEvent)(nil))
+}
+
+// auditError records the reason and the message of the error that was returned to the caller
+func auditError(w http.ResponseWriter, reason string, message string) {
+	if aw, ok := w.(*auditResponseWriter); ok {
+		aw.event.Result = reason
+		aw.event.Message = message
+	}
+}
@@ -0,0 +1,165 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeAuditSink keeps the audit events in memory
+type fakeAuditSink struct {
+	mutex  sync.Mutex
+	events []*AuditEvent
+}
+
+func (s *fakeAuditSink) Write(event *AuditEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func serveAudited(attrs authzAttributes, token string, next http.HandlerFunc) (*fakeAuditSink, int) {
+	sink := &fakeAuditSink{}
+	auditSink = sink
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/namespaces/{namespace}/dedicatedgameservercollections/{name}", audited(authenticated(attrs, next)))
+
+	r := httptest.NewRequest(http.MethodDelete, "/api/v1/namespaces/title1/dedicatedgameservercollections/col1", nil)
+	r.RemoteAddr = "10.0.0.1:12345"
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return sink, w.Code
+}
+
+func TestAuditRecordsCallerAndValues(t *testing.T) {
+	defer func() { auditSink, tokenAuthenticator = nil, nil }()
+
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("delete", &reviews)
+
+	sink, code := serveAudited(authzAttributes{"delete", dgsColResource, ""}, "valid-token", func(w http.ResponseWriter, r *http.Request) {
+		auditValues(r, map[string]int{"replicas": 5}, nil)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, "system:serviceaccount:title1:matchmaker", event.User)
+	assert.Equal(t, "10.0.0.1", event.SourceIP)
+	assert.Equal(t, "/api/v1/namespaces/{namespace}/dedicatedgameservercollections/{name}", event.Route)
+	assert.Equal(t, "delete", event.Verb)
+	assert.Equal(t, dgsColResource, event.Resource)
+	assert.Equal(t, "title1", event.Namespace)
+	assert.Equal(t, "col1", event.Name)
+	assert.Equal(t, map[string]int{"replicas": 5}, event.OldValue)
+	assert.Nil(t, event.NewValue)
+	assert.Equal(t, http.StatusNoContent, event.Code)
+	assert.Equal(t, "Success", event.Result)
+}
+
+func TestAuditRecordsRejectedCalls(t *testing.T) {
+	defer func() { auditSink, tokenAuthenticator = nil, nil }()
+
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("list", &reviews)
+
+	// unauthenticated caller
+	sink, code := serveAudited(authzAttributes{"delete", dgsColResource, ""}, "invalid-token", func(w http.ResponseWriter, r *http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Len(t, sink.events, 1)
+	assert.Empty(t, sink.events[0].User)
+	assert.Equal(t, http.StatusUnauthorized, sink.events[0].Code)
+	assert.Equal(t, "Unauthorized", sink.events[0].Result)
+
+	// authenticated caller that is not allowed to perform the operation
+	sink, code = serveAudited(authzAttributes{"delete", dgsColResource, ""}, "valid-token", func(w http.ResponseWriter, r *http.Request) {})
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "system:serviceaccount:title1:matchmaker", sink.events[0].User)
+	assert.Equal(t, "Forbidden", sink.events[0].Result)
+	assert.NotEmpty(t, sink.events[0].Message)
+}
+
+func TestJSONLinesAuditSink(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := NewJSONLinesAuditSink(&buffer)
+
+	sink.Write(&AuditEvent{User: "access-code", Method: http.MethodPost, Code: http.StatusCreated, Result: "Success"})
+	sink.Write(&AuditEvent{User: "dedicatedgameserver:default/dgs1", Method: http.MethodPatch, Code: http.StatusOK, Result: "Success"})
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	var event AuditEvent
+	assert.NoError(t, json.Unmarshal(lines[1], &event))
+	assert.Equal(t, "dedicatedgameserver:default/dgs1", event.User)
+	assert.Equal(t, http.StatusOK, event.Code)
+}
+
+func TestNotAuditedWithoutSink(t *testing.T) {
+	called := false
+	handler := audited(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		assert.Nil(t, auditEvent(r))
+		// recording values is a no-op for requests that are not audited
+		auditValues(r, nil, "value")
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/create", nil))
+	assert.True(t, called)
+}
+
+func TestAuditRecordsBatchPrincipals(t *testing.T) {
+	fake := &fakeDGSUpdates{dgss: map[string]*dgsv1alpha1.DedicatedGameServer{
+		"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}},
+		"dgs2": {ObjectMeta: metav1.ObjectMeta{Name: "dgs2", Labels: map[string]string{}}},
+	}}
+	statusUpdates = newDGSUpdateCoalescer(fake.update, nil)
+	sink := &fakeAuditSink{}
+	auditSink = sink
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("patch", &reviews)
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		auditSink, tokenAuthenticator = nil, nil
+	}()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/namespaces/{namespace}/dedicatedgameservers", audited(namespaced(batchUpdateDGSHandler)))
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/title1/dedicatedgameservers",
+		strings.NewReader(`{"items":[{"name":"dgs1","activePlayers":1},{"name":"dgs2","activePlayers":2}]}`))
+	r.Header.Set("Authorization", "Bearer valid-token")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, []AuditPrincipal{
+		{Name: "dgs1", User: "system:serviceaccount:title1:matchmaker"},
+		{Name: "dgs2", User: "system:serviceaccount:title1:matchmaker"},
+	}, event.Principals)
+	assert.Equal(t, "system:serviceaccount:title1:matchmaker", event.User)
+	assert.Empty(t, event.Name)
+
+	// the request has no single caller if the objects have different callers
+	event = &AuditEvent{}
+	r = r.WithContext(context.WithValue(r.Context(), auditContextKey{}, event))
+	auditBatchPrincipals(r, []AuditPrincipal{{Name: "dgs1", User: dgsCallerPrefix + "title1/dgs1"}, {Name: "dgs2", User: ""}})
+	assert.Empty(t, event.User)
+	assert.Len(t, event.Principals, 2)
+}
@@ -0,0 +1,165 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+
+	"github.com/gorilla/mux"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxBatchUpdateItems is the maximum number of DedicatedGameServer updates in a single batch request
+const maxBatchUpdateItems = 100
+
+var batchUpdateAttributes = authzAttributes{"patch", dgsResource, "status"}
+
+// batchUpdateDGSHandler applies the updates of one or more DedicatedGameServers of the namespace
+// The caller is authenticated once, then each update is authorized, validated and applied separately, so the response contains the result of each one
+// The updates of different DedicatedGameServers are applied in parallel
+func batchUpdateDGSHandler(w http.ResponseWriter, r *http.Request) {
+	namespace := mux.Vars(r)["namespace"]
+	r, err := authenticateBatchCaller(r, namespace)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var batch helpers.DGSBatchUpdate
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+	if len(batch.Items) == 0 {
+		writeError(w, apierrors.NewBadRequest("items must not be empty"))
+		return
+	}
+	if len(batch.Items) > maxBatchUpdateItems {
+		writeError(w, apierrors.NewBadRequest(fmt.Sprintf("items must not contain more than %d updates", maxBatchUpdateItems)))
+		return
+	}
+
+	results := make([]helpers.DGSUpdateResult, len(batch.Items))
+
+	// authentication records the caller for auditing, so it is not done in parallel
+	// Each DedicatedGameServer is authenticated once, even if the batch contains several updates of it,
+	// and its caller is taken from the audit event right after it has been authenticated
+	authenticated := make([]bool, len(batch.Items))
+	authErrors := make(map[string]error)
+	var principals []AuditPrincipal
+	for i, update := range batch.Items {
+		results[i].Name = update.Name
+		err, done := authErrors[update.Name]
+		if !done {
+			err = authenticateDGS(r, batchUpdateAttributes, namespace, update.Name)
+			authErrors[update.Name] = err
+			if event := auditEvent(r); event != nil && update.Name != "" {
+				principals = append(principals, AuditPrincipal{Name: update.Name, User: event.User})
+			}
+		} else if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+			// every update that fails to authenticate counts towards the limit of failed authentications
+			err = rateLimitFailedAuthentication(r, err)
+		}
+		if err == nil && update.Name == "" {
+			err = apierrors.NewBadRequest("name is required")
+		}
+		if err != nil {
+			setUpdateError(&results[i], err)
+			continue
+		}
+		authenticated[i] = true
+	}
+	auditBatchPrincipals(r, principals)
+
+	// the batch is audited as a whole, with the previous values taken from the informer cache
+	var previous map[string]interface{}
+	if auditEvent(r) != nil {
+		previous = make(map[string]interface{})
+		for i, update := range batch.Items {
+			if !authenticated[i] {
+				continue
+			}
+			if existing, err := getDGS(namespace, update.Name); err == nil {
+				previous[update.Name] = dgsUpdateAuditValue(existing.Status.DeepCopy(), existing.Labels)
+			}
+		}
+	}
+	unaudited := r.WithContext(withoutAudit(r.Context()))
+
+	var wg sync.WaitGroup
+	for i := range batch.Items {
+		if !authenticated[i] {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dgs, err := updateDGS(unaudited, namespace, batch.Items[i])
+			if err != nil {
+				setUpdateError(&results[i], err)
+				return
+			}
+			results[i].Code = http.StatusOK
+			results[i].Status = &dgs.Status
+			results[i].Labels = dgs.Labels
+		}(i)
+	}
+	wg.Wait()
+
+	if previous != nil {
+		updated := make(map[string]interface{})
+		for _, result := range results {
+			if result.Error == nil {
+				updated[result.Name] = dgsUpdateAuditValue(result.Status, result.Labels)
+			}
+		}
+		auditBatchValues(r, previous, updated)
+	}
+	writeJSON(w, http.StatusOK, helpers.DGSBatchUpdateResult{Items: results})
+}
+
+// authenticateBatchCaller authenticates the caller of a batch update before its body is decoded
+// A bearer token is reviewed once and the result is attached to the returned request, so that only the authorization
+// of each update is left. The other requests must carry an access code or the token of a DedicatedGameServer,
+// which are checked for each update
+func authenticateBatchCaller(r *http.Request, namespace string) (*http.Request, error) {
+	auditRequest(r, "", batchUpdateAttributes, namespace, "")
+
+	if token := bearerToken(r); token != "" {
+		if tokenAuthenticator == nil {
+			return r, apierrors.NewUnauthorized("bearer token authentication is not available")
+		}
+		r = withTokenReview(r)
+		user, err := reviewRequestToken(r, token)
+		if err != nil {
+			return r, rateLimitFailedAuthentication(r, err)
+		}
+		auditRequest(r, user.Username, batchUpdateAttributes, namespace, "")
+		return r, nil
+	}
+
+	if !hasCredentials(r) {
+		return r, rateLimitFailedAuthentication(r, apierrors.NewUnauthorized("Unauthorized"))
+	}
+	return r, nil
+}
+
+// setUpdateError sets the error of the update result
+func setUpdateError(result *helpers.DGSUpdateResult, err error) {
+	apiError := toAPIError(err)
+	result.Code = apiError.Code
+	result.Error = &apiError
+}
+
+// dgsUpdateAuditValue returns the values of a DedicatedGameServer that can be modified via a batch update, for auditing
+func dgsUpdateAuditValue(status *dgsv1alpha1.DedicatedGameServerStatus, labels map[string]string) interface{} {
+	return struct {
+		Status *dgsv1alpha1.DedicatedGameServerStatus `json:"status"`
+		Labels map[string]string                      `json:"labels,omitempty"`
+	}{status, labels}
+}
@@ -0,0 +1,326 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeDGSUpdates applies the updates to in-memory DedicatedGameServers and records them
+type fakeDGSUpdates struct {
+	mutex   sync.Mutex
+	dgss    map[string]*dgsv1alpha1.DedicatedGameServer
+	updates []shared.DGSStatusFields
+	// version is the last resourceVersion that was set on an update
+	version int
+	// block, if set, is received from before each update is applied
+	block chan struct{}
+}
+
+func (f *fakeDGSUpdates) update(name string, namespace string, fields shared.DGSStatusFields) (*dgsv1alpha1.DedicatedGameServer, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.updates = append(f.updates, fields)
+
+	dgs, ok := f.dgss[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(dgsv1alpha1.Resource("dedicatedgameservers"), name)
+	}
+	if fields.ResourceVersion != "" && fields.ResourceVersion != dgs.ResourceVersion {
+		return nil, apierrors.NewConflict(dgsv1alpha1.Resource("dedicatedgameservers"), name, fmt.Errorf("the object has been modified"))
+	}
+	f.version++
+	dgs.ResourceVersion = strconv.Itoa(f.version)
+	if fields.ActivePlayers != nil {
+		dgs.Status.ActivePlayers = *fields.ActivePlayers
+	}
+	if fields.DGSHealth != nil {
+		dgs.Status.Health = *fields.DGSHealth
+	}
+	if fields.DGSState != nil {
+		dgs.Status.DGSState = *fields.DGSState
+	}
+	if fields.LastHeartbeat != nil {
+		dgs.Status.LastHeartbeat = fields.LastHeartbeat
+	}
+	for k, v := range fields.Labels {
+		if v == nil {
+			delete(dgs.Labels, k)
+		} else {
+			dgs.Labels[k] = *v
+		}
+	}
+	return dgs.DeepCopy(), nil
+}
+
+func TestCoalescesUpdatesInFlight(t *testing.T) {
+	fake := &fakeDGSUpdates{
+		dgss:  map[string]*dgsv1alpha1.DedicatedGameServer{"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}}},
+		block: make(chan struct{}),
+	}
+	coalescer := newDGSUpdateCoalescer(fake.update, nil)
+
+	// waitForQueue waits until the queue of dgs1 satisfies the condition
+	waitForQueue := func(condition func(queue *dgsUpdateQueue) bool) {
+		for i := 0; i < 500; i++ {
+			coalescer.mutex.Lock()
+			queue, ok := coalescer.queues["default/dgs1"]
+			satisfied := ok && condition(queue)
+			coalescer.mutex.Unlock()
+			if satisfied {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("Timed out waiting for the update queue")
+	}
+
+	var wg sync.WaitGroup
+	update := func(fields shared.DGSStatusFields, check func(dgs *dgsv1alpha1.DedicatedGameServer)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dgs, err := coalescer.updateDGS("default", "dgs1", fields)
+			assert.NoError(t, err)
+			check(dgs)
+		}()
+	}
+
+	// the first update is in flight (blocked) while the others arrive
+	players1, players2 := 1, 7
+	health := dgsv1alpha1.DGSHealthy
+	mode := "ranked"
+	update(shared.DGSStatusFields{ActivePlayers: &players1}, func(dgs *dgsv1alpha1.DedicatedGameServer) {})
+	waitForQueue(func(queue *dgsUpdateQueue) bool { return len(queue.pending) == 0 })
+
+	update(shared.DGSStatusFields{ActivePlayers: &players2}, func(dgs *dgsv1alpha1.DedicatedGameServer) {
+		assert.Equal(t, 7, dgs.Status.ActivePlayers)
+	})
+	update(shared.DGSStatusFields{DGSHealth: &health}, func(dgs *dgsv1alpha1.DedicatedGameServer) {
+		assert.Equal(t, dgsv1alpha1.DGSHealthy, dgs.Status.Health)
+	})
+	update(shared.DGSStatusFields{Labels: map[string]*string{"mode": &mode}}, func(dgs *dgsv1alpha1.DedicatedGameServer) {
+		assert.Equal(t, "ranked", dgs.Labels["mode"])
+	})
+	waitForQueue(func(queue *dgsUpdateQueue) bool {
+		if len(queue.pending) != 1 {
+			return false
+		}
+		next := queue.pending[0].fields
+		return next.ActivePlayers != nil && next.DGSHealth != nil && len(next.Labels) == 1
+	})
+
+	close(fake.block)
+	wg.Wait()
+
+	assert.Len(t, fake.updates, 2, "Updates that arrive while an update is in flight should be sent together")
+
+	// the queue is removed once there are no more updates
+	for i := 0; i < 500; i++ {
+		coalescer.mutex.Lock()
+		remaining := len(coalescer.queues)
+		coalescer.mutex.Unlock()
+		if remaining == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("The update queue has not been removed")
+}
+
+func TestDoesNotCoalesceUpdatesWithPrecondition(t *testing.T) {
+	fake := &fakeDGSUpdates{
+		dgss:  map[string]*dgsv1alpha1.DedicatedGameServer{"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}}},
+		block: make(chan struct{}),
+	}
+	coalescer := newDGSUpdateCoalescer(fake.update, nil)
+
+	pending := func() int {
+		coalescer.mutex.Lock()
+		defer coalescer.mutex.Unlock()
+		if queue, ok := coalescer.queues["default/dgs1"]; ok {
+			return len(queue.pending)
+		}
+		return -1
+	}
+	waitForPending := func(count int) {
+		for i := 0; i < 500 && pending() != count; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, count, pending())
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	update := func(i int, fields shared.DGSStatusFields) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = coalescer.updateDGS("default", "dgs1", fields)
+		}()
+	}
+
+	players1, players2 := 1, 2
+	state := dgsv1alpha1.DGSAssigned
+	update(0, shared.DGSStatusFields{ActivePlayers: &players1})
+	waitForPending(0)
+	update(1, shared.DGSStatusFields{DGSState: &state, ResourceVersion: "stale"})
+	waitForPending(1)
+	update(2, shared.DGSStatusFields{ActivePlayers: &players2})
+	waitForPending(2)
+
+	close(fake.block)
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.True(t, apierrors.IsConflict(errs[1]))
+	// the conflict of the precondition does not fail the updates that arrived along with it
+	assert.NoError(t, errs[2])
+	assert.Len(t, fake.updates, 3)
+	assert.Equal(t, 2, fake.dgss["dgs1"].Status.ActivePlayers)
+	assert.Equal(t, dgsv1alpha1.DGSState(""), fake.dgss["dgs1"].Status.DGSState)
+}
+
+func TestBatchUpdateDGS(t *testing.T) {
+	fake := &fakeDGSUpdates{dgss: map[string]*dgsv1alpha1.DedicatedGameServer{
+		"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{"map": "dm1"}}},
+		"dgs2": {ObjectMeta: metav1.ObjectMeta{Name: "dgs2", Labels: map[string]string{}}},
+	}}
+	statusUpdates = newDGSUpdateCoalescer(fake.update, nil)
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("patch", &reviews)
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		tokenAuthenticator = nil
+	}()
+
+	body := `{"items":[
+		{"name":"dgs1","activePlayers":8,"health":"Healthy","labels":{"mode":"ranked","map":null}},
+		{"name":"dgs2","state":"Wrong"},
+		{"name":"dgs3","activePlayers":1},
+		{"name":"dgs2","labels":{"DedicatedGameServerName":"other"}}
+	]}`
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/title1/dedicatedgameservers", strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer valid-token")
+	r = mux.SetURLVars(r, map[string]string{"namespace": "title1"})
+	w := httptest.NewRecorder()
+	batchUpdateDGSHandler(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result helpers.DGSBatchUpdateResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Len(t, result.Items, 4)
+
+	assert.Equal(t, http.StatusOK, result.Items[0].Code)
+	assert.Equal(t, 8, result.Items[0].Status.ActivePlayers)
+	assert.Equal(t, map[string]string{"mode": "ranked"}, result.Items[0].Labels)
+
+	assert.Equal(t, http.StatusBadRequest, result.Items[1].Code, "Invalid state")
+	assert.Equal(t, http.StatusNotFound, result.Items[2].Code)
+	assert.Equal(t, http.StatusBadRequest, result.Items[3].Code, "Reserved label")
+
+	// the token is reviewed once and each DedicatedGameServer is authorized once
+	tokenReviews := 0
+	for _, action := range tokenAuthenticator.client.(*k8sfake.Clientset).Actions() {
+		if action.GetResource().Resource == "tokenreviews" {
+			tokenReviews++
+		}
+	}
+	assert.Equal(t, 1, tokenReviews)
+	assert.Len(t, reviews, 3)
+}
+
+func TestBatchUpdateDGSAuthentication(t *testing.T) {
+	fake := &fakeDGSUpdates{dgss: map[string]*dgsv1alpha1.DedicatedGameServer{
+		"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}},
+		"dgs2": {ObjectMeta: metav1.ObjectMeta{Name: "dgs2", Labels: map[string]string{}}},
+	}}
+	statusUpdates = newDGSUpdateCoalescer(fake.update, nil)
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("patch", &reviews)
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		tokenAuthenticator = nil
+	}()
+
+	client := k8sfake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: shared.APIAccessCodeSecretName, Namespace: "batchauth"},
+		Data:       map[string][]byte{"code": []byte("access-code")},
+	})
+	_, err := shared.GetAccessCode(client, "batchauth")
+	assert.NoError(t, err)
+	key, err := shared.GetDGSTokenSigningKey(client, "batchauth")
+	assert.NoError(t, err)
+
+	serve := func(body string, header string, value string, rl *rateLimiter) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/batchauth/dedicatedgameservers", strings.NewReader(body))
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		r = mux.SetURLVars(r, map[string]string{"namespace": "batchauth"})
+		r, err := limitCaller(rl, r)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		batchUpdateDGSHandler(w, r)
+		return w
+	}
+
+	// the caller is authenticated before the body is decoded
+	assert.Equal(t, http.StatusUnauthorized, serve(`{"items":`, "", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(`{"items":`, "Authorization", "Bearer wrong-token", nil).Code)
+
+	// the token of dgs1 fails for the other DedicatedGameServers, including an update without a name,
+	// and every failed update takes a token from the bucket of the caller's IP address
+	rl := newRateLimiter(0.001, 3, clockwork.NewFakeClock())
+	w := serve(`{"items":[{"name":"dgs1","activePlayers":1},{"name":"dgs2","activePlayers":1},{"name":"dgs2","activePlayers":2},{"name":"","activePlayers":1}]}`,
+		helpers.AccessCodeHeader, shared.NewDGSToken(key, "batchauth", "dgs1"), rl)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result helpers.DGSBatchUpdateResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	codes := make([]int, 0, len(result.Items))
+	for _, item := range result.Items {
+		codes = append(codes, item.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized}, codes)
+	allowed, _ := rl.allow("192.0.2.1")
+	assert.False(t, allowed, "Each failed update should be rate limited")
+
+	// an update without a name is rejected once the caller has been authenticated
+	w = serve(`{"items":[{"name":"","activePlayers":1}]}`, helpers.AccessCodeHeader, "access-code", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusBadRequest, result.Items[0].Code)
+}
+
+func TestBatchUpdateDGSLimits(t *testing.T) {
+	for _, body := range []string{`{"items":[]}`, `{"items":[` + strings.Repeat(`{"name":"dgs1"},`, maxBatchUpdateItems) + `{"name":"dgs1"}]}`} {
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/title1/dedicatedgameservers", strings.NewReader(body))
+		r.Header.Set(helpers.AccessCodeHeader, "code")
+		r = mux.SetURLVars(r, map[string]string{"namespace": "title1"})
+		w := httptest.NewRecorder()
+		batchUpdateDGSHandler(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,213 @@
+package apiserver
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned"
+	dgsinformers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/informers/externalversions"
+	listers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/listers/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	log "github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// objectCache serves the read requests of the API Server from shared informer caches,
+// so that frequent calls (e.g. a server browser polling /running) do not list all the objects via the Kubernetes API
+type objectCache struct {
+	// dgsListers and dgsColListers contain the listers of each served namespace,
+	// or a single lister for metav1.NamespaceAll if the API Server serves all namespaces
+	dgsListers    map[string]listers.DedicatedGameServerLister
+	dgsColListers map[string]listers.DedicatedGameServerCollectionLister
+	synced        []cache.InformerSynced
+	// dgsEvents distributes the changes of the DedicatedGameServers to the watchers
+	dgsEvents *dgsWatchHub
+}
+
+// informerCache is nil if the API Server is not connected to a cluster, in which case the Kubernetes API is called directly
+var informerCache *objectCache
+
+// newObjectCache starts the DedicatedGameServer and DedicatedGameServerCollection informers
+// If the API Server is restricted to specific namespaces, one set of informers is started per namespace,
+// so that the objects of the other namespaces are neither watched nor kept in memory
+func newObjectCache(dgsClient versioned.Interface, namespaces []string, stopCh <-chan struct{}) *objectCache {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	oc := &objectCache{
+		dgsListers:    make(map[string]listers.DedicatedGameServerLister, len(namespaces)),
+		dgsColListers: make(map[string]listers.DedicatedGameServerCollectionLister, len(namespaces)),
+		dgsEvents:     newDGSWatchHub(),
+	}
+
+	for _, namespace := range namespaces {
+		factory := dgsinformers.NewSharedInformerFactoryWithOptions(dgsClient, 30*time.Minute, dgsinformers.WithNamespace(namespace))
+		dgsInformer := factory.Azuregaming().V1alpha1().DedicatedGameServers()
+		dgsColInformer := factory.Azuregaming().V1alpha1().DedicatedGameServerCollections()
+
+		oc.dgsListers[namespace] = dgsInformer.Lister()
+		oc.dgsColListers[namespace] = dgsColInformer.Lister()
+		oc.synced = append(oc.synced, dgsInformer.Informer().HasSynced, dgsColInformer.Informer().HasSynced)
+		dgsInformer.Informer().AddEventHandler(oc.dgsEvents.eventHandler())
+
+		factory.Start(stopCh)
+	}
+
+	go func() {
+		if cache.WaitForCacheSync(stopCh, oc.synced...) {
+			oc.dgsEvents.markSynced()
+			log.Info("API Server informer caches have been synced")
+		}
+	}()
+	go func() {
+		<-stopCh
+		oc.dgsEvents.stop()
+	}()
+
+	return oc
+}
+
+// hasSynced returns true if the informer caches contain all the objects
+func (oc *objectCache) hasSynced() bool {
+	if oc == nil {
+		return false
+	}
+	for _, synced := range oc.synced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// dgsLister returns the DedicatedGameServer lister of the namespace
+// It returns false if the caches have not been synced or if the namespace is not cached
+func (oc *objectCache) dgsLister(namespace string) (listers.DedicatedGameServerNamespaceLister, bool) {
+	if !oc.hasSynced() {
+		return nil, false
+	}
+	if lister, ok := oc.dgsListers[namespace]; ok {
+		return lister.DedicatedGameServers(namespace), true
+	}
+	if lister, ok := oc.dgsListers[metav1.NamespaceAll]; ok {
+		return lister.DedicatedGameServers(namespace), true
+	}
+	return nil, false
+}
+
+// dgsColLister returns the DedicatedGameServerCollection lister of the namespace
+// It returns false if the caches have not been synced or if the namespace is not cached
+func (oc *objectCache) dgsColLister(namespace string) (listers.DedicatedGameServerCollectionNamespaceLister, bool) {
+	if !oc.hasSynced() {
+		return nil, false
+	}
+	if lister, ok := oc.dgsColListers[namespace]; ok {
+		return lister.DedicatedGameServerCollections(namespace), true
+	}
+	if lister, ok := oc.dgsColListers[metav1.NamespaceAll]; ok {
+		return lister.DedicatedGameServerCollections(namespace), true
+	}
+	return nil, false
+}
+
+// listDGSs returns the DedicatedGameServers of the namespace that match the label selector, sorted by name
+// The informer cache is used once it has been synced, otherwise the Kubernetes API is called
+func listDGSs(namespace string, selector labels.Selector) (*dgsv1alpha1.DedicatedGameServerList, error) {
+	if lister, ok := informerCache.dgsLister(namespace); ok {
+		dgss, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		list := &dgsv1alpha1.DedicatedGameServerList{Items: make([]dgsv1alpha1.DedicatedGameServer, 0, len(dgss))}
+		for _, dgs := range dgss {
+			list.Items = append(list.Items, *dgs)
+		}
+		sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+		return list, nil
+	}
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+}
+
+// getDGS returns the designated DedicatedGameServer, which must not be modified since it can be shared with the informer cache
+func getDGS(namespace string, name string) (*dgsv1alpha1.DedicatedGameServer, error) {
+	if lister, ok := informerCache.dgsLister(namespace); ok {
+		return lister.Get(name)
+	}
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).Get(name, metav1.GetOptions{})
+}
+
+// listDGSCols returns the DedicatedGameServerCollections of the namespace, sorted by name
+func listDGSCols(namespace string) (*dgsv1alpha1.DedicatedGameServerCollectionList, error) {
+	if lister, ok := informerCache.dgsColLister(namespace); ok {
+		dgsCols, err := lister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		list := &dgsv1alpha1.DedicatedGameServerCollectionList{Items: make([]dgsv1alpha1.DedicatedGameServerCollection, 0, len(dgsCols))}
+		for _, dgsCol := range dgsCols {
+			list.Items = append(list.Items, *dgsCol)
+		}
+		sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+		return list, nil
+	}
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(namespace).List(metav1.ListOptions{})
+}
+
+// getDGSCol returns the designated DedicatedGameServerCollection, which must not be modified since it can be shared with the informer cache
+func getDGSCol(namespace string, name string) (*dgsv1alpha1.DedicatedGameServerCollection, error) {
+	if lister, ok := informerCache.dgsColLister(namespace); ok {
+		return lister.Get(name)
+	}
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(namespace).Get(name, metav1.GetOptions{})
+}
+
+// readyDGSs returns the DedicatedGameServers of the namespace that match the label selector and are ready to accept players
+func readyDGSs(namespace string, selector labels.Selector) ([]dgsv1alpha1.DedicatedGameServer, error) {
+	dgss, err := listDGSs(namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	ready := make([]dgsv1alpha1.DedicatedGameServer, 0)
+	for _, dgs := range dgss.Items {
+		if shared.IsDGSReady(&dgs) {
+			ready = append(ready, dgs)
+		}
+	}
+	return ready, nil
+}
+
+// readyzHandler returns 200 once the informer caches have been synced, so the API Server only receives traffic when it can serve it
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	if !informerCache.hasSynced() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
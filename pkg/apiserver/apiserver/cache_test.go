@@ -0,0 +1,127 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned/fake"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func newCachedDGS(namespace string, name string, health dgsv1alpha1.DGSHealth) *dgsv1alpha1.DedicatedGameServer {
+	return &dgsv1alpha1.DedicatedGameServer{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: dgsv1alpha1.DedicatedGameServerStatus{
+			Health:   health,
+			PodPhase: corev1.PodRunning,
+		},
+	}
+}
+
+func TestReadyzWaitsForCacheSync(t *testing.T) {
+	informerCache = nil
+
+	w := httptest.NewRecorder()
+	readyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerCache = newObjectCache(fake.NewSimpleClientset(), nil, stopCh)
+	defer func() { informerCache = nil }()
+
+	err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		w := httptest.NewRecorder()
+		readyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return w.Code == http.StatusOK, nil
+	})
+	assert.NoError(t, err, "readyz should return 200 once the caches are synced")
+}
+
+func TestReadsAreServedFromCache(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newCachedDGS("title1", "dgs2", dgsv1alpha1.DGSHealthy),
+		newCachedDGS("title1", "dgs1", dgsv1alpha1.DGSHealthy),
+		newCachedDGS("title1", "dgs3", dgsv1alpha1.DGSFailed),
+		newCachedDGS("title2", "dgs4", dgsv1alpha1.DGSHealthy),
+		&dgsv1alpha1.DedicatedGameServerCollection{ObjectMeta: metav1.ObjectMeta{Name: "col1", Namespace: "title1"}},
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerCache = newObjectCache(client, nil, stopCh)
+	defer func() { informerCache = nil }()
+
+	assert.NoError(t, wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return informerCache.hasSynced(), nil
+	}))
+	// make sure that no further calls reach the API
+	client.ClearActions()
+
+	dgss, err := listDGSs("title1", labels.Everything())
+	assert.NoError(t, err)
+	assert.Len(t, dgss.Items, 3)
+	assert.Equal(t, "dgs1", dgss.Items[0].Name)
+
+	ready, err := readyDGSs("title1", labels.Everything())
+	assert.NoError(t, err)
+	assert.Len(t, ready, 2)
+
+	dgs, err := getDGS("title2", "dgs4")
+	assert.NoError(t, err)
+	assert.Equal(t, "dgs4", dgs.Name)
+
+	_, err = getDGS("title2", "dgs1")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	dgsCols, err := listDGSCols("title1")
+	assert.NoError(t, err)
+	assert.Len(t, dgsCols.Items, 1)
+
+	_, err = getDGSCol("title1", "col1")
+	assert.NoError(t, err)
+
+	assert.Empty(t, client.Actions(), "Reads should not call the Kubernetes API")
+}
+
+func TestCacheWatchesServedNamespacesOnly(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newCachedDGS("title1", "dgs1", dgsv1alpha1.DGSHealthy),
+		newCachedDGS("title2", "dgs2", dgsv1alpha1.DGSHealthy),
+		newCachedDGS("title3", "dgs3", dgsv1alpha1.DGSHealthy),
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerCache = newObjectCache(client, []string{"title1", "title3"}, stopCh)
+	defer func() { informerCache = nil }()
+
+	assert.NoError(t, wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return informerCache.hasSynced(), nil
+	}))
+
+	for _, action := range client.Actions() {
+		assert.Contains(t, []string{"title1", "title3"}, action.GetNamespace(), "Only the served namespaces should be listed and watched")
+	}
+	client.ClearActions()
+
+	dgss, err := listDGSs("title1", labels.Everything())
+	assert.NoError(t, err)
+	assert.Len(t, dgss.Items, 1)
+
+	dgs, err := getDGS("title3", "dgs3")
+	assert.NoError(t, err)
+	assert.Equal(t, "dgs3", dgs.Name)
+
+	assert.Empty(t, client.Actions(), "Reads of the served namespaces should not call the Kubernetes API")
+}
@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"sync"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+)
+
+// dgsUpdateCoalescer sends at most one status update per DedicatedGameServer to the Kubernetes API at a time
+// The updates that arrive while an update is in flight are merged into a single update, which is sent once the previous one completes,
+// so a game server that reports e.g. every player join results in fewer API calls
+// If the coalescer has a rateLimiter, the updates of each DedicatedGameServer are also sent at most at its rate,
+// and the updates that arrive in the meantime are merged as well
+// Updates with a ResourceVersion precondition (e.g. allocations) are never merged, so that a conflict only fails the update that expected the version
+type dgsUpdateCoalescer struct {
+	// update applies the fields to the DedicatedGameServer, it has the signature of shared.UpdateDGSStatus
+	update func(name string, namespace string, fields shared.DGSStatusFields) (*dgsv1alpha1.DedicatedGameServer, error)
+	// limiter limits the updates per DedicatedGameServer, nil if rate limiting is disabled
+	limiter *rateLimiter
+
+	mutex  sync.Mutex
+	queues map[string]*dgsUpdateQueue
+}
+
+// dgsUpdateQueue contains the updates of a DedicatedGameServer that wait for the update in flight, in the order they are sent
+type dgsUpdateQueue struct {
+	pending []*coalescedUpdate
+}
+
+// coalescedUpdate is a single API call that contains the updates of one or more requests
+type coalescedUpdate struct {
+	fields shared.DGSStatusFields
+	done   chan struct{}
+	result *dgsv1alpha1.DedicatedGameServer
+	err    error
+}
+
+// statusUpdates coalesces the status updates of the API Server
+var statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+
+func newDGSUpdateCoalescer(update func(name string, namespace string, fields shared.DGSStatusFields) (*dgsv1alpha1.DedicatedGameServer, error),
+	limiter *rateLimiter) *dgsUpdateCoalescer {
+	return &dgsUpdateCoalescer{
+		update:  update,
+		limiter: limiter,
+		queues:  make(map[string]*dgsUpdateQueue),
+	}
+}
+
+// updateDGS merges the fields into the last queued update of the DedicatedGameServer
+// and returns the DedicatedGameServer once this update has been applied
+func (c *dgsUpdateCoalescer) updateDGS(namespace string, name string, fields shared.DGSStatusFields) (*dgsv1alpha1.DedicatedGameServer, error) {
+	key := namespace + "/" + name
+
+	c.mutex.Lock()
+	queue, inFlight := c.queues[key]
+	if !inFlight {
+		queue = &dgsUpdateQueue{}
+		c.queues[key] = queue
+	}
+	var update *coalescedUpdate
+	if last := len(queue.pending) - 1; last >= 0 && queue.pending[last].fields.ResourceVersion == "" && fields.ResourceVersion == "" {
+		update = queue.pending[last]
+	} else {
+		update = &coalescedUpdate{done: make(chan struct{})}
+		queue.pending = append(queue.pending, update)
+	}
+	update.fields = update.fields.Merge(fields)
+	c.mutex.Unlock()
+
+	if !inFlight {
+		go c.run(key, namespace, name, queue)
+	}
+
+	<-update.done
+	return update.result, update.err
+}
+
+// run sends the queued updates of the DedicatedGameServer one after the other, until there are no more updates
+func (c *dgsUpdateCoalescer) run(key string, namespace string, name string, queue *dgsUpdateQueue) {
+	for {
+		c.mutex.Lock()
+		if len(queue.pending) == 0 {
+			delete(c.queues, key)
+			c.mutex.Unlock()
+			return
+		}
+		c.mutex.Unlock()
+
+		// the updates that arrive while waiting for the rate limit are merged into the next update
+		c.limiter.wait(key)
+
+		c.mutex.Lock()
+		update := queue.pending[0]
+		queue.pending = queue.pending[1:]
+		c.mutex.Unlock()
+
+		update.result, update.err = c.update(name, namespace, update.fields)
+		close(update.done)
+	}
+}
@@ -0,0 +1,163 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// writeJSON writes the designated object as the JSON response body, with the specified HTTP status code
+func writeJSON(w http.ResponseWriter, code int, obj interface{}) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		log.Errorf("Error in marshaling to JSON: %s", err.Error())
+		writeError(w, apierrors.NewInternalError(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// writeError writes the JSON representation of the error as the response body
+// Kubernetes API errors keep their status code (e.g. NotFound => 404, Conflict => 409), all other errors are returned as 500
+// If the error carries a retry delay (e.g. TooManyRequests), it is set as the Retry-After header
+func writeError(w http.ResponseWriter, err error) {
+	apiError := toAPIError(err)
+	body, _ := json.Marshal(apiError)
+	auditError(w, apiError.Reason, apiError.Message)
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiError.Code)
+	w.Write(body)
+}
+
+// toAPIError converts an error to the APIError that is returned to the caller
+func toAPIError(err error) helpers.APIError {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		s := status.Status()
+		if s.Code != 0 {
+			return helpers.APIError{
+				Code:    int(s.Code),
+				Reason:  string(s.Reason),
+				Message: s.Message,
+			}
+		}
+	}
+	return helpers.APIError{
+		Code:    http.StatusInternalServerError,
+		Reason:  string(metav1.StatusReasonInternalError),
+		Message: err.Error(),
+	}
+}
+
+// authenticated wraps the handler so that it is called only if the request's namespace is served by the API Server
+// and the request carries either a valid access code for this namespace or a bearer token that is allowed to perform the operation
+func authenticated(attrs authzAttributes, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := authenticate(r, attrs, requestNamespace(r), mux.Vars(r)["name"]); err != nil {
+			writeError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authenticate returns an error if the designated namespace is not served by the API Server
+// or if the request does not carry a valid access code for this namespace or a bearer token that is allowed to perform the operation
+func authenticate(r *http.Request, attrs authzAttributes, namespace string, name string) error {
+	return authenticateWith(r, attrs, namespace, name, func() (string, bool, error) {
+		result, err := helpers.IsAPICallAuthenticated(r, namespace)
+		return accessCodeCaller, result, err
+	})
+}
+
+// dgsAuthenticated wraps the handler so that it is called only if the request's namespace is served by the API Server
+// and the request carries either a valid access code for this namespace, the token of the designated DedicatedGameServer
+// or a bearer token that is allowed to perform the operation
+func dgsAuthenticated(attrs authzAttributes, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := authenticateDGS(r, attrs, requestNamespace(r), mux.Vars(r)["name"]); err != nil {
+			writeError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authenticateDGS returns an error if the designated namespace is not served by the API Server or if the request does not carry
+// a valid access code for this namespace, the token of the designated DedicatedGameServer or a bearer token that is allowed to perform the operation
+func authenticateDGS(r *http.Request, attrs authzAttributes, namespace string, name string) error {
+	return authenticateWith(r, attrs, namespace, name, func() (string, bool, error) {
+		result, err := shared.AuthenticateDGSToken(helpers.RequestCode(r), namespace, name)
+		if err != nil || result {
+			return dgsCallerPrefix + namespace + "/" + name, result, err
+		}
+		result, err = helpers.IsAPICallAuthenticated(r, namespace)
+		return accessCodeCaller, result, err
+	})
+}
+
+// authenticateWith authorizes requests with a bearer token via the Kubernetes API
+// and all other requests via the designated access code check, which returns the caller of the request if it succeeds
+// The caller and the target of the request are recorded for auditing
+func authenticateWith(r *http.Request, attrs authzAttributes, namespace string, name string, checkCode func() (string, bool, error)) error {
+	auditRequest(r, "", attrs, namespace, name)
+
+	if err := checkNamespace(namespace); err != nil {
+		return err
+	}
+
+	if token := bearerToken(r); token != "" {
+		if tokenAuthenticator == nil {
+			return apierrors.NewUnauthorized("bearer token authentication is not available")
+		}
+		user, err := reviewRequestToken(r, token)
+		if err == nil {
+			auditRequest(r, user.Username, attrs, namespace, name)
+			err = tokenAuthenticator.authorizeUser(user, attrs, namespace, name)
+		}
+		if err != nil {
+			return rateLimitFailedAuthentication(r, err)
+		}
+		return checkCallerRateLimit(r, userRateLimitKeyPrefix+user.Username)
+	}
+
+	caller, result, err := checkCode()
+	if err != nil {
+		log.Errorf("Error in authentication: %v", err)
+		return apierrors.NewInternalError(err)
+	}
+
+	if !result {
+		return rateLimitFailedAuthentication(r, apierrors.NewUnauthorized("Unauthorized"))
+	}
+	auditRequest(r, caller, attrs, namespace, name)
+
+	// the access code is shared by all the callers of the namespace, so they are told apart by their IP address
+	if caller == accessCodeCaller {
+		return checkCallerRateLimit(r, "")
+	}
+	return checkCallerRateLimit(r, caller)
+}
+
+// rateLimitFailedAuthentication limits the failed authentications per IP address,
+// so that the credentials cannot be guessed at an unlimited rate
+// It returns the rate limit error, if the limit has been exceeded, or the designated authentication error
+func rateLimitFailedAuthentication(r *http.Request, err error) error {
+	if limitErr := checkFailedAuthRateLimit(r); limitErr != nil {
+		return limitErr
+	}
+	return err
+}
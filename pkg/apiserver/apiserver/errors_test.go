@@ -0,0 +1,66 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWriteErrorMapsKubernetesErrors(t *testing.T) {
+	resource := schema.GroupResource{Group: "azuregaming.com", Resource: "dedicatedgameservers"}
+
+	tests := []struct {
+		err    error
+		code   int
+		reason string
+	}{
+		{apierrors.NewNotFound(resource, "dgs"), http.StatusNotFound, "NotFound"},
+		{apierrors.NewConflict(resource, "dgs", errors.New("conflict")), http.StatusConflict, "Conflict"},
+		{apierrors.NewBadRequest("bad"), http.StatusBadRequest, "BadRequest"},
+		{errors.New("something went wrong"), http.StatusInternalServerError, "InternalError"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		writeError(w, test.err)
+
+		assert.Equal(t, test.code, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var apiError helpers.APIError
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiError))
+		assert.Equal(t, test.code, apiError.Code)
+		assert.Equal(t, test.reason, apiError.Reason)
+		assert.Equal(t, test.err.Error(), apiError.Message)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	assert.NoError(t, validateDGSState(dgsv1alpha1.DGSRunning))
+	assert.True(t, apierrors.IsBadRequest(validateDGSState("Foo")))
+
+	assert.NoError(t, validateDGSHealth(dgsv1alpha1.DGSHealthy))
+	assert.True(t, apierrors.IsBadRequest(validateDGSHealth("Foo")))
+
+	assert.NoError(t, validatePlayerCount(0))
+	assert.True(t, apierrors.IsBadRequest(validatePlayerCount(-1)))
+}
+
+func TestSetDGSStatusRejectsInvalidValues(t *testing.T) {
+	state := "Foo"
+	_, err := setDGSStatus(httptest.NewRequest(http.MethodPatch, "/", nil), "default", "dgs", helpers.DGSStatusUpdate{State: &state})
+	assert.True(t, apierrors.IsBadRequest(err))
+
+	_, err = setDGSPlayers(httptest.NewRequest(http.MethodPut, "/", nil), "default", "dgs", -5)
+	assert.True(t, apierrors.IsBadRequest(err))
+}
@@ -0,0 +1,313 @@
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	grpcapi "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/grpcapi"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// grpcEventTypes maps the watch event types to the values of the gRPC API
+var grpcEventTypes = map[watch.EventType]grpcapi.WatchEvent_Type{
+	watch.Added:    grpcapi.WatchEvent_ADDED,
+	watch.Modified: grpcapi.WatchEvent_MODIFIED,
+	watch.Deleted:  grpcapi.WatchEvent_DELETED,
+}
+
+// grpcCodes maps the HTTP status codes of the errors to the gRPC status codes, the other errors are Unknown
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusGone:                codes.OutOfRange,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// grpcService implements the DedicatedGameServers service of the gRPC API (see grpcapi/apiserver.proto)
+// Each call is represented by an HTTP request and served by the functions of the v1 JSON API,
+// so both APIs apply the same authentication, validation, rate limiting and auditing
+type grpcService struct {
+	// listRequiresAuth determines whether List and Watch require authentication, as the read operations of the JSON API
+	listRequiresAuth bool
+}
+
+// newGRPCServer returns a gRPC server that serves the DedicatedGameServers service
+// The server uses TLS if tlsConfig is not nil. A non-positive maxMessageSize keeps the default maximum request size
+func newGRPCServer(listRequiresAuth bool, tlsConfig *tls.Config, maxMessageSize int64) *grpc.Server {
+	var options []grpc.ServerOption
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if maxMessageSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(int(maxMessageSize)))
+	}
+	server := grpc.NewServer(options...)
+	grpcapi.RegisterDedicatedGameServersServer(server, &grpcService{listRequiresAuth: listRequiresAuth})
+	return server
+}
+
+// SetHealth sets the Health of the DedicatedGameServer
+func (s *grpcService) SetHealth(ctx context.Context, request *grpcapi.SetHealthRequest) (*grpcapi.DedicatedGameServerStatus, error) {
+	health := grpcapi.DGSHealth(request.Health)
+	return s.update(ctx, legacyStatusAttributes, request.Namespace, helpers.DGSUpdate{Name: request.Name, Health: &health})
+}
+
+// SetState sets the state of the DedicatedGameServer
+func (s *grpcService) SetState(ctx context.Context, request *grpcapi.SetStateRequest) (*grpcapi.DedicatedGameServerStatus, error) {
+	state := grpcapi.DGSState(request.State)
+	return s.update(ctx, legacyStatusAttributes, request.Namespace, helpers.DGSUpdate{Name: request.Name, State: &state})
+}
+
+// SetPlayers sets the number of active players of the DedicatedGameServer
+func (s *grpcService) SetPlayers(ctx context.Context, request *grpcapi.SetPlayersRequest) (*grpcapi.DedicatedGameServerStatus, error) {
+	playerCount := int(request.PlayerCount)
+	return s.update(ctx, legacyPlayersAttributes, request.Namespace, helpers.DGSUpdate{Name: request.Name, ActivePlayers: &playerCount})
+}
+
+// MarkForDeletion sets the MarkedForDeletion field of the DedicatedGameServer
+func (s *grpcService) MarkForDeletion(ctx context.Context, request *grpcapi.MarkForDeletionRequest) (*grpcapi.DedicatedGameServerStatus, error) {
+	return s.update(ctx, legacyStatusAttributes, request.Namespace, helpers.DGSUpdate{Name: request.Name, MarkedForDeletion: &request.MarkedForDeletion})
+}
+
+// update authenticates the call as a request of the DedicatedGameServer and applies the update, as the status and players methods of the JSON API
+func (s *grpcService) update(ctx context.Context, attrs authzAttributes, namespace string, update helpers.DGSUpdate) (*grpcapi.DedicatedGameServerStatus, error) {
+	r, err := grpcRequest(ctx, nil)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	namespace = grpcNamespace(namespace)
+
+	var dgs *dgsv1alpha1.DedicatedGameServer
+	err = auditedCall(r, func(r *http.Request) error {
+		if err := authenticateDGS(r, attrs, namespace, update.Name); err != nil {
+			return err
+		}
+		var err error
+		dgs, err = updateDGS(r, namespace, update)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcapi.FromDGSStatus(&dgs.Status), nil
+}
+
+// List returns the DedicatedGameServers of the namespace that pass the filters
+func (s *grpcService) List(ctx context.Context, request *grpcapi.ListRequest) (*grpcapi.ListResponse, error) {
+	query := listQuery(request.Filters)
+	if request.SortBy != "" {
+		query.Set("sortBy", request.SortBy)
+	}
+	if request.Limit != 0 {
+		query.Set("limit", strconv.Itoa(int(request.Limit)))
+	}
+	if request.Continue != "" {
+		query.Set("continue", request.Continue)
+	}
+
+	namespace := grpcNamespace(request.Namespace)
+	r, err := grpcRequest(ctx, query)
+	if err == nil {
+		err = s.authenticateRead(r, authzAttributes{"list", dgsResource, ""}, namespace)
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	options, err := parseDGSListOptions(r)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	var dgss []dgsv1alpha1.DedicatedGameServer
+	if request.GetFilters().GetReadyOnly() {
+		dgss, err = readyDGSs(namespace, options.selector)
+	} else {
+		var list *dgsv1alpha1.DedicatedGameServerList
+		if list, err = listDGSs(namespace, options.selector); err == nil {
+			dgss = list.Items
+		}
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	dgss, continueToken := options.apply(dgss)
+	response := &grpcapi.ListResponse{Continue: continueToken}
+	for i := range dgss {
+		response.Items = append(response.Items, grpcapi.FromDGS(&dgss[i]))
+	}
+	return response, nil
+}
+
+// Allocate sets the state of an Idle DedicatedGameServer that is ready to accept players and passes the filters to Assigned, and returns it
+func (s *grpcService) Allocate(ctx context.Context, request *grpcapi.AllocateRequest) (*grpcapi.AllocateResponse, error) {
+	query := listQuery(request.Filters)
+	if request.SortBy != "" {
+		query.Set("sortBy", request.SortBy)
+	}
+
+	namespace := grpcNamespace(request.Namespace)
+	r, err := grpcRequest(ctx, query)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var dgs *dgsv1alpha1.DedicatedGameServer
+	err = auditedCall(r, func(r *http.Request) error {
+		if err := authenticate(r, allocateAttributes, namespace, ""); err != nil {
+			return err
+		}
+		options, err := parseDGSListOptions(r)
+		if err != nil {
+			return err
+		}
+		dgs, err = allocateDGS(r, namespace, options)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &grpcapi.AllocateResponse{Object: grpcapi.FromDGS(dgs)}, nil
+}
+
+// Watch streams the changes of the DedicatedGameServers of the namespace that pass the filters
+func (s *grpcService) Watch(request *grpcapi.WatchRequest, stream grpcapi.DedicatedGameServers_WatchServer) error {
+	if request.GetFilters().GetReadyOnly() {
+		return status.Error(codes.InvalidArgument, "ready_only is not supported by Watch")
+	}
+
+	namespace := grpcNamespace(request.Namespace)
+	r, err := grpcRequest(stream.Context(), listQuery(request.Filters))
+	if err == nil {
+		err = s.authenticateRead(r, authzAttributes{"watch", dgsResource, ""}, namespace)
+	}
+	if err != nil {
+		return grpcError(err)
+	}
+
+	hub, watcher, initial, err := watchDGSs(r, namespace, request.ResourceVersion)
+	if err != nil {
+		return grpcError(err)
+	}
+	defer hub.stopWatching(watcher)
+
+	for _, message := range initial {
+		if err := stream.Send(toGRPCWatchEvent(message)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case message, ok := <-watcher.events:
+			if !ok {
+				return status.Error(codes.Unavailable, "Watch closed, resume from the last resource_version")
+			}
+			if err := stream.Send(toGRPCWatchEvent(message)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// authenticateRead authenticates the listing calls as the read routes of the JSON API (see registerV1Routes)
+func (s *grpcService) authenticateRead(r *http.Request, attrs authzAttributes, namespace string) error {
+	if s.listRequiresAuth {
+		return authenticate(r, attrs, namespace, "")
+	}
+	if err := checkCallerRateLimit(r, ""); err != nil {
+		return err
+	}
+	return checkNamespace(namespace)
+}
+
+// grpcRequest returns the HTTP request that represents the gRPC call, so that the call can be served by the functions of the JSON API
+// The request carries the credentials of the call metadata, the address of the peer and the designated query parameters
+// Its caller is rate limited as the callers of the JSON API (see withRateLimit)
+func grpcRequest(ctx context.Context, query url.Values) (*http.Request, error) {
+	method, _ := grpc.Method(ctx)
+	r := &http.Request{
+		Method: http.MethodPost,
+		URL:    &url.URL{Path: method, RawQuery: query.Encode()},
+		Header: make(http.Header),
+		Body:   http.NoBody,
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, header := range []string{helpers.AccessCodeHeader, "Authorization"} {
+			if values := md.Get(header); len(values) > 0 {
+				r.Header.Set(header, values[0])
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	return limitCaller(callerRateLimiter, r.WithContext(ctx))
+}
+
+// grpcNamespace returns the namespace of a call, which is the default GameNamespace if it is empty
+func grpcNamespace(namespace string) string {
+	if namespace == "" {
+		return shared.GameNamespace
+	}
+	return namespace
+}
+
+// listQuery returns the query parameters of the list method of the JSON API that correspond to the filters
+func listQuery(filters *grpcapi.Filters) url.Values {
+	query := make(url.Values)
+	set := func(key string, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set("labelSelector", filters.GetLabelSelector())
+	set("collection", filters.GetCollection())
+	if filters.GetState() != grpcapi.State_STATE_UNSPECIFIED {
+		set("state", grpcapi.DGSState(filters.GetState()))
+	}
+	set("node", filters.GetNode())
+	if filters.GetMinFreeSlots() != 0 {
+		set("minFreeSlots", strconv.Itoa(int(filters.GetMinFreeSlots())))
+	}
+	return query
+}
+
+// grpcError converts an error of the JSON API functions to a gRPC status error
+func grpcError(err error) error {
+	apiError := toAPIError(err)
+	code, ok := grpcCodes[apiError.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	return status.Error(code, apiError.Message)
+}
+
+func toGRPCWatchEvent(message dgsWatchMessage) *grpcapi.WatchEvent {
+	return &grpcapi.WatchEvent{
+		Type:            grpcEventTypes[message.event.Type],
+		Object:          grpcapi.FromDGS(message.event.Object),
+		ResourceVersion: strconv.FormatUint(message.resourceVersion, 10),
+	}
+}
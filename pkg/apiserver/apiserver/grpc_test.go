@@ -0,0 +1,205 @@
+package apiserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	grpcapi "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/grpcapi"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startGRPCServer serves the gRPC API on a local port and returns a client of it, along with a function that stops both
+func startGRPCServer(t *testing.T, listRequiresAuth bool) (grpcapi.DedicatedGameServersClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	server := newGRPCServer(listRequiresAuth, nil, 0)
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return grpcapi.NewDedicatedGameServersClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func withBearerToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestGRPCUpdatesDGSs(t *testing.T) {
+	fake := &fakeDGSUpdates{dgss: map[string]*dgsv1alpha1.DedicatedGameServer{
+		"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}},
+	}}
+	statusUpdates = newDGSUpdateCoalescer(fake.update, nil)
+	sink := &fakeAuditSink{}
+	auditSink = sink
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("patch", &reviews)
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		auditSink, tokenAuthenticator = nil, nil
+	}()
+
+	client, stop := startGRPCServer(t, false)
+	defer stop()
+	ctx := withBearerToken(context.Background(), "valid-token")
+
+	dgsStatus, err := client.SetHealth(ctx, &grpcapi.SetHealthRequest{Namespace: "title1", Name: "dgs1", Health: grpcapi.Health_HEALTHY})
+	assert.NoError(t, err)
+	assert.Equal(t, grpcapi.Health_HEALTHY, dgsStatus.GetHealth())
+	assert.Equal(t, dgsv1alpha1.DGSHealthy, fake.dgss["dgs1"].Status.Health)
+
+	// the call is authorized and audited as the status method of the JSON API
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, "status", reviews[0].Spec.ResourceAttributes.Subresource)
+		assert.Equal(t, "dgs1", reviews[0].Spec.ResourceAttributes.Name)
+	}
+	if assert.Len(t, sink.events, 1) {
+		event := sink.events[0]
+		assert.Equal(t, "system:serviceaccount:title1:matchmaker", event.User)
+		assert.Equal(t, "/azuregaming.apiserver.v1.DedicatedGameServers/SetHealth", event.Path)
+		assert.Equal(t, "127.0.0.1", event.SourceIP)
+		assert.Equal(t, "title1", event.Namespace)
+		assert.Equal(t, "dgs1", event.Name)
+		assert.Equal(t, "Success", event.Result)
+	}
+
+	// the values are validated as in the JSON API
+	_, err = client.SetHealth(ctx, &grpcapi.SetHealthRequest{Namespace: "title1", Name: "dgs1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the errors are converted to the corresponding status codes
+	_, err = client.SetPlayers(ctx, &grpcapi.SetPlayersRequest{Namespace: "title1", Name: "dgs1", PlayerCount: 5})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "The token is not allowed to update the players")
+	_, err = client.SetHealth(withBearerToken(context.Background(), "invalid-token"), &grpcapi.SetHealthRequest{Namespace: "title1", Name: "dgs1", Health: grpcapi.Health_FAILED})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.MarkForDeletion(ctx, &grpcapi.MarkForDeletionRequest{Namespace: "title1", Name: "dgs2", MarkedForDeletion: true})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCListsAndWatchesDGSs(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	dgsClient := startWatchedCache(t, stopCh)
+	defer func() { informerCache = nil }()
+
+	client, stop := startGRPCServer(t, false)
+	defer stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	list, err := client.List(ctx, &grpcapi.ListRequest{Namespace: "default"})
+	if assert.NoError(t, err) && assert.Len(t, list.Items, 1) {
+		assert.Equal(t, "dgs1", list.Items[0].Name)
+		assert.Equal(t, grpcapi.Health_HEALTHY, list.Items[0].Status.Health)
+		assert.Equal(t, "Running", list.Items[0].Status.PodPhase)
+	}
+	list, err = client.List(ctx, &grpcapi.ListRequest{Namespace: "default", Filters: &grpcapi.Filters{State: grpcapi.State_RUNNING}})
+	assert.NoError(t, err)
+	assert.Empty(t, list.GetItems())
+	_, err = client.List(ctx, &grpcapi.ListRequest{Namespace: "default", SortBy: "players"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err := client.Watch(ctx, &grpcapi.WatchRequest{Namespace: "default"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	event, err := stream.Recv()
+	if assert.NoError(t, err) {
+		assert.Equal(t, grpcapi.WatchEvent_ADDED, event.Type)
+		assert.Equal(t, "dgs1", event.Object.Name)
+	}
+	_, err = dgsClient.AzuregamingV1alpha1().DedicatedGameServers("default").Create(newCachedDGS("default", "dgs2", dgsv1alpha1.DGSHealthy))
+	assert.NoError(t, err)
+	event, err = stream.Recv()
+	if assert.NoError(t, err) {
+		assert.Equal(t, grpcapi.WatchEvent_ADDED, event.Type)
+		assert.Equal(t, "dgs2", event.Object.Name)
+	}
+	cancel()
+	waitForWatchersToStop(t)
+
+	// the stream fails with the error of the watch
+	stream, err = client.Watch(context.Background(), &grpcapi.WatchRequest{Namespace: "default", ResourceVersion: "latest"})
+	if assert.NoError(t, err) {
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestGRPCListRequiresAuthentication(t *testing.T) {
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("update", &reviews)
+	defer func() { tokenAuthenticator = nil }()
+
+	client, stop := startGRPCServer(t, true)
+	defer stop()
+
+	_, err := client.List(withBearerToken(context.Background(), "valid-token"), &grpcapi.ListRequest{Namespace: "title1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, "list", reviews[0].Spec.ResourceAttributes.Verb)
+	}
+}
+
+func TestGRPCAllocatesDGSs(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	startAllocationCache(t, stopCh,
+		newAllocatableDGS("dgs1", dgsv1alpha1.DGSIdle, "1"),
+		newAllocatableDGS("dgs2", dgsv1alpha1.DGSIdle, "2"),
+	)
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("update", &reviews)
+	sink := &fakeAuditSink{}
+	auditSink = sink
+	defer func() {
+		statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil)
+		informerCache, tokenAuthenticator, auditSink = nil, nil, nil
+	}()
+
+	client, stop := startGRPCServer(t, false)
+	defer stop()
+	ctx := withBearerToken(context.Background(), "valid-token")
+
+	// the call is served by the allocate method of the JSON API
+	response, err := client.Allocate(ctx, &grpcapi.AllocateRequest{Namespace: "title1", SortBy: "-name"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "dgs2", response.Object.Name)
+		assert.Equal(t, grpcapi.State_ASSIGNED, response.Object.Status.State)
+		if assert.Len(t, response.Object.Status.Ports, 1) {
+			assert.Equal(t, int32(20001), response.Object.Status.Ports[0].HostPort)
+		}
+	}
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, "allocate", reviews[0].Spec.ResourceAttributes.Subresource)
+	}
+	if assert.Len(t, sink.events, 1) {
+		assert.Equal(t, "dgs2", sink.events[0].Name)
+		assert.Equal(t, "/azuregaming.apiserver.v1.DedicatedGameServers/Allocate", sink.events[0].Path)
+	}
+
+	_, err = client.Allocate(ctx, &grpcapi.AllocateRequest{Namespace: "title1", Filters: &grpcapi.Filters{State: grpcapi.State_RUNNING}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Allocate(ctx, &grpcapi.AllocateRequest{Namespace: "title1", Filters: &grpcapi.Filters{Collection: "other"}})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "No DedicatedGameServer passes the filters")
+	_, err = client.Allocate(withBearerToken(context.Background(), "invalid-token"), &grpcapi.AllocateRequest{Namespace: "title1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
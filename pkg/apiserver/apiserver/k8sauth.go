@@ -0,0 +1,131 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	log "github.com/sirupsen/logrus"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	dgsResource    = "dedicatedgameservers"
+	dgsColResource = "dedicatedgameservercollections"
+)
+
+// authzAttributes describe the operation that a request performs, so it can be authorized via a SubjectAccessReview
+type authzAttributes struct {
+	verb        string
+	resource    string
+	subresource string
+}
+
+// k8sAuthenticator authenticates bearer tokens via the Kubernetes TokenReview API
+// and authorizes them via the SubjectAccessReview API, so access can be granted via ordinary RBAC
+type k8sAuthenticator struct {
+	client kubernetes.Interface
+}
+
+// tokenAuthenticator is used for the requests that carry an 'Authorization: Bearer' header
+var tokenAuthenticator *k8sAuthenticator
+
+func newK8sAuthenticator(client kubernetes.Interface) *k8sAuthenticator {
+	return &k8sAuthenticator{client: client}
+}
+
+// bearerToken returns the bearer token of the request, or an empty string if the request does not have one
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// reviewToken validates the token via a TokenReview and returns its user
+func (a *k8sAuthenticator) reviewToken(token string) (authenticationv1.UserInfo, error) {
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		log.Errorf("Error in TokenReview: %v", err)
+		return authenticationv1.UserInfo{}, apierrors.NewInternalError(err)
+	}
+	if !review.Status.Authenticated {
+		return authenticationv1.UserInfo{}, apierrors.NewUnauthorized("Unauthorized")
+	}
+	return review.Status.User, nil
+}
+
+// authorizeUser checks via a SubjectAccessReview whether the user is allowed to perform the operation on the designated object
+func (a *k8sAuthenticator) authorizeUser(user authenticationv1.UserInfo, attrs authzAttributes, namespace string, name string) error {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	sar, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        attrs.verb,
+				Group:       dgsv1alpha1.SchemeGroupVersion.Group,
+				Version:     dgsv1alpha1.SchemeGroupVersion.Version,
+				Resource:    attrs.resource,
+				Subresource: attrs.subresource,
+				Name:        name,
+			},
+		},
+	})
+	if err != nil {
+		log.Errorf("Error in SubjectAccessReview: %v", err)
+		return apierrors.NewInternalError(err)
+	}
+	if !sar.Status.Allowed {
+		return apierrors.NewForbidden(dgsv1alpha1.Resource(attrs.resource), name,
+			fmt.Errorf("user %s cannot %s %s in namespace %s: %s", user.Username, attrs.verb, attrs.resource, namespace, sar.Status.Reason))
+	}
+	return nil
+}
+
+// tokenReviewContextKey is the context key of the tokenReview of a request
+type tokenReviewContextKey struct{}
+
+// tokenReview is attached to the requests that authenticate several objects (e.g. a batch update),
+// so that their bearer token is reviewed only once
+type tokenReview struct {
+	once sync.Once
+	user authenticationv1.UserInfo
+	err  error
+}
+
+// withTokenReview returns the request with a tokenReview attached, see reviewRequestToken
+func withTokenReview(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), tokenReviewContextKey{}, &tokenReview{}))
+}
+
+// reviewRequestToken validates the bearer token of the request and returns its user
+// If the request has a tokenReview attached, the token is reviewed only for the first call and the next calls return the same result
+func reviewRequestToken(r *http.Request, token string) (authenticationv1.UserInfo, error) {
+	review, _ := r.Context().Value(tokenReviewContextKey{}).(*tokenReview)
+	if review == nil {
+		return tokenAuthenticator.reviewToken(token)
+	}
+	review.once.Do(func() {
+		review.user, review.err = tokenAuthenticator.reviewToken(token)
+	})
+	return review.user, review.err
+}
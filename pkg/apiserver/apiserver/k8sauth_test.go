@@ -0,0 +1,99 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+// newFakeAuthenticator returns a k8sAuthenticator whose fake client authenticates 'valid-token' as the matchmaker ServiceAccount
+// and allows the designated verb. The SubjectAccessReviews that the client receives are appended to reviews
+func newFakeAuthenticator(allowedVerb string, reviews *[]authorizationv1.SubjectAccessReview) *k8sAuthenticator {
+	client := k8sfake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action core.Action) (bool, runtime.Object, error) {
+		review := action.(core.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token != "valid-token" {
+			return true, &authenticationv1.TokenReview{}, nil
+		}
+		return true, &authenticationv1.TokenReview{
+			Status: authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User: authenticationv1.UserInfo{
+					Username: "system:serviceaccount:title1:matchmaker",
+					Groups:   []string{"system:serviceaccounts"},
+				},
+			},
+		}, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+		sar := action.(core.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		*reviews = append(*reviews, *sar)
+		return true, &authorizationv1.SubjectAccessReview{
+			Status: authorizationv1.SubjectAccessReviewStatus{
+				Allowed: sar.Spec.ResourceAttributes.Verb == allowedVerb,
+			},
+		}, nil
+	})
+	return newK8sAuthenticator(client)
+}
+
+func serveAuthenticated(attrs authzAttributes, token string, vars map[string]string) (int, bool) {
+	called := false
+	handler := authenticated(attrs, func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	r := httptest.NewRequest("GET", "/api/v1/namespaces/title1/dedicatedgameservers", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	r = mux.SetURLVars(r, vars)
+
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w.Code, called
+}
+
+func TestBearerTokenIsAuthorizedViaSubjectAccessReview(t *testing.T) {
+	defer func() { tokenAuthenticator = nil }()
+
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("list", &reviews)
+
+	_, called := serveAuthenticated(authzAttributes{"list", dgsResource, ""}, "valid-token", map[string]string{"namespace": "title1"})
+	assert.True(t, called)
+
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "system:serviceaccount:title1:matchmaker", reviews[0].Spec.User)
+	assert.Equal(t, &authorizationv1.ResourceAttributes{
+		Namespace: "title1",
+		Verb:      "list",
+		Group:     "azuregaming.com",
+		Version:   "v1alpha1",
+		Resource:  "dedicatedgameservers",
+	}, reviews[0].Spec.ResourceAttributes)
+}
+
+func TestBearerTokenIsRejected(t *testing.T) {
+	defer func() { tokenAuthenticator = nil }()
+
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("list", &reviews)
+
+	// invalid token
+	code, called := serveAuthenticated(authzAttributes{"list", dgsResource, ""}, "invalid-token", map[string]string{"namespace": "title1"})
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Empty(t, reviews)
+
+	// valid token, verb not allowed
+	code, called = serveAuthenticated(authzAttributes{"delete", dgsColResource, ""}, "valid-token", map[string]string{"namespace": "title1", "name": "col"})
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "col", reviews[0].Spec.ResourceAttributes.Name)
+}
@@ -0,0 +1,228 @@
+package apiserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// the fields that a DedicatedGameServer listing can be sorted by, a "-" prefix sorts in descending order
+const (
+	sortByName              = "name"
+	sortByActivePlayers     = "activePlayers"
+	sortByCreationTimestamp = "creationTimestamp"
+)
+
+// dgsListOptions contains the filters, the sort order and the page size of a DedicatedGameServer listing
+type dgsListOptions struct {
+	selector     labels.Selector
+	state        dgsv1alpha1.DGSState
+	nodeName     string
+	minFreeSlots int
+	sortBy       string
+	limit        int
+	// after is the position of the last DedicatedGameServer of the previous page
+	after *dgsListKey
+}
+
+// dgsListKey is the position of a DedicatedGameServer in a sorted listing
+// The key of the last DedicatedGameServer of a page is the continue token of the next page
+type dgsListKey struct {
+	SortBy string `json:"sortBy"`
+	Value  int64  `json:"value,omitempty"`
+	Name   string `json:"name"`
+}
+
+// parseDGSListOptions reads the listing options from the query parameters of the request
+func parseDGSListOptions(r *http.Request) (*dgsListOptions, error) {
+	query := r.URL.Query()
+	options := &dgsListOptions{
+		selector: labels.Everything(),
+		state:    dgsv1alpha1.DGSState(query.Get("state")),
+		nodeName: query.Get("node"),
+		sortBy:   sortByName,
+	}
+
+	if labelSelector := query.Get("labelSelector"); labelSelector != "" {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("Wrong value for labelSelector: %s", err.Error()))
+		}
+		options.selector = selector
+	}
+
+	if collection := query.Get("collection"); collection != "" {
+		requirement, err := labels.NewRequirement(shared.LabelDedicatedGameServerCollectionName, selection.Equals, []string{collection})
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("Wrong value for collection: %s", err.Error()))
+		}
+		options.selector = options.selector.Add(*requirement)
+	}
+
+	if options.state != "" {
+		if err := validateDGSState(options.state); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if options.minFreeSlots, err = parseNonNegativeInt(query.Get("minFreeSlots"), "minFreeSlots"); err != nil {
+		return nil, err
+	}
+	if options.limit, err = parseNonNegativeInt(query.Get("limit"), "limit"); err != nil {
+		return nil, err
+	}
+
+	if sortBy := query.Get("sortBy"); sortBy != "" {
+		switch strings.TrimPrefix(sortBy, "-") {
+		case sortByName, sortByActivePlayers, sortByCreationTimestamp:
+			options.sortBy = sortBy
+		default:
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("Wrong value for sortBy: %s", sortBy))
+		}
+	}
+
+	if token := query.Get("continue"); token != "" {
+		if options.after, err = decodeContinueToken(token, options.sortBy); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+func parseNonNegativeInt(value string, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, apierrors.NewBadRequest(fmt.Sprintf("Wrong value for %s: %s", name, value))
+	}
+	return i, nil
+}
+
+// decodeContinueToken returns the position that the continue token refers to
+// The token is only valid for the sort order of the listing that returned it
+func decodeContinueToken(token string, sortBy string) (*dgsListKey, error) {
+	invalid := apierrors.NewBadRequest("Wrong value for continue, it must be the token returned by the previous page of the same listing")
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	var key dgsListKey
+	if err := json.Unmarshal(data, &key); err != nil || key.SortBy != sortBy {
+		return nil, invalid
+	}
+	return &key, nil
+}
+
+func encodeContinueToken(key dgsListKey) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// key returns the position of the DedicatedGameServer in the listing
+func (o *dgsListOptions) key(dgs *dgsv1alpha1.DedicatedGameServer) dgsListKey {
+	key := dgsListKey{SortBy: o.sortBy, Name: dgs.Name}
+	switch strings.TrimPrefix(o.sortBy, "-") {
+	case sortByActivePlayers:
+		key.Value = int64(dgs.Status.ActivePlayers)
+	case sortByCreationTimestamp:
+		key.Value = dgs.CreationTimestamp.Unix()
+	}
+	return key
+}
+
+// less returns true if the position a comes before the position b
+// DedicatedGameServers with the same value are sorted by name
+func (o *dgsListOptions) less(a dgsListKey, b dgsListKey) bool {
+	if a.Value != b.Value {
+		return (a.Value < b.Value) != strings.HasPrefix(o.sortBy, "-")
+	}
+	if strings.HasPrefix(o.sortBy, "-") && strings.TrimPrefix(o.sortBy, "-") == sortByName {
+		return a.Name > b.Name
+	}
+	return a.Name < b.Name
+}
+
+// apply filters and sorts the DedicatedGameServers and returns the requested page of them
+// along with the continue token of the next page, which is empty if this is the last page
+func (o *dgsListOptions) apply(dgss []dgsv1alpha1.DedicatedGameServer) ([]dgsv1alpha1.DedicatedGameServer, string) {
+	maxPlayers := make(map[string]int)
+	filtered := make([]dgsv1alpha1.DedicatedGameServer, 0, len(dgss))
+	for i := range dgss {
+		if o.matches(&dgss[i], maxPlayers) {
+			filtered = append(filtered, dgss[i])
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return o.less(o.key(&filtered[i]), o.key(&filtered[j]))
+	})
+
+	if o.after != nil {
+		start := sort.Search(len(filtered), func(i int) bool {
+			return o.less(*o.after, o.key(&filtered[i]))
+		})
+		filtered = filtered[start:]
+	}
+
+	if o.limit > 0 && len(filtered) > o.limit {
+		filtered = filtered[:o.limit]
+		return filtered, encodeContinueToken(o.key(&filtered[o.limit-1]))
+	}
+	return filtered, ""
+}
+
+// matches returns true if the DedicatedGameServer passes the filters of the listing
+// maxPlayers caches the maximum players per DedicatedGameServer of the collections, it is keyed by namespace and collection name
+func (o *dgsListOptions) matches(dgs *dgsv1alpha1.DedicatedGameServer, maxPlayers map[string]int) bool {
+	if !o.selector.Matches(labels.Set(dgs.Labels)) {
+		return false
+	}
+	if o.state != "" && dgs.Status.DGSState != o.state {
+		return false
+	}
+	if o.nodeName != "" && dgs.Status.NodeName != o.nodeName {
+		return false
+	}
+	if o.minFreeSlots > 0 {
+		// DedicatedGameServers without a known capacity are excluded
+		collection := dgs.Labels[shared.LabelDedicatedGameServerCollectionName]
+		key := dgs.Namespace + "/" + collection
+		max, ok := maxPlayers[key]
+		if !ok {
+			max = maxPlayersPerServer(dgs.Namespace, collection)
+			maxPlayers[key] = max
+		}
+		if max-dgs.Status.ActivePlayers < o.minFreeSlots {
+			return false
+		}
+	}
+	return true
+}
+
+// maxPlayersPerServer returns the maximum players per DedicatedGameServer of the collection, as set in its autoscaler details
+// It returns 0 if the collection does not exist or does not set a maximum
+func maxPlayersPerServer(namespace string, collection string) int {
+	if collection == "" {
+		return 0
+	}
+	dgsCol, err := getDGSCol(namespace, collection)
+	if err != nil || dgsCol.Spec.DGSActivePlayersAutoScalerDetails == nil {
+		return 0
+	}
+	return dgsCol.Spec.DGSActivePlayersAutoScalerDetails.MaxPlayersPerServer
+}
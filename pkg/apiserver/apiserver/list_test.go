@@ -0,0 +1,148 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned/fake"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func newListedDGS(name string, collection string, players int, state dgsv1alpha1.DGSState, node string, age time.Duration) *dgsv1alpha1.DedicatedGameServer {
+	dgs := newCachedDGS("default", name, dgsv1alpha1.DGSHealthy)
+	dgs.Labels = map[string]string{shared.LabelDedicatedGameServerCollectionName: collection}
+	dgs.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+	dgs.Status.ActivePlayers = players
+	dgs.Status.DGSState = state
+	dgs.Status.NodeName = node
+	return dgs
+}
+
+func newListedDGSCol(name string, maxPlayers int) *dgsv1alpha1.DedicatedGameServerCollection {
+	dgsCol := &dgsv1alpha1.DedicatedGameServerCollection{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	if maxPlayers > 0 {
+		dgsCol.Spec.DGSActivePlayersAutoScalerDetails = &dgsv1alpha1.DGSActivePlayersAutoScalerDetails{MaxPlayersPerServer: maxPlayers}
+	}
+	return dgsCol
+}
+
+// startListCache fills the informer cache with the DedicatedGameServers and DedicatedGameServerCollections used by the listing tests
+func startListCache(t *testing.T, stopCh chan struct{}) {
+	client := fake.NewSimpleClientset(
+		newListedDGS("dgs1", "ctf", 10, dgsv1alpha1.DGSRunning, "node1", 3*time.Hour),
+		newListedDGS("dgs2", "ctf", 2, dgsv1alpha1.DGSIdle, "node2", 2*time.Hour),
+		newListedDGS("dgs3", "ctf", 6, dgsv1alpha1.DGSRunning, "node1", 1*time.Hour),
+		newListedDGS("dgs4", "conquest", 2, dgsv1alpha1.DGSIdle, "node2", 4*time.Hour),
+		newListedDGS("dgs5", "unlimited", 0, dgsv1alpha1.DGSIdle, "node1", 5*time.Hour),
+		newListedDGSCol("ctf", 12),
+		newListedDGSCol("conquest", 32),
+		newListedDGSCol("unlimited", 0),
+	)
+	informerCache = newObjectCache(client, nil, stopCh)
+	assert.NoError(t, wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return informerCache.hasSynced(), nil
+	}))
+}
+
+// listNames calls the /running handler with the query and returns the names of the DedicatedGameServers and the continue header
+func listNames(t *testing.T, query string) ([]string, string) {
+	w := httptest.NewRecorder()
+	getPodPhaseRunningDGSHandler(w, httptest.NewRequest(http.MethodGet, "/running?"+query, nil))
+	if !assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+		return nil, ""
+	}
+	var dgss []dgsv1alpha1.DedicatedGameServer
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &dgss))
+	names := make([]string, 0, len(dgss))
+	for _, dgs := range dgss {
+		names = append(names, dgs.Name)
+	}
+	return names, w.Header().Get(helpers.ContinueHeader)
+}
+
+func TestListDGSFilters(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	startListCache(t, stopCh)
+	defer func() { informerCache = nil }()
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"dgs1", "dgs2", "dgs3", "dgs4", "dgs5"}},
+		{"collection=ctf", []string{"dgs1", "dgs2", "dgs3"}},
+		{"labelSelector=DedicatedGameServerCollectionName+in+(conquest,unlimited)", []string{"dgs4", "dgs5"}},
+		{"state=Idle&node=node2", []string{"dgs2", "dgs4"}},
+		// dgs5 has no known capacity, dgs1 has 2 free slots
+		{"minFreeSlots=4", []string{"dgs2", "dgs3", "dgs4"}},
+		{"collection=ctf&minFreeSlots=7", []string{"dgs2"}},
+		{"sortBy=-activePlayers", []string{"dgs1", "dgs3", "dgs2", "dgs4", "dgs5"}},
+		{"sortBy=activePlayers", []string{"dgs5", "dgs2", "dgs4", "dgs3", "dgs1"}},
+		{"sortBy=creationTimestamp", []string{"dgs5", "dgs4", "dgs1", "dgs2", "dgs3"}},
+		{"sortBy=-name", []string{"dgs5", "dgs4", "dgs3", "dgs2", "dgs1"}},
+	}
+	for _, test := range tests {
+		names, continueToken := listNames(t, test.query)
+		assert.Equal(t, test.expected, names, test.query)
+		assert.Empty(t, continueToken, test.query)
+	}
+}
+
+func TestListDGSPagination(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	startListCache(t, stopCh)
+	defer func() { informerCache = nil }()
+
+	names, continueToken := listNames(t, "sortBy=-activePlayers&limit=2")
+	assert.Equal(t, []string{"dgs1", "dgs3"}, names)
+	assert.NotEmpty(t, continueToken)
+
+	names, continueToken = listNames(t, "sortBy=-activePlayers&limit=2&continue="+continueToken)
+	assert.Equal(t, []string{"dgs2", "dgs4"}, names)
+	assert.NotEmpty(t, continueToken)
+
+	names, continueToken = listNames(t, "sortBy=-activePlayers&limit=2&continue="+continueToken)
+	assert.Equal(t, []string{"dgs5"}, names)
+	assert.Empty(t, continueToken)
+
+	// the v1 API returns the continue token in the list metadata
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/default/dedicatedgameservers?limit=3", nil)
+	r = mux.SetURLVars(r, map[string]string{"namespace": "default"})
+	w := httptest.NewRecorder()
+	listDGSHandler(w, r)
+	var list dgsv1alpha1.DedicatedGameServerList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Items, 3)
+	assert.NotEmpty(t, list.Continue)
+}
+
+func TestParseDGSListOptionsErrors(t *testing.T) {
+	for _, query := range []string{
+		"labelSelector=a%3D%3D%3Db",
+		"collection=not+valid",
+		"state=Sleeping",
+		"minFreeSlots=-1",
+		"limit=ten",
+		"sortBy=health",
+		"continue=garbage",
+		// the token belongs to a listing with a different sort order
+		"sortBy=activePlayers&continue=" + encodeContinueToken(dgsListKey{SortBy: "name", Name: "dgs1"}),
+	} {
+		_, err := parseDGSListOptions(httptest.NewRequest(http.MethodGet, "/running?"+query, nil))
+		assert.True(t, apierrors.IsBadRequest(err), query)
+	}
+}
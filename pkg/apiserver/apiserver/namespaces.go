@@ -0,0 +1,54 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// servedNamespaces contains the namespaces that the API Server serves. If empty, all namespaces are served
+var servedNamespaces []string
+
+// requestNamespace returns the namespace of the request
+// This is the {namespace} path variable for the v1 API and the 'namespace' query parameter for the legacy routes
+// If neither is set, the default GameNamespace is returned
+func requestNamespace(r *http.Request) string {
+	if namespace := mux.Vars(r)["namespace"]; namespace != "" {
+		return namespace
+	}
+	if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+		return namespace
+	}
+	return shared.GameNamespace
+}
+
+// checkNamespace returns a Forbidden error if the API Server does not serve the designated namespace
+func checkNamespace(namespace string) error {
+	if len(servedNamespaces) == 0 {
+		return nil
+	}
+	for _, served := range servedNamespaces {
+		if served == namespace {
+			return nil
+		}
+	}
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, namespace,
+		fmt.Errorf("namespace %s is not served by this API Server", namespace))
+}
+
+// namespaced wraps the handler so that it is called only if the request's namespace is served by the API Server
+func namespaced(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := checkNamespace(requestNamespace(r)); err != nil {
+			writeError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
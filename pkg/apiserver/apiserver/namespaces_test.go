@@ -0,0 +1,40 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestRequestNamespace(t *testing.T) {
+	r := httptest.NewRequest("GET", "/running?namespace=title1", nil)
+	assert.Equal(t, "title1", requestNamespace(r))
+
+	r = httptest.NewRequest("GET", "/running", nil)
+	assert.Equal(t, "default", requestNamespace(r))
+
+	r = mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/namespaces/title2/dedicatedgameservers", nil), map[string]string{"namespace": "title2"})
+	assert.Equal(t, "title2", requestNamespace(r))
+}
+
+func TestServedNamespaces(t *testing.T) {
+	defer func() { servedNamespaces = nil }()
+
+	assert.NoError(t, checkNamespace("title1"))
+
+	servedNamespaces = []string{"title1", "title2"}
+	assert.NoError(t, checkNamespace("title2"))
+	assert.True(t, apierrors.IsForbidden(checkNamespace("title3")))
+
+	called := false
+	handler := namespaced(func(w http.ResponseWriter, r *http.Request) { called = true })
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/running?namespace=title3", nil))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,615 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	openAPIPath    = "/openapi.json"
+	openAPIVersion = "3.0.0"
+	// the package path of the types of this project, their schemas are named after the package and the type (e.g. helpers.ServerState)
+	projectPackagePrefix = "github.com/dgkanatsios/azuregameserversscalingkubernetes/"
+)
+
+// openAPIDocument is an OpenAPI 3 document
+// Only the parts of the specification that the API Server uses are declared
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Tags        []string                    `json:"tags"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	// Security lists the alternative ways to authenticate, an empty requirement means that authentication is optional
+	Security []map[string][]string `json:"security"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Headers     map[string]*openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+// openAPISchema is a schema object, an empty schema allows any value
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+}
+
+// openAPIEnums contains the allowed values of the string types, which cannot be found via reflection
+var openAPIEnums = map[reflect.Type][]string{
+	reflect.TypeOf(dgsv1alpha1.DGSState("")):            openAPIEnumValues(dgsv1alpha1.AllDGSStates),
+	reflect.TypeOf(dgsv1alpha1.DGSHealth("")):           openAPIEnumValues(dgsv1alpha1.AllDGSHealths),
+	reflect.TypeOf(dgsv1alpha1.DGSIPFamily("")):         openAPIEnumValues(dgsv1alpha1.AllDGSIPFamilies),
+	reflect.TypeOf(dgsv1alpha1.DGSPortExposureMode("")): openAPIEnumValues(dgsv1alpha1.AllDGSPortExposureModes),
+	reflect.TypeOf(corev1.PodPhase("")):                 {string(corev1.PodPending), string(corev1.PodRunning), string(corev1.PodSucceeded), string(corev1.PodFailed), string(corev1.PodUnknown)},
+	reflect.TypeOf(watch.EventType("")):                 {string(watch.Added), string(watch.Modified), string(watch.Deleted), string(watch.Error)},
+}
+
+// openAPIEnumValues returns the values of a slice of string constants as strings
+func openAPIEnumValues(constants interface{}) []string {
+	slice := reflect.ValueOf(constants)
+	values := make([]string, slice.Len())
+	for i := range values {
+		values[i] = slice.Index(i).String()
+	}
+	return values
+}
+
+// openAPIFieldTypes documents the string fields of the legacy payloads with the enum type of their values
+var openAPIFieldTypes = map[reflect.Type]map[string]reflect.Type{
+	reflect.TypeOf(helpers.ServerState{}):     {"State": reflect.TypeOf(dgsv1alpha1.DGSState(""))},
+	reflect.TypeOf(helpers.ServerHealth{}):    {"Health": reflect.TypeOf(dgsv1alpha1.DGSHealth(""))},
+	reflect.TypeOf(helpers.DGSStatusUpdate{}): {"State": reflect.TypeOf(dgsv1alpha1.DGSState("")), "Health": reflect.TypeOf(dgsv1alpha1.DGSHealth(""))},
+	reflect.TypeOf(helpers.DGSUpdate{}):       {"State": reflect.TypeOf(dgsv1alpha1.DGSState("")), "Health": reflect.TypeOf(dgsv1alpha1.DGSHealth(""))},
+}
+
+// openAPIFormattedTypes contains the schemas of the types that have a custom JSON representation
+var openAPIFormattedTypes = map[reflect.Type]*openAPISchema{
+	reflect.TypeOf(metav1.Time{}):          {Type: "string", Format: "date-time"},
+	reflect.TypeOf(metav1.MicroTime{}):     {Type: "string", Format: "date-time"},
+	reflect.TypeOf(resource.Quantity{}):    {Type: "string"},
+	reflect.TypeOf(intstr.IntOrString{}):   {OneOf: []*openAPISchema{{Type: "integer"}, {Type: "string"}}},
+	reflect.TypeOf(runtime.RawExtension{}): {Type: "object"},
+}
+
+// schemaGenerator builds the schemas of Go types, following the encoding/json rules
+// Named struct types are added to the components and referenced
+type schemaGenerator struct {
+	schemas map[string]*openAPISchema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*openAPISchema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema of the type of the value
+func (g *schemaGenerator) of(v interface{}) *openAPISchema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *openAPISchema {
+	if schema, ok := openAPIFormattedTypes[t]; ok {
+		copied := *schema
+		return &copied
+	}
+	if values, ok := openAPIEnums[t]; ok {
+		return &openAPISchema{Type: "string", Enum: values}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Uint:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		values := g.schema(t.Elem())
+		// e.g. the labels of a DGSUpdate, where a null value removes the label
+		values.Nullable = t.Elem().Kind() == reflect.Ptr && values.Ref == ""
+		return &openAPISchema{Type: "object", AdditionalProperties: values}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	default:
+		// interfaces can contain any value
+		return &openAPISchema{}
+	}
+}
+
+// ref adds the schema of the named struct type to the components (if it is not there already) and returns a reference to it
+func (g *schemaGenerator) ref(t reflect.Type) *openAPISchema {
+	name, ok := g.names[t]
+	if !ok {
+		name = schemaName(t)
+		if _, exists := g.schemas[name]; exists {
+			panic(fmt.Sprintf("OpenAPI schema %s is declared by more than one type", name))
+		}
+		g.names[t] = name
+		// the name is registered before the properties, so that recursive types refer to themselves
+		g.schemas[name] = &openAPISchema{}
+		*g.schemas[name] = *g.structSchema(t)
+	}
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema returns the schema of the JSON object that the struct is encoded to
+func (g *schemaGenerator) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, options = tag[:comma], tag[comma:]
+		}
+
+		fieldType := field.Type
+		if documented, ok := openAPIFieldTypes[t][field.Name]; ok {
+			fieldType = documented
+		}
+
+		// the fields of embedded structs without a name (e.g. TypeMeta) are encoded as fields of the outer struct
+		if field.Anonymous && name == "" {
+			embedded := fieldType
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = g.schema(fieldType)
+		if field.Type.Kind() != reflect.Ptr && !strings.Contains(options, ",omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// schemaName returns the name of the component of the type
+// The types of this project are named after their package (e.g. v1alpha1.DedicatedGameServer), other types
+// after their full package path, as in the Kubernetes OpenAPI document (e.g. io.k8s.api.core.v1.PodSpec)
+func schemaName(t reflect.Type) string {
+	path := t.PkgPath()
+	if vendor := strings.LastIndex(path, "/vendor/"); vendor >= 0 {
+		path = path[vendor+len("/vendor/"):]
+	}
+	if strings.HasPrefix(path, projectPackagePrefix) {
+		return path[strings.LastIndex(path, "/")+1:] + "." + t.Name()
+	}
+	parts := strings.Split(path, "/")
+	domain := strings.Split(parts[0], ".")
+	for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+		domain[i], domain[j] = domain[j], domain[i]
+	}
+	return strings.Join(append(append(domain, parts[1:]...), t.Name()), ".")
+}
+
+// the security requirements of the operations
+var (
+	accessCodeSecurity = []map[string][]string{{"accessCode": {}}, {"accessCodeHeader": {}}, {"bearerToken": {}}}
+	noSecurity         = []map[string][]string{}
+)
+
+// newOpenAPIDocument returns the OpenAPI document of the routes that newRouter registers
+// listRequiresAuth determines whether the DedicatedGameServer read operations require authentication
+func newOpenAPIDocument(listRequiresAuth bool) *openAPIDocument {
+	g := newSchemaGenerator()
+
+	readSecurity := noSecurity
+	if listRequiresAuth {
+		readSecurity = accessCodeSecurity
+	}
+
+	errorSchema := g.of(helpers.APIError{})
+	jsonContent := func(schema *openAPISchema) map[string]*openAPIMediaType {
+		return map[string]*openAPIMediaType{"application/json": {Schema: schema}}
+	}
+	jsonBody := func(v interface{}) *openAPIRequestBody {
+		return &openAPIRequestBody{Required: true, Content: jsonContent(g.of(v))}
+	}
+	responses := func(code int, description string, schema *openAPISchema) map[string]*openAPIResponse {
+		response := &openAPIResponse{Description: description}
+		if schema != nil {
+			response.Content = jsonContent(schema)
+		}
+		return map[string]*openAPIResponse{
+			fmt.Sprint(code): response,
+			"default":        {Description: "The error that occurred", Content: jsonContent(errorSchema)},
+		}
+	}
+
+	pathParameter := func(name, description string) *openAPIParameter {
+		return &openAPIParameter{Name: name, In: "path", Description: description, Required: true, Schema: &openAPISchema{Type: "string"}}
+	}
+	queryParameter := func(name, description string, schema *openAPISchema) *openAPIParameter {
+		return &openAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+	}
+	stringSchema := &openAPISchema{Type: "string"}
+	integerSchema := &openAPISchema{Type: "integer"}
+
+	namespaceParameter := pathParameter("namespace", "The namespace of the object")
+	nameParameter := pathParameter("name", "The name of the object")
+	legacyNamespaceParameter := queryParameter("namespace", "The namespace of the DedicatedGameServer, if it is not set in the request body (default is "+shared.GameNamespace+")", stringSchema)
+	dryRunParameter := queryParameter("dryRun", "If set to "+dryRunAll+", the request is validated but not persisted", &openAPISchema{Type: "string", Enum: []string{dryRunAll}})
+	// the filters and the sort order of the listings, which also select the DedicatedGameServer that is allocated
+	filterParameters := []*openAPIParameter{
+		queryParameter("labelSelector", "Returns the DedicatedGameServers that match the Kubernetes label selector", stringSchema),
+		queryParameter("collection", "Returns the DedicatedGameServers of the DedicatedGameServerCollection", stringSchema),
+		queryParameter("state", "Returns the DedicatedGameServers with this state", g.of(dgsv1alpha1.DGSState(""))),
+		queryParameter("node", "Returns the DedicatedGameServers that run on the node", stringSchema),
+		queryParameter("minFreeSlots", "Returns the DedicatedGameServers with at least this number of free player slots", integerSchema),
+		queryParameter("sortBy", "Sorts the DedicatedGameServers by name, activePlayers or creationTimestamp, a '-' prefix sorts in descending order", stringSchema),
+	}
+	listParameters := append(filterParameters,
+		queryParameter("limit", "The maximum number of DedicatedGameServers that are returned", integerSchema),
+		queryParameter("continue", "The continue token of the previous page", stringSchema),
+	)
+
+	dgsSchema := g.of(dgsv1alpha1.DedicatedGameServer{})
+	dgsStatusSchema := g.of(dgsv1alpha1.DedicatedGameServerStatus{})
+	dgsColSchema := g.of(dgsv1alpha1.DedicatedGameServerCollection{})
+	noContent := responses(http.StatusNoContent, "The object was deleted", nil)
+
+	dgsTags := []string{"DedicatedGameServers"}
+	dgsColTags := []string{"DedicatedGameServerCollections"}
+	legacyTags := []string{"Legacy"}
+	serverTags := []string{"Server"}
+
+	watchResponses := responses(http.StatusOK, "The DedicatedGameServers or, for watch requests, a stream of their changes "+
+		"(Server-Sent Events, or WebSocket messages if the request is a WebSocket upgrade)", g.of(dgsv1alpha1.DedicatedGameServerList{}))
+	watchResponses["200"].Content["text/event-stream"] = &openAPIMediaType{Schema: g.of(DGSWatchEvent{})}
+
+	runningResponses := responses(http.StatusOK, "The DedicatedGameServers that are ready to accept players", g.of([]dgsv1alpha1.DedicatedGameServer{}))
+	runningResponses["200"].Headers = map[string]*openAPIHeader{
+		helpers.ContinueHeader: {Description: "The continue token of the next page, if there is one", Schema: stringSchema},
+	}
+
+	legacyUpdate := func(id, summary string, body interface{}) *openAPIOperation {
+		return &openAPIOperation{
+			OperationID: id, Summary: summary, Tags: legacyTags,
+			Parameters:  []*openAPIParameter{legacyNamespaceParameter},
+			RequestBody: jsonBody(body),
+			Responses:   responses(http.StatusOK, "The updated DedicatedGameServer Status", dgsStatusSchema),
+			Security:    accessCodeSecurity,
+		}
+	}
+
+	paths := map[string]map[string]*openAPIOperation{
+		v1Prefix + dgsPath: {
+			"get": {
+				OperationID: "listDedicatedGameServers", Summary: "Lists or watches the DedicatedGameServers of the namespace", Tags: dgsTags,
+				Parameters: append([]*openAPIParameter{namespaceParameter,
+					queryParameter("watch", "If true, the changes of the DedicatedGameServers are streamed", &openAPISchema{Type: "boolean"}),
+					queryParameter("resourceVersion", "Resumes the watch after this resourceVersion (the Last-Event-ID header is used for Server-Sent Events)", stringSchema),
+				}, listParameters...),
+				Responses: watchResponses,
+				Security:  readSecurity,
+			},
+			"post": {
+				OperationID: "createDedicatedGameServer", Summary: "Creates a DedicatedGameServer that does not belong to a DedicatedGameServerCollection", Tags: dgsTags,
+				Parameters:  []*openAPIParameter{namespaceParameter, dryRunParameter},
+				RequestBody: jsonBody(dgsv1alpha1.DedicatedGameServer{}),
+				Responses:   responses(http.StatusCreated, "The created DedicatedGameServer", dgsSchema),
+				Security:    accessCodeSecurity,
+			},
+			"patch": {
+				OperationID: "updateDedicatedGameServers", Summary: "Updates the Status and labels of one or more DedicatedGameServers", Tags: dgsTags,
+				Parameters:  []*openAPIParameter{namespaceParameter},
+				RequestBody: jsonBody(helpers.DGSBatchUpdate{}),
+				Responses:   responses(http.StatusOK, "The result of each update, in the same order", g.of(helpers.DGSBatchUpdateResult{})),
+				Security:    accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsPath + dgsAllocateSubPath: {
+			"post": {
+				OperationID: "allocateDedicatedGameServer", Summary: "Sets the state of an Idle DedicatedGameServer that is ready to accept players to Assigned", Tags: dgsTags,
+				Parameters: append([]*openAPIParameter{namespaceParameter}, filterParameters...),
+				Responses:  responses(http.StatusOK, "The allocated DedicatedGameServer", dgsSchema),
+				Security:   accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsItemPath: {
+			"get": {
+				OperationID: "getDedicatedGameServer", Summary: "Returns the DedicatedGameServer", Tags: dgsTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  responses(http.StatusOK, "The DedicatedGameServer", dgsSchema),
+				Security:   readSecurity,
+			},
+			"delete": {
+				OperationID: "deleteDedicatedGameServer", Summary: "Deletes a DedicatedGameServer that does not belong to a DedicatedGameServerCollection", Tags: dgsTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  noContent,
+				Security:   accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsItemPath + dgsStatusSubPath: {
+			"get": {
+				OperationID: "getDedicatedGameServerStatus", Summary: "Returns the Status of the DedicatedGameServer", Tags: dgsTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  responses(http.StatusOK, "The DedicatedGameServer Status", dgsStatusSchema),
+				Security:   readSecurity,
+			},
+			"patch": {
+				OperationID: "patchDedicatedGameServerStatus", Summary: "Updates the Health, state and MarkedForDeletion fields of the DedicatedGameServer", Tags: dgsTags,
+				Parameters:  []*openAPIParameter{namespaceParameter, nameParameter},
+				RequestBody: jsonBody(helpers.DGSStatusUpdate{}),
+				Responses:   responses(http.StatusOK, "The updated DedicatedGameServer", dgsSchema),
+				Security:    accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsItemPath + dgsPlayersSubPath: {
+			"get": {
+				OperationID: "getDedicatedGameServerPlayers", Summary: "Returns the number of active players of the DedicatedGameServer", Tags: dgsTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  responses(http.StatusOK, "The active players", g.of(helpers.DGSPlayers{})),
+				Security:   readSecurity,
+			},
+			"put": {
+				OperationID: "setDedicatedGameServerPlayers", Summary: "Sets the number of active players of the DedicatedGameServer", Tags: dgsTags,
+				Parameters:  []*openAPIParameter{namespaceParameter, nameParameter},
+				RequestBody: jsonBody(helpers.DGSPlayers{}),
+				Responses:   responses(http.StatusOK, "The updated DedicatedGameServer", dgsSchema),
+				Security:    accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsItemPath + dgsHeartbeatSubPath: {
+			"post": {
+				OperationID: "postDedicatedGameServerHeartbeat", Summary: "Records a heartbeat of a DedicatedGameServer that has a HealthCheck", Tags: dgsTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  responses(http.StatusNoContent, "The heartbeat was recorded", nil),
+				Security:   accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsColPath: {
+			"get": {
+				OperationID: "listDedicatedGameServerCollections", Summary: "Lists the DedicatedGameServerCollections of the namespace", Tags: dgsColTags,
+				Parameters: []*openAPIParameter{namespaceParameter},
+				Responses:  responses(http.StatusOK, "The DedicatedGameServerCollections", g.of(dgsv1alpha1.DedicatedGameServerCollectionList{})),
+				Security:   accessCodeSecurity,
+			},
+			"post": {
+				OperationID: "createDedicatedGameServerCollection", Summary: "Creates a DedicatedGameServerCollection", Tags: dgsColTags,
+				Parameters:  []*openAPIParameter{namespaceParameter, dryRunParameter},
+				RequestBody: jsonBody(dgsv1alpha1.DedicatedGameServerCollection{}),
+				Responses:   responses(http.StatusCreated, "The created DedicatedGameServerCollection", dgsColSchema),
+				Security:    accessCodeSecurity,
+			},
+		},
+		v1Prefix + dgsColItemPath: {
+			"get": {
+				OperationID: "getDedicatedGameServerCollection", Summary: "Returns the DedicatedGameServerCollection", Tags: dgsColTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  responses(http.StatusOK, "The DedicatedGameServerCollection", dgsColSchema),
+				Security:   accessCodeSecurity,
+			},
+			"put": {
+				OperationID: "replaceDedicatedGameServerCollection", Summary: "Replaces the DedicatedGameServerCollection", Tags: dgsColTags,
+				Parameters:  []*openAPIParameter{namespaceParameter, nameParameter, dryRunParameter},
+				RequestBody: jsonBody(dgsv1alpha1.DedicatedGameServerCollection{}),
+				Responses:   responses(http.StatusOK, "The updated DedicatedGameServerCollection", dgsColSchema),
+				Security:    accessCodeSecurity,
+			},
+			"patch": {
+				OperationID: "patchDedicatedGameServerCollection", Summary: "Applies a strategic merge patch (as in 'kubectl patch') to the DedicatedGameServerCollection", Tags: dgsColTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter, dryRunParameter},
+				RequestBody: &openAPIRequestBody{Required: true, Content: map[string]*openAPIMediaType{
+					"application/strategic-merge-patch+json": {Schema: &openAPISchema{Type: "object"}},
+				}},
+				Responses: responses(http.StatusOK, "The updated DedicatedGameServerCollection", dgsColSchema),
+				Security:  accessCodeSecurity,
+			},
+			"delete": {
+				OperationID: "deleteDedicatedGameServerCollection", Summary: "Deletes the DedicatedGameServerCollection and its DedicatedGameServers", Tags: dgsColTags,
+				Parameters: []*openAPIParameter{namespaceParameter, nameParameter},
+				Responses:  noContent,
+				Security:   accessCodeSecurity,
+			},
+		},
+
+		"/create": {
+			"post": {
+				OperationID: "legacyCreateDedicatedGameServerCollection", Summary: "Creates a DedicatedGameServerCollection in the namespace of the object", Tags: legacyTags,
+				Parameters:  []*openAPIParameter{dryRunParameter},
+				RequestBody: jsonBody(dgsv1alpha1.DedicatedGameServerCollection{}),
+				Responses:   responses(http.StatusCreated, "The created DedicatedGameServerCollection", dgsColSchema),
+				Security:    accessCodeSecurity,
+			},
+		},
+		"/delete": {
+			"get": {
+				OperationID: "legacyDeleteDedicatedGameServerCollection", Summary: "Deletes the DedicatedGameServerCollection and its DedicatedGameServers", Tags: legacyTags,
+				Parameters: []*openAPIParameter{legacyNamespaceParameter,
+					{Name: "name", In: "query", Description: "The name of the DedicatedGameServerCollection", Required: true, Schema: stringSchema}},
+				Responses: noContent,
+				Security:  accessCodeSecurity,
+			},
+		},
+		"/running": {
+			"get": {
+				OperationID: "legacyListReadyDedicatedGameServers", Summary: "Lists the DedicatedGameServers that are ready to accept players", Tags: legacyTags,
+				Parameters: append([]*openAPIParameter{legacyNamespaceParameter}, listParameters...),
+				Responses:  runningResponses,
+				Security:   readSecurity,
+			},
+		},
+		"/setactiveplayers": {
+			"post": legacyUpdate("legacySetActivePlayers", "Sets the number of active players of the DedicatedGameServer", helpers.ServerActivePlayers{}),
+		},
+		"/setdgsstate": {
+			"post": legacyUpdate("legacySetState", "Sets the state of the DedicatedGameServer", helpers.ServerState{}),
+		},
+		"/setsdgshealth": {
+			"post": legacyUpdate("legacySetHealth", "Sets the Health of the DedicatedGameServer", helpers.ServerHealth{}),
+		},
+		"/setdgsmarkedfordeletion": {
+			"post": legacyUpdate("legacySetMarkedForDeletion", "Sets the MarkedForDeletion field of the DedicatedGameServer", helpers.ServerMarkedForDeletion{}),
+		},
+
+		"/healthz": {
+			"get": {
+				OperationID: "healthz", Summary: "Returns 200 if the API Server is running", Tags: serverTags,
+				Responses: map[string]*openAPIResponse{"200": {Description: "The API Server is running"}},
+				Security:  noSecurity,
+			},
+		},
+		"/readyz": {
+			"get": {
+				OperationID: "readyz", Summary: "Returns 200 once the informer caches of the API Server have been synced", Tags: serverTags,
+				Responses: map[string]*openAPIResponse{
+					"200": {Description: "The API Server is ready"},
+					"503": {Description: "The informer caches have not been synced yet"},
+				},
+				Security: noSecurity,
+			},
+		},
+		openAPIPath: {
+			"get": {
+				OperationID: "openAPI", Summary: "Returns this OpenAPI document", Tags: serverTags,
+				Responses: map[string]*openAPIResponse{"200": {Description: "The OpenAPI document", Content: jsonContent(&openAPISchema{Type: "object"})}},
+				Security:  noSecurity,
+			},
+		},
+		"/": {
+			"get": {
+				OperationID: "webUI", Summary: "Serves the web UI", Tags: serverTags,
+				Responses: map[string]*openAPIResponse{"200": {Description: "The web UI", Content: map[string]*openAPIMediaType{"text/html": {Schema: stringSchema}}}},
+				Security:  noSecurity,
+			},
+		},
+	}
+	return &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title:       "Azure Gaming API Server",
+			Description: "Manages the DedicatedGameServers and DedicatedGameServerCollections of the cluster",
+			Version:     "v1",
+		},
+		Paths: paths,
+		Components: openAPIComponents{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				"accessCode":       {Type: "apiKey", In: "query", Name: "code", Description: "The access code of the namespace, or the token of the DedicatedGameServer"},
+				"accessCodeHeader": {Type: "apiKey", In: "header", Name: helpers.AccessCodeHeader, Description: "The access code of the namespace, or the token of the DedicatedGameServer"},
+				"bearerToken":      {Type: "http", Scheme: "bearer", Description: "A Kubernetes bearer token that is authorized for the operation"},
+			},
+		},
+	}
+}
+
+// openAPIHandler serves the OpenAPI document
+func openAPIHandler(document *openAPIDocument) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, document)
+	}
+}
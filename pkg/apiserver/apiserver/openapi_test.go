@@ -0,0 +1,134 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// routeOperations returns the "path method" of each route of the router
+func routeOperations(t *testing.T, router *mux.Router) map[string]bool {
+	operations := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// the subrouters do not have methods, their routes are walked separately
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			operations[path+" "+strings.ToLower(method)] = true
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	return operations
+}
+
+func TestOpenAPIDocumentDescribesAllRoutes(t *testing.T) {
+	for _, listRequiresAuth := range []bool{false, true} {
+		routes := routeOperations(t, newRouter(listRequiresAuth))
+		document := newOpenAPIDocument(listRequiresAuth)
+
+		for route := range routes {
+			fields := strings.Fields(route)
+			assert.NotNil(t, document.Paths[fields[0]][fields[1]], "Route %s is missing from the OpenAPI document", route)
+		}
+		for path, operations := range document.Paths {
+			for method := range operations {
+				assert.True(t, routes[path+" "+method], "Operation %s %s of the OpenAPI document is not registered", method, path)
+			}
+		}
+
+		readSecurity := document.Paths[v1Prefix+dgsItemPath]["get"].Security
+		if listRequiresAuth {
+			assert.Equal(t, accessCodeSecurity, readSecurity)
+		} else {
+			assert.Empty(t, readSecurity)
+		}
+	}
+}
+
+func TestOpenAPIDocumentSchemas(t *testing.T) {
+	document := newOpenAPIDocument(false)
+	schemas := document.Components.Schemas
+
+	activePlayers := schemas["helpers.ServerActivePlayers"]
+	if assert.NotNil(t, activePlayers) {
+		assert.Equal(t, "object", activePlayers.Type)
+		assert.Equal(t, "integer", activePlayers.Properties["playerCount"].Type)
+		assert.Equal(t, []string{"serverName", "namespace", "playerCount"}, activePlayers.Required)
+	}
+	serverState := schemas["helpers.ServerState"]
+	if assert.NotNil(t, serverState) {
+		assert.Equal(t, []string{"Idle", "Assigned", "Running", "PostMatch"}, serverState.Properties["state"].Enum)
+	}
+	assert.Equal(t, []string{"Creating", "Healthy", "Failed"}, schemas["helpers.ServerHealth"].Properties["health"].Enum)
+	assert.Equal(t, "boolean", schemas["helpers.ServerMarkedForDeletion"].Properties["markedForDeletion"].Type)
+
+	// optional fields are not required, labels can be removed with null values
+	update := schemas["helpers.DGSUpdate"]
+	if assert.NotNil(t, update) {
+		assert.Equal(t, []string{"name"}, update.Required)
+		assert.True(t, update.Properties["labels"].AdditionalProperties.Nullable)
+	}
+
+	// the fields of TypeMeta are inlined, the other structs are referenced
+	dgs := schemas["v1alpha1.DedicatedGameServer"]
+	if assert.NotNil(t, dgs) {
+		assert.Equal(t, "string", dgs.Properties["kind"].Type)
+		assert.Equal(t, "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta", dgs.Properties["metadata"].Ref)
+		assert.Equal(t, "#/components/schemas/v1alpha1.DedicatedGameServerStatus", dgs.Properties["status"].Ref)
+		assert.Equal(t, []string{"spec", "status"}, dgs.Required)
+	}
+	assert.Equal(t, "date-time", schemas["io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"].Properties["creationTimestamp"].Format)
+	assert.Equal(t, "#/components/schemas/io.k8s.api.core.v1.PodSpec", schemas["v1alpha1.DedicatedGameServerSpec"].Properties["template"].Ref)
+
+	// all the references can be resolved
+	body, err := json.Marshal(document)
+	assert.NoError(t, err)
+	for _, part := range strings.Split(string(body), `"$ref":"#/components/schemas/`)[1:] {
+		name := part[:strings.Index(part, `"`)]
+		assert.NotNil(t, schemas[name], "Schema %s is referenced but not declared", name)
+	}
+}
+
+func TestOpenAPIEnumsMatchValidation(t *testing.T) {
+	schemas := newOpenAPIDocument(false).Components.Schemas
+
+	states := schemas["helpers.DGSUpdate"].Properties["state"].Enum
+	assert.Len(t, states, len(dgsv1alpha1.AllDGSStates))
+	for _, state := range states {
+		assert.NoError(t, validateDGSState(dgsv1alpha1.DGSState(state)))
+	}
+	assert.Error(t, validateDGSState("Unknown"))
+
+	healths := schemas["helpers.DGSUpdate"].Properties["health"].Enum
+	assert.Len(t, healths, len(dgsv1alpha1.AllDGSHealths))
+	for _, health := range healths {
+		assert.NoError(t, validateDGSHealth(dgsv1alpha1.DGSHealth(health)))
+	}
+	assert.Error(t, validateDGSHealth("Unknown"))
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	router := newRouter(false)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, openAPIVersion, document["openapi"])
+}
@@ -0,0 +1,265 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rateLimiterIdleTimeout is the period after which the token bucket of a key that has not made any requests is discarded
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+// userRateLimitKeyPrefix is the prefix of the rate limit keys of the callers that are authenticated with a bearer token,
+// so that their user names cannot collide with IP addresses or DedicatedGameServer callers
+const userRateLimitKeyPrefix = "user:"
+
+// rateLimiter keeps a token bucket per key (e.g. per caller or per DedicatedGameServer)
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+	clock clockwork.Clock
+
+	mutex     sync.Mutex
+	buckets   map[string]*rateLimiterBucket
+	lastSweep time.Time
+}
+
+type rateLimiterBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// callerRateLimiter limits the requests per caller, nil if rate limiting is disabled
+var callerRateLimiter *rateLimiter
+
+// newRateLimiter returns a rateLimiter that allows requestsPerSecond requests per key, with bursts up to burst requests
+// It returns nil if requestsPerSecond is not positive, i.e. rate limiting is disabled
+func newRateLimiter(requestsPerSecond float64, burst int, clock clockwork.Clock) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limit:     rate.Limit(requestsPerSecond),
+		burst:     burst,
+		clock:     clock,
+		buckets:   make(map[string]*rateLimiterBucket),
+		lastSweep: clock.Now(),
+	}
+}
+
+// allow takes a token from the bucket of the key
+// If the bucket is empty, it returns false and the time after which the next request will be allowed
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := rl.clock.Now()
+	reservation := rl.bucket(key, now).ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// wait takes a token from the bucket of the key, waiting until the bucket has one
+// A nil rateLimiter does not wait
+func (rl *rateLimiter) wait(key string) {
+	if rl == nil {
+		return
+	}
+
+	rl.mutex.Lock()
+	now := rl.clock.Now()
+	delay := rl.bucket(key, now).ReserveN(now, 1).DelayFrom(now)
+	rl.mutex.Unlock()
+
+	if delay > 0 {
+		rl.clock.Sleep(delay)
+	}
+}
+
+// bucket returns the token bucket of the key, it must be called with the mutex held
+func (rl *rateLimiter) bucket(key string, now time.Time) *rate.Limiter {
+	rl.sweep(now)
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = &rateLimiterBucket{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.buckets[key] = bucket
+	}
+	bucket.lastSeen = now
+	return bucket.limiter
+}
+
+// sweep discards the buckets of the keys that have been idle for rateLimiterIdleTimeout
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rateLimiterIdleTimeout {
+		return
+	}
+	for key, bucket := range rl.buckets {
+		if now.Sub(bucket.lastSeen) >= rateLimiterIdleTimeout {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// check returns a TooManyRequests error if the key has exceeded its rate limit
+// A nil rateLimiter allows all requests
+func (rl *rateLimiter) check(key string, description string) error {
+	if rl == nil {
+		return nil
+	}
+	if ok, delay := rl.allow(key); !ok {
+		log.WithField("Key", key).Warnf("Rate limit exceeded for %s", description)
+		return apierrors.NewTooManyRequests(fmt.Sprintf("Rate limit exceeded for %s, please retry later", description),
+			int(math.Ceil(delay.Seconds())))
+	}
+	return nil
+}
+
+// callerRateLimitContextKey is the context key of the callerRateLimit of a request
+type callerRateLimitContextKey struct{}
+
+// callerRateLimit is attached to each request by withRateLimit, so that a token is taken
+// from the bucket of the request's caller once the caller is known
+type callerRateLimit struct {
+	rl      *rateLimiter
+	checked bool
+	// checkedIP is true if the token of the request has been taken from the bucket of its IP address
+	checkedIP bool
+	// failures is the number of failed authentications of the request, see checkFailedAuthRateLimit
+	failures int
+}
+
+// withRateLimit wraps the handler so that each caller is limited by the rateLimiter
+// Requests without credentials are limited per IP address right away, all other requests are limited
+// per authenticated caller (see checkCallerRateLimit) or, if they are not authenticated by the route, per IP address
+func withRateLimit(rl *rateLimiter, next http.Handler) http.Handler {
+	if rl == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, err := limitCaller(rl, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitCaller returns the request with the callerRateLimit of the rateLimiter attached, see withRateLimit
+// It returns an error if the request has no credentials and its IP address has exceeded the rate limit
+func limitCaller(rl *rateLimiter, r *http.Request) (*http.Request, error) {
+	if rl == nil {
+		return r, nil
+	}
+	r = r.WithContext(context.WithValue(r.Context(), callerRateLimitContextKey{}, &callerRateLimit{rl: rl}))
+	if !hasCredentials(r) {
+		if err := checkCallerRateLimit(r, ""); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// hasCredentials returns true if the request carries a bearer token or an access code (or the token of a DedicatedGameServer)
+// The body is not parsed, so that it can still be read by the handler
+func hasCredentials(r *http.Request) bool {
+	return bearerToken(r) != "" || r.Header.Get(helpers.AccessCodeHeader) != "" || r.URL.Query().Get("code") != ""
+}
+
+// checkCallerRateLimit takes a token from the bucket of the designated caller of the request,
+// or from the bucket of the request's IP address if the caller is empty
+// Only the first call for each request takes a token, the next ones return nil
+func checkCallerRateLimit(r *http.Request, caller string) error {
+	limit, _ := r.Context().Value(callerRateLimitContextKey{}).(*callerRateLimit)
+	if limit == nil || limit.checked {
+		return nil
+	}
+	limit.checked = true
+	if caller == "" {
+		limit.checkedIP = true
+		caller = callerIP(r)
+	}
+	return limit.rl.check(caller, "caller "+caller)
+}
+
+// checkFailedAuthRateLimit takes a token from the bucket of the request's IP address for a failed authentication
+// Unlike checkCallerRateLimit, every failure takes a token, so a request that authenticates several objects (e.g. a batch update)
+// cannot try several credentials for the price of one. The first failure of a request that has already been limited
+// per IP address does not take another token
+func checkFailedAuthRateLimit(r *http.Request) error {
+	limit, _ := r.Context().Value(callerRateLimitContextKey{}).(*callerRateLimit)
+	if limit == nil {
+		return nil
+	}
+	limit.failures++
+	if limit.checkedIP && limit.failures == 1 {
+		return nil
+	}
+	limit.checked, limit.checkedIP = true, true
+	caller := callerIP(r)
+	return limit.rl.check(caller, "caller "+caller)
+}
+
+// rateLimitedByIP wraps the handler of a route that does not authenticate its callers,
+// so that the requests that carry credentials are limited per IP address as well
+func rateLimitedByIP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := checkCallerRateLimit(r, ""); err != nil {
+			writeError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// callerIP returns the IP address of the caller
+func callerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// withMaxBodySize wraps the handler so that request bodies larger than maxBytes are rejected
+// A non-positive maxBytes disables the check
+func withMaxBodySize(maxBytes int64, next http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			writeError(w, &apierrors.StatusError{ErrStatus: metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusRequestEntityTooLarge,
+				Reason:  "RequestEntityTooLarge",
+				Message: fmt.Sprintf("Request body is too large, limit is %d bytes", maxBytes),
+			}})
+			return
+		}
+		// bodies without a Content-Length fail to decode after maxBytes
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
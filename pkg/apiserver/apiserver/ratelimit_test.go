@@ -0,0 +1,158 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRateLimiter(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	rl := newRateLimiter(1, 2, clock)
+
+	assert.NoError(t, rl.check("caller1", "caller1"))
+	assert.NoError(t, rl.check("caller1", "caller1"))
+
+	err := rl.check("caller1", "caller1")
+	assert.True(t, apierrors.IsTooManyRequests(err), "Expected TooManyRequests after the burst, got %v", err)
+	seconds, ok := apierrors.SuggestsClientDelay(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, seconds)
+
+	// the buckets are per key
+	assert.NoError(t, rl.check("caller2", "caller2"))
+
+	clock.Advance(time.Second)
+	assert.NoError(t, rl.check("caller1", "caller1"))
+}
+
+func TestRateLimiterSweepsIdleKeys(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	rl := newRateLimiter(1, 1, clock)
+
+	rl.check("caller1", "caller1")
+	clock.Advance(rateLimiterIdleTimeout)
+	rl.check("caller2", "caller2")
+
+	assert.Len(t, rl.buckets, 1)
+	assert.Contains(t, rl.buckets, "caller2")
+}
+
+func TestDisabledRateLimiter(t *testing.T) {
+	rl := newRateLimiter(0, 10, clockwork.NewFakeClock())
+	assert.Nil(t, rl)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, rl.check("caller1", "caller1"))
+	}
+}
+
+func TestWithRateLimitSetsRetryAfter(t *testing.T) {
+	handler := withRateLimit(newRateLimiter(0.1, 1, clockwork.NewFakeClock()), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodGet, "/running", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+}
+
+func TestCallerRateLimitIsPerUser(t *testing.T) {
+	defer func() { tokenAuthenticator = nil }()
+
+	reviews := make([]authorizationv1.SubjectAccessReview, 0)
+	tokenAuthenticator = newFakeAuthenticator("list", &reviews)
+	handler := withRateLimit(newRateLimiter(0.1, 1, clockwork.NewFakeClock()),
+		authenticated(authzAttributes{"list", dgsResource, ""}, func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(remoteAddr string, token string) int {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/title1/dedicatedgameservers", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Authorization", "Bearer "+token)
+		r = mux.SetURLVars(r, map[string]string{"namespace": "title1"})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("10.0.0.1:1234", "valid-token"))
+	// the same user is limited from any IP address
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.2:1234", "valid-token"))
+
+	// failed authentications are limited per IP address, independently of the users that called from it
+	assert.Equal(t, http.StatusUnauthorized, serve("10.0.0.1:1234", "invalid-token"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1:1234", "invalid-token"))
+}
+
+func TestDGSUpdatesAreRateLimited(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	fake := &fakeDGSUpdates{dgss: map[string]*dgsv1alpha1.DedicatedGameServer{
+		"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}},
+	}}
+	coalescer := newDGSUpdateCoalescer(fake.update, newRateLimiter(1, 1, clock))
+
+	players1, players2 := 1, 2
+	health := dgsv1alpha1.DGSHealthy
+	_, err := coalescer.updateDGS("default", "dgs1", shared.DGSStatusFields{ActivePlayers: &players1})
+	assert.NoError(t, err)
+
+	// the next updates wait for the rate limit instead of being rejected, and are merged in the meantime
+	var wg sync.WaitGroup
+	for _, fields := range []shared.DGSStatusFields{{ActivePlayers: &players2}, {DGSHealth: &health}} {
+		wg.Add(1)
+		go func(fields shared.DGSStatusFields) {
+			defer wg.Done()
+			dgs, err := coalescer.updateDGS("default", "dgs1", fields)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, dgs.Status.ActivePlayers)
+			assert.Equal(t, dgsv1alpha1.DGSHealthy, dgs.Status.Health)
+		}(fields)
+	}
+
+	clock.BlockUntil(1)
+	for i := 0; i < 500; i++ {
+		coalescer.mutex.Lock()
+		pending := coalescer.queues["default/dgs1"].pending
+		merged := len(pending) == 1 && pending[0].fields.ActivePlayers != nil && pending[0].fields.DGSHealth != nil
+		coalescer.mutex.Unlock()
+		if merged {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	wg.Wait()
+
+	assert.Len(t, fake.updates, 2, "Updates that arrive while waiting for the rate limit should be sent together")
+}
+
+func TestWithMaxBodySize(t *testing.T) {
+	handler := withMaxBodySize(10, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodPost, "/setactiveplayers", strings.NewReader(`{"serverName":"dgs1","playerCount":5}`))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/setactiveplayers", strings.NewReader(`{}`))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -1,9 +1,10 @@
 package apiserver
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"io"
+	"net"
 	"net/http"
 
 	log "github.com/sirupsen/logrus"
@@ -13,256 +14,277 @@ import (
 	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
 	"github.com/gorilla/mux"
+	"github.com/jonboulle/clockwork"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	legacyStatusAttributes  = authzAttributes{"patch", dgsResource, "status"}
+	legacyPlayersAttributes = authzAttributes{"update", dgsResource, "players"}
 )
 
-var listPodPhaseRunningRequiresAuth = false
+// Config contains the settings of the API Server
+type Config struct {
+	// Port is the port the API Server listens at
+	Port int
+	// GRPCPort is the port of the gRPC API (see grpcapi/apiserver.proto). The gRPC API is disabled if it is not positive
+	GRPCPort int
+	// ListRunningAuth determines whether the listing methods require authentication
+	ListRunningAuth bool
+	// Namespaces contains the namespaces that the API Server serves, all namespaces are served if it is empty
+	Namespaces []string
+	// TLSCertFile and TLSKeyFile contain the TLS certificate and key. The API Server listens on plain HTTP if they are empty
+	// The files are loaded again when they change, so the certificate can be renewed without a restart
+	TLSCertFile string
+	TLSKeyFile  string
+	// CORSAllowedOrigins contains the origins that browser-based tools can call the API Server from ("*" allows all origins)
+	CORSAllowedOrigins []string
+	// RateLimit and RateBurst set the token bucket of each caller (requests per second and burst size). A non-positive RateLimit disables it
+	RateLimit float64
+	RateBurst int
+	// DGSRateLimit and DGSRateBurst set the token bucket of the updates that are sent to the Kubernetes API for each DedicatedGameServer
+	// The updates that exceed it are delayed and merged (see dgsUpdateCoalescer). A non-positive DGSRateLimit disables it
+	DGSRateLimit float64
+	DGSRateBurst int
+	// MaxBodySize is the maximum size of a request body in bytes. A non-positive MaxBodySize disables the check
+	MaxBodySize int64
+	// AuditSink receives an AuditEvent for each mutating request. Auditing is disabled if it is nil
+	AuditSink AuditSink
+	// StopCh stops the informers of the API Server
+	StopCh <-chan struct{}
+}
 
 // Run begins the WebServer
-func Run(port int, listrunningauth bool) *http.Server {
+func Run(config Config) (*http.Server, error) {
+	servedNamespaces = config.Namespaces
+	auditSink = config.AuditSink
+	callerRateLimiter = newRateLimiter(config.RateLimit, config.RateBurst, clockwork.NewRealClock())
+	statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, newRateLimiter(config.DGSRateLimit, config.DGSRateBurst, clockwork.NewRealClock()))
 
-	server := &http.Server{
-		Addr: fmt.Sprintf(":%v", port),
+	client, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		log.Errorf("Cannot get client set, bearer token authentication and informer caches will not be available: %v", err)
+	} else {
+		tokenAuthenticator = newK8sAuthenticator(client)
+		informerCache = newObjectCache(dgsClient, config.Namespaces, config.StopCh)
 	}
 
-	router := mux.NewRouter()
+	server := &http.Server{
+		Addr: fmt.Sprintf(":%v", config.Port),
+	}
 
-	router.HandleFunc("/create", createDGSColHandler).Queries("code", "{code}").Methods("POST")
-	router.HandleFunc("/delete", deleteDGSColHandler).Queries("name", "{name}", "code", "{code}").Methods("GET")
-	router.HandleFunc("/healthz", healthHandler).Methods("GET")
-	route := router.HandleFunc("/running", getPodPhaseRunningDGSHandler).Methods("GET")
-	if listrunningauth {
-		listPodPhaseRunningRequiresAuth = true
-		route.Queries("code", "{code}")
+	useTLS := config.TLSCertFile != "" || config.TLSKeyFile != ""
+	if useTLS {
+		certificates, err := newCertificateReloader(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot load TLS certificate due to %s", err.Error())
+		}
+		server.TLSConfig = &tls.Config{
+			GetCertificate: certificates.getCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
 	}
 
-	// Dedicated Game Server API methods
-	router.HandleFunc("/setactiveplayers", setActivePlayersHandler).Methods("POST")
-	router.HandleFunc("/setdgsstate", setServerStateHandler).Methods("POST")
-	router.HandleFunc("/setsdgshealth", setServerHealthHandler).Methods("POST")
-	router.HandleFunc("/setdgsmarkedfordeletion", setServerMarkedForDeletionHandler).Methods("POST")
+	if config.GRPCPort > 0 {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", config.GRPCPort))
+		if err != nil {
+			return nil, fmt.Errorf("Cannot listen for gRPC requests due to %s", err.Error())
+		}
+		grpcServer := newGRPCServer(config.ListRunningAuth, server.TLSConfig, config.MaxBodySize)
+		// the gRPC server is stopped along with the HTTP server
+		server.RegisterOnShutdown(grpcServer.GracefulStop)
+
+		log.Printf("API Server waiting for gRPC requests at port %d (TLS: %t)", config.GRPCPort, useTLS)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Errorf("Failed to serve gRPC API: %v", err)
+			}
+		}()
+	}
 
-	//this should be the last handler
-	router.PathPrefix("/").Handler(http.FileServer(http.Dir("./html/"))).Methods("GET")
+	router := newRouter(config.ListRunningAuth)
 
-	log.Printf("API Server waiting for requests at port %d", port)
+	log.Printf("API Server waiting for requests at port %d (TLS: %t)", config.Port, useTLS)
 
-	server.Handler = router
+	// CORS is handled first, so that the rejected requests carry the CORS headers and browsers can read the errors
+	server.Handler = withCORS(config.CORSAllowedOrigins, withRateLimit(callerRateLimiter, withMaxBodySize(config.MaxBodySize, router)))
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil {
+		var err error
+		if useTLS {
+			// the certificate is served via TLSConfig.GetCertificate
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			log.Errorf("Failed to listen and serve API server: %v", err)
 		}
 	}()
 
-	return server
+	return server, nil
 }
 
-func createDGSColHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("createcollection was called")
-
-	result, err := helpers.IsAPICallAuthenticated(w, r)
-	if err != nil {
-		log.Errorf("Error in authentication: %v", err)
-		w.WriteHeader(500)
-		w.Write([]byte("Error"))
-		return
-	}
+// newRouter returns the router of the API Server
+// listRunningAuth determines whether the listing methods require authentication
+func newRouter(listRunningAuth bool) *mux.Router {
+	router := mux.NewRouter()
 
-	if !result {
-		w.WriteHeader(401)
-		w.Write([]byte("Unathorized"))
-		return
+	registerV1Routes(router, listRunningAuth)
+
+	// legacy routes, kept for existing game server images
+	// the namespace is set via the 'namespace' query parameter, apart from the methods
+	// that carry it in the request body (these authenticate the request after decoding it)
+	router.HandleFunc("/create", audited(createDGSColHandler)).Methods("POST")
+	router.HandleFunc("/delete", audited(authenticated(authzAttributes{"delete", dgsColResource, ""}, deleteDGSColHandler))).Queries("name", "{name}").Methods("GET")
+	router.HandleFunc("/healthz", rateLimitedByIP(healthHandler)).Methods("GET")
+	router.HandleFunc("/readyz", rateLimitedByIP(readyzHandler)).Methods("GET")
+	if listRunningAuth {
+		router.HandleFunc("/running", authenticated(authzAttributes{"list", dgsResource, ""}, getPodPhaseRunningDGSHandler)).Methods("GET")
+	} else {
+		router.HandleFunc("/running", rateLimitedByIP(namespaced(getPodPhaseRunningDGSHandler))).Methods("GET")
 	}
 
-	var dgsCol dgsv1alpha1.DedicatedGameServerCollection
-	err = json.NewDecoder(r.Body).Decode(&dgsCol)
+	// Dedicated Game Server API methods
+	router.HandleFunc("/setactiveplayers", audited(setActivePlayersHandler)).Methods("POST")
+	router.HandleFunc("/setdgsstate", audited(setServerStateHandler)).Methods("POST")
+	router.HandleFunc("/setsdgshealth", audited(setServerHealthHandler)).Methods("POST")
+	router.HandleFunc("/setdgsmarkedfordeletion", audited(setServerMarkedForDeletionHandler)).Methods("POST")
 
-	if err != nil {
-		w.WriteHeader(400)
-		w.Write([]byte("Incorrect arguments: " + err.Error()))
-		return
-	}
+	// the OpenAPI document describes all the routes above, see newOpenAPIDocument
+	router.HandleFunc(openAPIPath, rateLimitedByIP(openAPIHandler(newOpenAPIDocument(listRunningAuth)))).Methods("GET")
 
-	colname, err := helpers.CreateDedicatedGameServerCollectionCRD(dgsCol.Name, dgsCol.Spec.Replicas, dgsCol.Spec.Template)
+	//this should be the last handler
+	router.PathPrefix("/").HandlerFunc(rateLimitedByIP(http.FileServer(http.Dir("./html/")).ServeHTTP)).Methods("GET")
 
-	if err != nil {
-		log.Printf("error encountered: %s", err.Error())
-		w.Write([]byte(fmt.Sprintf("Error %s encountered", err.Error())))
-	} else {
-		w.Write([]byte("DedicatedGameServerCollection " + colname + " was created"))
-	}
+	return router
 }
 
-func deleteDGSColHandler(w http.ResponseWriter, r *http.Request) {
+func createDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("createcollection was called")
 
-	result, err := helpers.IsAPICallAuthenticated(w, r)
+	dryRun, err := isDryRun(r)
 	if err != nil {
-		log.Errorf("Error in authentication: %v", err)
-		w.WriteHeader(500)
-		w.Write([]byte("Error"))
+		writeError(w, err)
 		return
 	}
 
-	if !result {
-		w.WriteHeader(401)
-		w.Write([]byte("Unathorized"))
-		return
-	}
-
-	name := r.FormValue("name")
-
-	_, dgsClient, err := shared.GetClientSet()
-
+	// the namespace is taken from the request body
+	dgsCol, err := decodeDGSCol(r, "")
 	if err != nil {
-		log.Errorf("Error in getting client set: %v", err)
-		w.WriteHeader(500)
-		w.Write([]byte("Error"))
+		writeError(w, err)
 		return
 	}
 
-	err = dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(shared.GameNamespace).Delete(name, nil)
-	if err != nil {
-		msg := fmt.Sprintf("Cannot delete DedicatedGameServerCollection due to %s", err.Error())
-		log.Print(msg)
-		w.Write([]byte(msg))
+	if err := authenticate(r, authzAttributes{"create", dgsColResource, ""}, dgsCol.Namespace, dgsCol.Name); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.Write([]byte(name + " was deleted"))
+	createDGSCol(w, r, dgsCol, dryRun)
 }
 
-func getPodPhaseRunningDGSHandler(w http.ResponseWriter, r *http.Request) {
-
-	if listPodPhaseRunningRequiresAuth {
-		result, err := helpers.IsAPICallAuthenticated(w, r)
-		if err != nil {
-			log.Errorf("Error in authentication: %v", err)
-			w.WriteHeader(500)
-			w.Write([]byte("Error"))
-			return
-		}
-
-		if !result {
-			w.WriteHeader(401)
-			w.Write([]byte("Unathorized"))
-			return
-		}
-	}
+func deleteDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	deleteDGSCol(w, r, requestNamespace(r), r.FormValue("name"))
+}
 
-	entities, err := shared.GetReadyDGSs()
+func getPodPhaseRunningDGSHandler(w http.ResponseWriter, r *http.Request) {
+	options, err := parseDGSListOptions(r)
 	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte("Error in marshaling to JSON: " + err.Error()))
+		writeError(w, err)
+		return
 	}
-	result, err := json.Marshal(entities)
+	namespace := requestNamespace(r)
+	entities, err := readyDGSs(namespace, options.selector)
 	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte("Error in marshaling to JSON: " + err.Error()))
+		writeError(w, err)
+		return
 	}
-	w.Write(result)
+	// the response is an array, so the continue token of the next page is returned in a header
+	entities, continueToken := options.apply(entities)
+	if continueToken != "" {
+		w.Header().Set(helpers.ContinueHeader, continueToken)
+	}
+	writeJSON(w, http.StatusOK, entities)
 }
 
 func setActivePlayersHandler(w http.ResponseWriter, r *http.Request) {
-	setDGSStatusHandler(w, r, func(r io.ReadCloser) (interface{}, error) {
-		var serverActivePlayers helpers.ServerActivePlayers
-		err := json.NewDecoder(r).Decode(&serverActivePlayers)
-
-		//a very simple validation
-		if serverActivePlayers.PlayerCount < 0 {
-			w.WriteHeader(400)
-			w.Write([]byte("Wrong value for activePlayers"))
-			return "", fmt.Errorf("Error in active players, wrong value:%d", serverActivePlayers.PlayerCount)
-		}
-		return serverActivePlayers, err
-	})
+	var serverActivePlayers helpers.ServerActivePlayers
+	if err := json.NewDecoder(r.Body).Decode(&serverActivePlayers); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+	namespace := bodyNamespace(r, serverActivePlayers.Namespace)
+	if err := authenticateDGS(r, legacyPlayersAttributes, namespace, serverActivePlayers.ServerName); err != nil {
+		writeError(w, err)
+		return
+	}
+	dgs, err := setDGSPlayers(r, namespace, serverActivePlayers.ServerName, serverActivePlayers.PlayerCount)
+	writeDGSStatusResult(w, dgs, err)
 }
 
 func setServerStateHandler(w http.ResponseWriter, r *http.Request) {
-	setDGSStatusHandler(w, r, func(r io.ReadCloser) (interface{}, error) {
-		var serverState helpers.ServerState
-		err := json.NewDecoder(r).Decode(&serverState)
-
-		//a very simple validation
-		state := dgsv1alpha1.DGSState(serverState.State)
-		if state != dgsv1alpha1.DGSIdle && state != dgsv1alpha1.DGSAssigned && state != dgsv1alpha1.DGSRunning && state != dgsv1alpha1.DGSPostMatch {
-			w.WriteHeader(400)
-			w.Write([]byte("Wrong value for serverState"))
-
-			return "", fmt.Errorf("Error in server state, wrong value:%s", state)
-		}
-		return serverState, err
-	})
+	var serverState helpers.ServerState
+	if err := json.NewDecoder(r.Body).Decode(&serverState); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+	namespace := bodyNamespace(r, serverState.Namespace)
+	if err := authenticateDGS(r, legacyStatusAttributes, namespace, serverState.ServerName); err != nil {
+		writeError(w, err)
+		return
+	}
+	dgs, err := setDGSStatus(r, namespace, serverState.ServerName, helpers.DGSStatusUpdate{State: &serverState.State})
+	writeDGSStatusResult(w, dgs, err)
 }
 
 func setServerHealthHandler(w http.ResponseWriter, r *http.Request) {
-	setDGSStatusHandler(w, r, func(r io.ReadCloser) (interface{}, error) {
-		var serverHealth helpers.ServerHealth
-		err := json.NewDecoder(r).Decode(&serverHealth)
-
-		//a very simple validation
-		health := dgsv1alpha1.DGSHealth(serverHealth.Health)
-		if health != dgsv1alpha1.DGSCreating && health != dgsv1alpha1.DGSHealthy && health != dgsv1alpha1.DGSFailed {
-			w.WriteHeader(400)
-			w.Write([]byte("Wrong value for serverHealth"))
-			return "", fmt.Errorf("Error in server status, wrong value:%s", health)
-		}
-		return serverHealth, err
-	})
-}
-
-func setServerMarkedForDeletionHandler(w http.ResponseWriter, r *http.Request) {
-	setDGSStatusHandler(w, r, func(r io.ReadCloser) (interface{}, error) {
-		var serverMarkedForDeletion helpers.ServerMarkedForDeletion
-		err := json.NewDecoder(r).Decode(&serverMarkedForDeletion)
-		return serverMarkedForDeletion, err
-	})
-}
-
-func setDGSStatusHandler(w http.ResponseWriter, r *http.Request, decode func(r io.ReadCloser) (interface{}, error)) {
-	result, err := helpers.IsAPICallAuthenticated(w, r)
-	if err != nil {
-		log.Errorf("Error in authentication: %v", err)
-		w.WriteHeader(500)
-		w.Write([]byte("Error"))
+	var serverHealth helpers.ServerHealth
+	if err := json.NewDecoder(r.Body).Decode(&serverHealth); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
 		return
 	}
-
-	if !result {
-		w.WriteHeader(401)
-		w.Write([]byte("Unathorized"))
+	namespace := bodyNamespace(r, serverHealth.Namespace)
+	if err := authenticateDGS(r, legacyStatusAttributes, namespace, serverHealth.ServerName); err != nil {
+		writeError(w, err)
 		return
 	}
+	dgs, err := setDGSStatus(r, namespace, serverHealth.ServerName, helpers.DGSStatusUpdate{Health: &serverHealth.Health})
+	writeDGSStatusResult(w, dgs, err)
+}
 
-	decoded, err := decode(r.Body)
-
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte("Error setting serverMarkedForDeletion: " + err.Error()))
-		log.Errorf("Error setting server marked for deletion:%s", err.Error())
+func setServerMarkedForDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	var serverMarkedForDeletion helpers.ServerMarkedForDeletion
+	if err := json.NewDecoder(r.Body).Decode(&serverMarkedForDeletion); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
 		return
 	}
+	namespace := bodyNamespace(r, serverMarkedForDeletion.Namespace)
+	if err := authenticateDGS(r, legacyStatusAttributes, namespace, serverMarkedForDeletion.ServerName); err != nil {
+		writeError(w, err)
+		return
+	}
+	dgs, err := setDGSStatus(r, namespace, serverMarkedForDeletion.ServerName, helpers.DGSStatusUpdate{MarkedForDeletion: &serverMarkedForDeletion.MarkedForDeletion})
+	writeDGSStatusResult(w, dgs, err)
+}
 
-	switch v := decoded.(type) {
-	case helpers.ServerMarkedForDeletion:
-		err = shared.UpdateGameServerMarkedForDeletion(v.ServerName, v.Namespace, v.MarkedForDeletion)
-	case helpers.ServerState:
-		err = shared.UpdateGameServerState(v.ServerName, v.Namespace, dgsv1alpha1.DGSState(v.State))
-	case helpers.ServerHealth:
-		err = shared.UpdateGameServerHealth(v.ServerName, v.Namespace, dgsv1alpha1.DGSHealth(v.Health))
-	case helpers.ServerActivePlayers:
-		err = shared.UpdateActivePlayers(v.ServerName, v.Namespace, v.PlayerCount)
-	default:
-		err = fmt.Errorf("Cannot recognize type %T", v)
+// bodyNamespace returns the namespace that was set in the request body or, if it is empty, the request's namespace
+func bodyNamespace(r *http.Request, namespace string) string {
+	if namespace != "" {
+		return namespace
 	}
+	return requestNamespace(r)
+}
 
+// writeDGSStatusResult writes the updated DedicatedGameServer Status or the error that occurred while updating it
+func writeDGSStatusResult(w http.ResponseWriter, dgs *dgsv1alpha1.DedicatedGameServer, err error) {
 	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte("Error setting values: " + err.Error()))
-		log.Errorf("Error setting values %v because of %s", decoded, err.Error())
+		log.Errorf("Error setting values: %s", err.Error())
+		writeError(w, err)
 		return
 	}
-
-	w.Write([]byte(fmt.Sprintf("Set values %v OK\n", decoded)))
+	writeJSON(w, http.StatusOK, dgs.Status)
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
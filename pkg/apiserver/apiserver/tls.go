@@ -0,0 +1,73 @@
+package apiserver
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// certificateReloader serves the TLS certificate of the API Server
+// and loads it again when the certificate or the key file change, so certificates can be renewed without a restart
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func newCertificateReloader(certFile string, keyFile string) (*certificateReloader, error) {
+	cr := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := cr.reload(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// reload loads the certificate if one of the files has been modified since the last load
+func (cr *certificateReloader) reload() error {
+	certInfo, err := os.Stat(cr.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(cr.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cr.mutex.RLock()
+	unchanged := cr.certificate != nil && certInfo.ModTime().Equal(cr.certModTime) && keyInfo.ModTime().Equal(cr.keyModTime)
+	cr.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+	cr.certificate = &certificate
+	cr.certModTime = certInfo.ModTime()
+	cr.keyModTime = keyInfo.ModTime()
+	log.Infof("Loaded TLS certificate from %s", cr.certFile)
+	return nil
+}
+
+// getCertificate is used as the tls.Config GetCertificate callback
+// If the renewed certificate cannot be loaded (e.g. the files are being written), the previous one is served
+func (cr *certificateReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if err := cr.reload(); err != nil {
+		log.Errorf("Cannot reload TLS certificate, serving the previous one: %v", err)
+	}
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+	return cr.certificate, nil
+}
@@ -0,0 +1,492 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+const (
+	v1Prefix          = "/api/v1"
+	dgsPath           = "/namespaces/{namespace}/dedicatedgameservers"
+	dgsItemPath       = dgsPath + "/{name}"
+	dgsColPath        = "/namespaces/{namespace}/dedicatedgameservercollections"
+	dgsColItemPath    = dgsColPath + "/{name}"
+	dgsStatusSubPath  = "/status"
+	dgsPlayersSubPath = "/players"
+	// dgsHeartbeatSubPath is not audited and not rate limited per DedicatedGameServer, since game servers with a HealthCheck call it periodically
+	dgsHeartbeatSubPath = "/heartbeat"
+
+	dryRunAll = "All"
+)
+
+// registerV1Routes registers the versioned API routes on the router
+// listRequiresAuth determines whether the DedicatedGameServer read operations require authentication
+func registerV1Routes(router *mux.Router, listRequiresAuth bool) {
+	v1 := router.PathPrefix(v1Prefix).Subrouter()
+
+	read := func(attrs authzAttributes, h http.HandlerFunc) http.HandlerFunc {
+		if listRequiresAuth {
+			return authenticated(attrs, h)
+		}
+		return rateLimitedByIP(namespaced(h))
+	}
+
+	// a DedicatedGameServer can read and update its own status using its token
+	readDGS := func(attrs authzAttributes, h http.HandlerFunc) http.HandlerFunc {
+		if listRequiresAuth {
+			return dgsAuthenticated(attrs, h)
+		}
+		return rateLimitedByIP(namespaced(h))
+	}
+
+	// DedicatedGameServers
+	v1.HandleFunc(dgsPath, read(authzAttributes{"watch", dgsResource, ""}, watchDGSHandler)).Methods(http.MethodGet).Queries("watch", "true")
+	v1.HandleFunc(dgsPath, read(authzAttributes{"list", dgsResource, ""}, listDGSHandler)).Methods(http.MethodGet)
+	v1.HandleFunc(dgsPath, audited(authenticated(authzAttributes{"create", dgsResource, ""}, postDGSHandler))).Methods(http.MethodPost)
+	v1.HandleFunc(dgsPath, audited(namespaced(batchUpdateDGSHandler))).Methods(http.MethodPatch)
+	v1.HandleFunc(dgsPath+dgsAllocateSubPath, audited(authenticated(allocateAttributes, allocateDGSHandler))).Methods(http.MethodPost)
+	v1.HandleFunc(dgsItemPath, readDGS(authzAttributes{"get", dgsResource, ""}, getDGSHandler)).Methods(http.MethodGet)
+	v1.HandleFunc(dgsItemPath, audited(authenticated(authzAttributes{"delete", dgsResource, ""}, deleteDGSHandler))).Methods(http.MethodDelete)
+	v1.HandleFunc(dgsItemPath+dgsStatusSubPath, readDGS(authzAttributes{"get", dgsResource, "status"}, getDGSStatusHandler)).Methods(http.MethodGet)
+	v1.HandleFunc(dgsItemPath+dgsStatusSubPath, audited(dgsAuthenticated(authzAttributes{"patch", dgsResource, "status"}, patchDGSStatusHandler))).Methods(http.MethodPatch)
+	v1.HandleFunc(dgsItemPath+dgsPlayersSubPath, readDGS(authzAttributes{"get", dgsResource, "players"}, getDGSPlayersHandler)).Methods(http.MethodGet)
+	v1.HandleFunc(dgsItemPath+dgsPlayersSubPath, audited(dgsAuthenticated(authzAttributes{"update", dgsResource, "players"}, putDGSPlayersHandler))).Methods(http.MethodPut)
+	v1.HandleFunc(dgsItemPath+dgsHeartbeatSubPath, dgsAuthenticated(authzAttributes{"create", dgsResource, "heartbeat"}, postDGSHeartbeatHandler)).Methods(http.MethodPost)
+
+	// DedicatedGameServerCollections
+	v1.HandleFunc(dgsColPath, authenticated(authzAttributes{"list", dgsColResource, ""}, listDGSColHandler)).Methods(http.MethodGet)
+	v1.HandleFunc(dgsColPath, audited(authenticated(authzAttributes{"create", dgsColResource, ""}, postDGSColHandler))).Methods(http.MethodPost)
+	v1.HandleFunc(dgsColItemPath, authenticated(authzAttributes{"get", dgsColResource, ""}, getDGSColHandler)).Methods(http.MethodGet)
+	v1.HandleFunc(dgsColItemPath, audited(authenticated(authzAttributes{"update", dgsColResource, ""}, putDGSColHandler))).Methods(http.MethodPut)
+	v1.HandleFunc(dgsColItemPath, audited(authenticated(authzAttributes{"patch", dgsColResource, ""}, patchDGSColHandler))).Methods(http.MethodPatch)
+	v1.HandleFunc(dgsColItemPath, audited(authenticated(authzAttributes{"delete", dgsColResource, ""}, deleteDGSColV1Handler))).Methods(http.MethodDelete)
+}
+
+func listDGSHandler(w http.ResponseWriter, r *http.Request) {
+	options, err := parseDGSListOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	namespace := mux.Vars(r)["namespace"]
+	dgss, err := listDGSs(namespace, options.selector)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dgss.Items, dgss.Continue = options.apply(dgss.Items)
+	writeJSON(w, http.StatusOK, dgss)
+}
+
+func getDGSHandler(w http.ResponseWriter, r *http.Request) {
+	dgs, err := getDGS(mux.Vars(r)["namespace"], mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgs)
+}
+
+// postDGSHandler creates a DedicatedGameServer that does not belong to a DedicatedGameServerCollection
+func postDGSHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := isDryRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var dgs dgsv1alpha1.DedicatedGameServer
+	if err := json.NewDecoder(r.Body).Decode(&dgs); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+
+	namespace := mux.Vars(r)["namespace"]
+	if dgs.Namespace != "" && dgs.Namespace != namespace {
+		writeError(w, apierrors.NewBadRequest("the namespace of the object does not match the namespace on the URL"))
+		return
+	}
+	dgs.Namespace = namespace
+
+	created, err := helpers.CreateDedicatedGameServerCRD(&dgs, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	auditValues(r, nil, created)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// deleteDGSHandler deletes a DedicatedGameServer that does not belong to a DedicatedGameServerCollection
+// DedicatedGameServers that belong to a collection should be removed by scaling in the collection
+func deleteDGSHandler(w http.ResponseWriter, r *http.Request) {
+	namespace, name := mux.Vars(r)["namespace"], mux.Vars(r)["name"]
+
+	dgs, err := getDGS(namespace, name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if owner := metav1.GetControllerOf(dgs); owner != nil {
+		writeError(w, apierrors.NewConflict(dgsv1alpha1.Resource("dedicatedgameservers"), name,
+			fmt.Errorf("DedicatedGameServer belongs to %s %s, scale in the collection instead", owner.Kind, owner.Name)))
+		return
+	}
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	auditValues(r, dgs, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getDGSStatusHandler(w http.ResponseWriter, r *http.Request) {
+	dgs, err := getDGS(mux.Vars(r)["namespace"], mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgs.Status)
+}
+
+func patchDGSStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var update helpers.DGSStatusUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+	dgs, err := setDGSStatus(r, mux.Vars(r)["namespace"], mux.Vars(r)["name"], update)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgs)
+}
+
+func getDGSPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	dgs, err := getDGS(mux.Vars(r)["namespace"], mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, helpers.DGSPlayers{PlayerCount: dgs.Status.ActivePlayers})
+}
+
+func putDGSPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	var players helpers.DGSPlayers
+	if err := json.NewDecoder(r.Body).Decode(&players); err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+	dgs, err := setDGSPlayers(r, mux.Vars(r)["namespace"], mux.Vars(r)["name"], players.PlayerCount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgs)
+}
+
+// postDGSHeartbeatHandler sets the LastHeartbeat of the DedicatedGameServer to the current time
+// The heartbeats of a DedicatedGameServer are written by the update coalescer, so there is at most one write in flight per DedicatedGameServer
+func postDGSHeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	now := metav1.Now()
+	_, err := statusUpdates.updateDGS(mux.Vars(r)["namespace"], mux.Vars(r)["name"], shared.DGSStatusFields{LastHeartbeat: &now})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func listDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	dgsCols, err := listDGSCols(mux.Vars(r)["namespace"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgsCols)
+}
+
+func getDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	dgsCol, err := getDGSCol(mux.Vars(r)["namespace"], mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dgsCol)
+}
+
+func postDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := isDryRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dgsCol, err := decodeDGSCol(r, mux.Vars(r)["namespace"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	createDGSCol(w, r, dgsCol, dryRun)
+}
+
+func deleteDGSColV1Handler(w http.ResponseWriter, r *http.Request) {
+	deleteDGSCol(w, r, mux.Vars(r)["namespace"], mux.Vars(r)["name"])
+}
+
+func putDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := isDryRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dgsCol, err := decodeDGSCol(r, mux.Vars(r)["namespace"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if dgsCol.Name != mux.Vars(r)["name"] {
+		writeError(w, apierrors.NewBadRequest("the name of the object does not match the name on the URL"))
+		return
+	}
+
+	updated, existing, err := helpers.UpdateDedicatedGameServerCollectionCRD(dgsCol, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	auditValues(r, existing, updated)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// patchDGSColHandler applies the strategic merge patch in the request body (as in 'kubectl patch') to the DedicatedGameServerCollection
+func patchDGSColHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun, err := isDryRun(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	patch, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest("Incorrect arguments: "+err.Error()))
+		return
+	}
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	existing, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(mux.Vars(r)["namespace"]).Get(mux.Vars(r)["name"], metav1.GetOptions{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dgsCol, err := applyDGSColPatch(existing, patch)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if dgsCol.Name != existing.Name || dgsCol.Namespace != existing.Namespace {
+		writeError(w, apierrors.NewBadRequest("the name and namespace of the object cannot be modified"))
+		return
+	}
+
+	updated, _, err := helpers.UpdateDedicatedGameServerCollectionCRD(dgsCol, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	auditValues(r, existing, updated)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// createDGSCol creates the DedicatedGameServerCollection and writes it as the response
+func createDGSCol(w http.ResponseWriter, r *http.Request, dgsCol *dgsv1alpha1.DedicatedGameServerCollection, dryRun bool) {
+	created, err := helpers.CreateDedicatedGameServerCollectionCRD(dgsCol, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	auditValues(r, nil, created)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// decodeDGSCol decodes the DedicatedGameServerCollection in the request body and sets its namespace
+// It returns a BadRequest error if the object belongs to a different namespace
+// If namespace is empty, the object's namespace is used (or the request's namespace, if the object does not have one)
+func decodeDGSCol(r *http.Request, namespace string) (*dgsv1alpha1.DedicatedGameServerCollection, error) {
+	var dgsCol dgsv1alpha1.DedicatedGameServerCollection
+	if err := json.NewDecoder(r.Body).Decode(&dgsCol); err != nil {
+		return nil, apierrors.NewBadRequest("Incorrect arguments: " + err.Error())
+	}
+
+	if namespace == "" {
+		namespace = dgsCol.Namespace
+		if namespace == "" {
+			namespace = requestNamespace(r)
+		}
+	}
+
+	if dgsCol.Namespace != "" && dgsCol.Namespace != namespace {
+		return nil, apierrors.NewBadRequest("the namespace of the object does not match the namespace on the URL")
+	}
+	dgsCol.Namespace = namespace
+
+	return &dgsCol, nil
+}
+
+// applyDGSColPatch returns a copy of the DedicatedGameServerCollection with the strategic merge patch applied
+func applyDGSColPatch(dgsCol *dgsv1alpha1.DedicatedGameServerCollection, patch []byte) (*dgsv1alpha1.DedicatedGameServerCollection, error) {
+	original, err := json.Marshal(dgsCol)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := strategicpatch.StrategicMergePatch(original, patch, dgsv1alpha1.DedicatedGameServerCollection{})
+	if err != nil {
+		return nil, apierrors.NewBadRequest("Incorrect patch: " + err.Error())
+	}
+
+	var result dgsv1alpha1.DedicatedGameServerCollection
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return nil, apierrors.NewBadRequest("Incorrect patch: " + err.Error())
+	}
+	return &result, nil
+}
+
+// isDryRun returns true if the request has the 'dryRun=All' query parameter, following the Kubernetes API convention
+func isDryRun(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("dryRun") {
+	case "":
+		return false, nil
+	case dryRunAll:
+		return true, nil
+	default:
+		return false, apierrors.NewBadRequest("the only supported dryRun value is " + dryRunAll)
+	}
+}
+
+// deleteDGSCol deletes the designated DedicatedGameServerCollection
+func deleteDGSCol(w http.ResponseWriter, r *http.Request, namespace string, name string) {
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// the existing object is kept for the audit log
+	existing, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	auditValues(r, existing, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setDGSStatus validates the status update and applies it to the DedicatedGameServer
+func setDGSStatus(r *http.Request, namespace string, name string, update helpers.DGSStatusUpdate) (*dgsv1alpha1.DedicatedGameServer, error) {
+	return updateDGS(r, namespace, helpers.DGSUpdate{
+		Name:              name,
+		Health:            update.Health,
+		State:             update.State,
+		MarkedForDeletion: update.MarkedForDeletion,
+	})
+}
+
+// setDGSPlayers validates the player count and sets it as the DedicatedGameServer's ActivePlayers
+func setDGSPlayers(r *http.Request, namespace string, name string, playerCount int) (*dgsv1alpha1.DedicatedGameServer, error) {
+	return updateDGS(r, namespace, helpers.DGSUpdate{
+		Name:          name,
+		ActivePlayers: &playerCount,
+	})
+}
+
+// updateDGS validates the update and applies it to the DedicatedGameServer
+// The previous and the updated Status are recorded for auditing
+func updateDGS(r *http.Request, namespace string, update helpers.DGSUpdate) (*dgsv1alpha1.DedicatedGameServer, error) {
+	fields, err := toDGSStatusFields(update)
+	if err != nil {
+		return nil, err
+	}
+	// the previous Status is taken from the informer cache, so auditing does not need an additional API call
+	var previous *dgsv1alpha1.DedicatedGameServerStatus
+	if auditEvent(r) != nil {
+		if existing, err := getDGS(namespace, update.Name); err == nil {
+			previous = existing.Status.DeepCopy()
+		}
+	}
+
+	dgs, err := statusUpdates.updateDGS(namespace, update.Name, fields)
+	if err != nil {
+		return nil, err
+	}
+	auditValues(r, previous, dgs.Status)
+	return dgs, nil
+}
+
+// toDGSStatusFields validates the update and returns the fields to be updated
+func toDGSStatusFields(update helpers.DGSUpdate) (shared.DGSStatusFields, error) {
+	fields := shared.DGSStatusFields{
+		MarkedForDeletion: update.MarkedForDeletion,
+		Labels:            update.Labels,
+	}
+	if update.Health != nil {
+		health := dgsv1alpha1.DGSHealth(*update.Health)
+		if err := validateDGSHealth(health); err != nil {
+			return fields, err
+		}
+		fields.DGSHealth = &health
+	}
+	if update.State != nil {
+		state := dgsv1alpha1.DGSState(*update.State)
+		if err := validateDGSState(state); err != nil {
+			return fields, err
+		}
+		fields.DGSState = &state
+	}
+	if update.ActivePlayers != nil {
+		if err := validatePlayerCount(*update.ActivePlayers); err != nil {
+			return fields, err
+		}
+		fields.ActivePlayers = update.ActivePlayers
+	}
+	if err := validateDGSLabels(update.Labels); err != nil {
+		return fields, err
+	}
+	return fields, nil
+}
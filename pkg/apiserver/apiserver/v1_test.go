@@ -0,0 +1,110 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDryRun(t *testing.T) {
+	dryRun, err := isDryRun(httptest.NewRequest("POST", "/api/v1/namespaces/default/dedicatedgameservercollections", nil))
+	assert.NoError(t, err)
+	assert.False(t, dryRun)
+
+	dryRun, err = isDryRun(httptest.NewRequest("POST", "/api/v1/namespaces/default/dedicatedgameservercollections?dryRun=All", nil))
+	assert.NoError(t, err)
+	assert.True(t, dryRun)
+
+	_, err = isDryRun(httptest.NewRequest("POST", "/api/v1/namespaces/default/dedicatedgameservercollections?dryRun=true", nil))
+	assert.True(t, apierrors.IsBadRequest(err))
+}
+
+func TestApplyDGSColPatchKeepsUnpatchedFields(t *testing.T) {
+	dgsCol := shared.NewDedicatedGameServerCollection("test", "default", 2, corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "game", Image: "game:1"}},
+	})
+	dgsCol.Spec.PortsToExpose = []int32{7777}
+	dgsCol.Spec.DGSFailBehavior = dgsv1alpha1.Remove
+
+	patched, err := applyDGSColPatch(dgsCol, []byte(`{"spec":{"replicas":5,"template":{"containers":[{"name":"game","image":"game:2"}]}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), patched.Spec.Replicas)
+	assert.Equal(t, "game:2", patched.Spec.Template.Containers[0].Image)
+	assert.Equal(t, []int32{7777}, patched.Spec.PortsToExpose)
+	assert.Equal(t, dgsv1alpha1.Remove, patched.Spec.DGSFailBehavior)
+
+	_, err = applyDGSColPatch(dgsCol, []byte(`{"spec":`))
+	assert.True(t, apierrors.IsBadRequest(err))
+}
+
+func TestCreateDGSColDryRunValidates(t *testing.T) {
+	dgsCol := shared.NewDedicatedGameServerCollection("test", "default", 2, corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "game", Image: "game:1"}},
+	})
+
+	// the container has no resources set
+	_, err := helpers.CreateDedicatedGameServerCollectionCRD(dgsCol, true)
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Equal(t, 422, toAPIError(err).Code)
+}
+
+func TestCreateDGSDryRunResetsHostPorts(t *testing.T) {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("64Mi"),
+	}
+	dgs := &dgsv1alpha1.DedicatedGameServer{}
+	dgs.Name = "tournament"
+	dgs.Namespace = "default"
+	dgs.Spec.PortsToExpose = []int32{7777}
+	dgs.Spec.Template.Containers = []corev1.Container{
+		{
+			Name:      "game",
+			Ports:     []corev1.ContainerPort{{ContainerPort: 7777, HostPort: 20001}},
+			Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources},
+		},
+	}
+
+	created, err := helpers.CreateDedicatedGameServerCRD(dgs, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), created.Spec.Template.Containers[0].Ports[0].HostPort)
+	assert.Equal(t, dgsv1alpha1.DGSCreating, created.Status.Health)
+	assert.Empty(t, created.OwnerReferences)
+}
+
+func TestPostDGSHeartbeat(t *testing.T) {
+	fake := &fakeDGSUpdates{dgss: map[string]*dgsv1alpha1.DedicatedGameServer{
+		"dgs1": {ObjectMeta: metav1.ObjectMeta{Name: "dgs1", Labels: map[string]string{}}},
+	}}
+	statusUpdates = newDGSUpdateCoalescer(fake.update, nil)
+	defer func() { statusUpdates = newDGSUpdateCoalescer(shared.UpdateDGSStatus, nil) }()
+
+	heartbeat := func(name string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/dedicatedgameservers/"+name+"/heartbeat", nil)
+		r = mux.SetURLVars(r, map[string]string{"namespace": "default", "name": name})
+		w := httptest.NewRecorder()
+		postDGSHeartbeatHandler(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, heartbeat("dgs1"))
+	assert.Equal(t, http.StatusNoContent, heartbeat("dgs1"))
+	assert.NotNil(t, fake.dgss["dgs1"].Status.LastHeartbeat)
+	if assert.Len(t, fake.updates, 2) {
+		assert.Nil(t, fake.updates[0].DGSHealth, "Only the LastHeartbeat should be updated")
+	}
+
+	assert.Equal(t, http.StatusNotFound, heartbeat("dgs2"))
+}
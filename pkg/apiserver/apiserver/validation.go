@@ -0,0 +1,60 @@
+package apiserver
+
+import (
+	"fmt"
+	"strings"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// reservedLabels are set by the controllers and cannot be modified via the API Server
+var reservedLabels = []string{
+	shared.LabelIsDedicatedGameServer,
+	shared.LabelDedicatedGameServerName,
+	shared.LabelDedicatedGameServerCollectionName,
+	shared.LabelOriginalDedicatedGameServerCollectionName,
+}
+
+func validateDGSState(state dgsv1alpha1.DGSState) error {
+	if !state.IsValid() {
+		return apierrors.NewBadRequest(fmt.Sprintf("Wrong value for serverState: %s", state))
+	}
+	return nil
+}
+
+func validateDGSHealth(health dgsv1alpha1.DGSHealth) error {
+	if !health.IsValid() {
+		return apierrors.NewBadRequest(fmt.Sprintf("Wrong value for serverHealth: %s", health))
+	}
+	return nil
+}
+
+func validatePlayerCount(playerCount int) error {
+	if playerCount < 0 {
+		return apierrors.NewBadRequest(fmt.Sprintf("Wrong value for activePlayers: %d", playerCount))
+	}
+	return nil
+}
+
+func validateDGSLabels(labels map[string]*string) error {
+	for key, value := range labels {
+		for _, reserved := range reservedLabels {
+			if key == reserved {
+				return apierrors.NewBadRequest(fmt.Sprintf("Label %s is reserved", key))
+			}
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return apierrors.NewBadRequest(fmt.Sprintf("Wrong label key %s: %s", key, strings.Join(errs, "; ")))
+		}
+		if value != nil {
+			if errs := validation.IsValidLabelValue(*value); len(errs) > 0 {
+				return apierrors.NewBadRequest(fmt.Sprintf("Wrong value for label %s: %s", key, strings.Join(errs, "; ")))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// watchHistorySize is the number of recent events that are kept, so that watchers can resume from a resourceVersion
+	watchHistorySize = 1000
+	// watchBufferSize is the number of events that can be queued for a watcher, a watcher that falls further behind is closed
+	watchBufferSize = 100
+	// watchKeepalivePeriod is the period of the keepalive messages, so that idle connections are not closed by proxies
+	watchKeepalivePeriod = 30 * time.Second
+)
+
+// DGSWatchEvent is a message of the DedicatedGameServer watch stream
+type DGSWatchEvent struct {
+	// Type is ADDED, MODIFIED or DELETED
+	Type   watch.EventType                  `json:"type"`
+	Object *dgsv1alpha1.DedicatedGameServer `json:"object"`
+}
+
+// dgsChange is a change of a DedicatedGameServer, as received by the informer
+type dgsChange struct {
+	eventType       watch.EventType
+	dgs             *dgsv1alpha1.DedicatedGameServer
+	previous        *dgsv1alpha1.DedicatedGameServer
+	resourceVersion uint64
+}
+
+// dgsWatchHub distributes the changes of the DedicatedGameServers that the informer receives to the watchers
+// It keeps the recent changes, so that a watcher can resume from the resourceVersion of the last event it received
+type dgsWatchHub struct {
+	mutex    sync.Mutex
+	objects  map[string]*dgsv1alpha1.DedicatedGameServer
+	history  []dgsChange
+	watchers map[*dgsWatcher]struct{}
+	// compactedVersion is the resourceVersion up to which the changes are no longer in the history
+	compactedVersion uint64
+	synced           bool
+	stopped          bool
+}
+
+// dgsWatcher receives the changes of the DedicatedGameServers of a namespace that pass its filters
+type dgsWatcher struct {
+	namespace string
+	options   *dgsListOptions
+	// events is closed when the watcher falls behind or the hub stops
+	events chan dgsWatchMessage
+}
+
+// dgsWatchMessage is a DGSWatchEvent along with the resourceVersion that the watcher can resume from
+type dgsWatchMessage struct {
+	event           DGSWatchEvent
+	resourceVersion uint64
+}
+
+func newDGSWatchHub() *dgsWatchHub {
+	return &dgsWatchHub{
+		objects:  make(map[string]*dgsv1alpha1.DedicatedGameServer),
+		watchers: make(map[*dgsWatcher]struct{}),
+	}
+}
+
+// eventHandler returns the informer event handler that feeds the hub
+func (h *dgsWatchHub) eventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if dgs, ok := obj.(*dgsv1alpha1.DedicatedGameServer); ok {
+				h.publish(watch.Added, dgs)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldDGS, ok1 := oldObj.(*dgsv1alpha1.DedicatedGameServer)
+			newDGS, ok2 := newObj.(*dgsv1alpha1.DedicatedGameServer)
+			// periodic resyncs do not modify the DedicatedGameServer
+			if ok1 && ok2 && oldDGS.ResourceVersion != newDGS.ResourceVersion {
+				h.publish(watch.Modified, newDGS)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if dgs, ok := obj.(*dgsv1alpha1.DedicatedGameServer); ok {
+				h.publish(watch.Deleted, dgs)
+			}
+		},
+	}
+}
+
+// markSynced is called once the informer cache has been synced
+// Watchers cannot resume from a resourceVersion older than the objects of the initial listing, since the previous changes are unknown
+func (h *dgsWatchHub) markSynced() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, dgs := range h.objects {
+		if version := parseResourceVersion(dgs.ResourceVersion); version > h.compactedVersion {
+			h.compactedVersion = version
+		}
+	}
+	h.history = nil
+	h.synced = true
+}
+
+// stop closes all the watchers, so that the API Server can shut down
+func (h *dgsWatchHub) stop() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for watcher := range h.watchers {
+		close(watcher.events)
+		delete(h.watchers, watcher)
+	}
+	h.stopped = true
+}
+
+func (h *dgsWatchHub) publish(eventType watch.EventType, dgs *dgsv1alpha1.DedicatedGameServer) {
+	key := dgs.Namespace + "/" + dgs.Name
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	change := dgsChange{
+		eventType:       eventType,
+		dgs:             dgs,
+		previous:        h.objects[key],
+		resourceVersion: parseResourceVersion(dgs.ResourceVersion),
+	}
+	if eventType == watch.Deleted {
+		delete(h.objects, key)
+	} else {
+		h.objects[key] = dgs
+	}
+
+	h.history = append(h.history, change)
+	if len(h.history) > watchHistorySize {
+		if compacted := h.history[0].resourceVersion; compacted > h.compactedVersion {
+			h.compactedVersion = compacted
+		}
+		h.history = h.history[1:]
+	}
+
+	maxPlayers := make(map[string]int)
+	for watcher := range h.watchers {
+		message, ok := watcher.message(change, maxPlayers)
+		if !ok {
+			continue
+		}
+		select {
+		case watcher.events <- message:
+		default:
+			log.WithField("Namespace", watcher.namespace).Warn("DedicatedGameServer watcher has fallen behind, closing it")
+			close(watcher.events)
+			delete(h.watchers, watcher)
+		}
+	}
+}
+
+// watch registers a watcher for the DedicatedGameServers of the namespace that pass the filters of the options
+// If resourceVersion is nil, the watcher starts with an ADDED event for each of the current DedicatedGameServers,
+// otherwise with the changes after resourceVersion
+// The initial events are returned, the next ones are sent to the events channel of the watcher
+func (h *dgsWatchHub) watch(namespace string, options *dgsListOptions, resourceVersion *uint64) (*dgsWatcher, []dgsWatchMessage, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.stopped {
+		return nil, nil, apierrors.NewServiceUnavailable("The API Server is shutting down")
+	}
+	if !h.synced {
+		return nil, nil, apierrors.NewServiceUnavailable("The DedicatedGameServer cache has not been synced yet, please retry later")
+	}
+
+	watcher := &dgsWatcher{namespace: namespace, options: options}
+	var initial []dgsWatchMessage
+	maxPlayers := make(map[string]int)
+
+	if resourceVersion == nil {
+		for _, dgs := range h.objects {
+			if message, ok := watcher.message(dgsChange{eventType: watch.Added, dgs: dgs, resourceVersion: parseResourceVersion(dgs.ResourceVersion)}, maxPlayers); ok {
+				initial = append(initial, message)
+			}
+		}
+		sort.Slice(initial, func(i, j int) bool { return initial[i].event.Object.Name < initial[j].event.Object.Name })
+	} else {
+		if *resourceVersion < h.compactedVersion {
+			return nil, nil, apierrors.NewGone(fmt.Sprintf("resourceVersion %d is too old, list the DedicatedGameServers and watch from the start", *resourceVersion))
+		}
+		for _, change := range h.history {
+			if change.resourceVersion <= *resourceVersion {
+				continue
+			}
+			if message, ok := watcher.message(change, maxPlayers); ok {
+				initial = append(initial, message)
+			}
+		}
+	}
+
+	watcher.events = make(chan dgsWatchMessage, watchBufferSize)
+	h.watchers[watcher] = struct{}{}
+	return watcher, initial, nil
+}
+
+// stopWatching unregisters the watcher
+func (h *dgsWatchHub) stopWatching(watcher *dgsWatcher) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, ok := h.watchers[watcher]; ok {
+		close(watcher.events)
+		delete(h.watchers, watcher)
+	}
+}
+
+// message converts the change to the event that the watcher receives, it returns false if the watcher is not interested in it
+// A DedicatedGameServer that starts passing the filters is ADDED and one that stops passing them is DELETED
+func (w *dgsWatcher) message(change dgsChange, maxPlayers map[string]int) (dgsWatchMessage, bool) {
+	if change.dgs.Namespace != w.namespace {
+		return dgsWatchMessage{}, false
+	}
+
+	matches := w.options.matches(change.dgs, maxPlayers)
+	eventType := change.eventType
+	if eventType == watch.Modified {
+		matched := change.previous != nil && w.options.matches(change.previous, maxPlayers)
+		switch {
+		case matched && !matches:
+			eventType, matches = watch.Deleted, true
+		case !matched && matches:
+			eventType = watch.Added
+		}
+	}
+	if !matches {
+		return dgsWatchMessage{}, false
+	}
+	return dgsWatchMessage{
+		event:           DGSWatchEvent{Type: eventType, Object: change.dgs},
+		resourceVersion: change.resourceVersion,
+	}, true
+}
+
+func parseResourceVersion(resourceVersion string) uint64 {
+	version, _ := strconv.ParseUint(resourceVersion, 10, 64)
+	return version
+}
+
+// watchDGSHandler streams the changes of the DedicatedGameServers of the namespace that pass the filters of the list method,
+// as Server-Sent Events or, if the client asks to upgrade the connection, as WebSocket messages
+// The stream resumes after the resourceVersion parameter or the Last-Event-ID header that EventSource sends when it reconnects
+func watchDGSHandler(w http.ResponseWriter, r *http.Request) {
+	since := r.URL.Query().Get("resourceVersion")
+	if since == "" {
+		since = r.Header.Get("Last-Event-ID")
+	}
+	hub, watcher, initial, err := watchDGSs(r, mux.Vars(r)["namespace"], since)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer hub.stopWatching(watcher)
+
+	if isWebsocketRequest(r) {
+		serveWebsocketWatch(w, r, watcher, initial)
+	} else {
+		serveSSEWatch(w, r, watcher, initial)
+	}
+}
+
+// watchDGSs registers a watcher for the DedicatedGameServers of the namespace that pass the filters of the list method in the request
+// The watcher starts after the resourceVersion since or, if it is empty, with the current DedicatedGameServers (see dgsWatchHub.watch)
+// The watcher must be unregistered from the returned hub
+func watchDGSs(r *http.Request, namespace string, since string) (*dgsWatchHub, *dgsWatcher, []dgsWatchMessage, error) {
+	options, err := parseDGSListOptions(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var resourceVersion *uint64
+	if since != "" {
+		version, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			return nil, nil, nil, apierrors.NewBadRequest(fmt.Sprintf("Wrong value for resourceVersion: %s", since))
+		}
+		resourceVersion = &version
+	}
+
+	if informerCache == nil {
+		return nil, nil, nil, apierrors.NewServiceUnavailable("Watching requires a connection to the Kubernetes cluster")
+	}
+	hub := informerCache.dgsEvents
+	watcher, initial, err := hub.watch(namespace, options, resourceVersion)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return hub, watcher, initial, nil
+}
+
+// serveSSEWatch sends the events as Server-Sent Events, with the resourceVersion as the event id
+func serveSSEWatch(w http.ResponseWriter, r *http.Request, watcher *dgsWatcher, initial []dgsWatchMessage) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, apierrors.NewInternalError(fmt.Errorf("streaming is not supported")))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// disables response buffering on nginx based proxies
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(message dgsWatchMessage) bool {
+		data, err := json.Marshal(message.event)
+		if err != nil {
+			log.Errorf("Error in marshaling watch event to JSON: %s", err.Error())
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", message.resourceVersion, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, message := range initial {
+		if !send(message) {
+			return
+		}
+	}
+
+	keepalive := time.NewTicker(watchKeepalivePeriod)
+	defer keepalive.Stop()
+	for {
+		select {
+		case message, ok := <-watcher.events:
+			// a closed stream makes EventSource reconnect with the id of the last event
+			if !ok || !send(message) {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// serveWebsocketWatch sends each event as a WebSocket text message
+// Since WebSocket messages carry no id, the events are sent along with their resourceVersion
+func serveWebsocketWatch(w http.ResponseWriter, r *http.Request, watcher *dgsWatcher, initial []dgsWatchMessage) {
+	conn, err := upgradeWebsocket(w, r)
+	if err != nil {
+		return
+	}
+
+	// the reader answers pings and notices when the client goes away or stops answering the pings
+	closed := make(chan struct{})
+	go func() {
+		conn.readMessages()
+		close(closed)
+	}()
+
+	send := func(message dgsWatchMessage) bool {
+		data, err := json.Marshal(struct {
+			DGSWatchEvent
+			ResourceVersion string `json:"resourceVersion"`
+		}{message.event, strconv.FormatUint(message.resourceVersion, 10)})
+		if err != nil {
+			log.Errorf("Error in marshaling watch event to JSON: %s", err.Error())
+			return false
+		}
+		return conn.writeText(data) == nil
+	}
+
+	for _, message := range initial {
+		if !send(message) {
+			conn.close(websocket.CloseGoingAway, "")
+			return
+		}
+	}
+
+	keepalive := time.NewTicker(websocketPingPeriod)
+	defer keepalive.Stop()
+	for {
+		select {
+		case message, ok := <-watcher.events:
+			if !ok {
+				conn.close(websocket.CloseTryAgainLater, "Watch closed, resume from the last resourceVersion")
+				return
+			}
+			if !send(message) {
+				conn.close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-keepalive.C:
+			if conn.ping() != nil {
+				conn.close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-closed:
+			conn.close(websocket.CloseGoingAway, "")
+			return
+		}
+	}
+}
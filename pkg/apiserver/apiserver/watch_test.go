@@ -0,0 +1,276 @@
+package apiserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned/fake"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func newWatchedDGS(name string, state dgsv1alpha1.DGSState, resourceVersion int) *dgsv1alpha1.DedicatedGameServer {
+	dgs := newCachedDGS("default", name, dgsv1alpha1.DGSHealthy)
+	dgs.Status.DGSState = state
+	dgs.ResourceVersion = strconv.Itoa(resourceVersion)
+	return dgs
+}
+
+func newWatchOptions(t *testing.T, query string) *dgsListOptions {
+	options, err := parseDGSListOptions(httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+	assert.NoError(t, err)
+	return options
+}
+
+// receive returns the type and the name of the next event of the watcher
+func receive(t *testing.T, watcher *dgsWatcher) (watch.EventType, string) {
+	select {
+	case message := <-watcher.events:
+		return message.event.Type, message.event.Object.Name
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a watch event")
+		return "", ""
+	}
+}
+
+func TestDGSWatchHubFilters(t *testing.T) {
+	hub := newDGSWatchHub()
+	hub.publish(watch.Added, newWatchedDGS("dgs1", dgsv1alpha1.DGSIdle, 1))
+	hub.publish(watch.Added, newWatchedDGS("dgs2", dgsv1alpha1.DGSRunning, 2))
+
+	_, _, err := hub.watch("default", newWatchOptions(t, ""), nil)
+	assert.True(t, apierrors.IsServiceUnavailable(err), "Watching should not be possible before the cache is synced")
+	hub.markSynced()
+
+	watcher, initial, err := hub.watch("default", newWatchOptions(t, "state=Idle"), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, initial, 1) {
+		assert.Equal(t, watch.Added, initial[0].event.Type)
+		assert.Equal(t, "dgs1", initial[0].event.Object.Name)
+	}
+
+	// DedicatedGameServers that start or stop passing the filters are added or deleted
+	hub.publish(watch.Modified, newWatchedDGS("dgs2", dgsv1alpha1.DGSIdle, 3))
+	eventType, name := receive(t, watcher)
+	assert.Equal(t, watch.Added, eventType)
+	assert.Equal(t, "dgs2", name)
+
+	hub.publish(watch.Modified, newWatchedDGS("dgs1", dgsv1alpha1.DGSAssigned, 4))
+	eventType, name = receive(t, watcher)
+	assert.Equal(t, watch.Deleted, eventType)
+	assert.Equal(t, "dgs1", name)
+
+	// changes of other namespaces and DedicatedGameServers that do not pass the filters are skipped
+	other := newWatchedDGS("dgs3", dgsv1alpha1.DGSIdle, 5)
+	other.Namespace = "other"
+	hub.publish(watch.Added, other)
+	hub.publish(watch.Modified, newWatchedDGS("dgs1", dgsv1alpha1.DGSRunning, 6))
+	hub.publish(watch.Deleted, newWatchedDGS("dgs2", dgsv1alpha1.DGSIdle, 7))
+	eventType, name = receive(t, watcher)
+	assert.Equal(t, watch.Deleted, eventType)
+	assert.Equal(t, "dgs2", name)
+
+	hub.stopWatching(watcher)
+	_, ok := <-watcher.events
+	assert.False(t, ok, "The events of a stopped watcher should be closed")
+}
+
+func TestDGSWatchHubResume(t *testing.T) {
+	hub := newDGSWatchHub()
+	hub.publish(watch.Added, newWatchedDGS("dgs1", dgsv1alpha1.DGSIdle, 10))
+	hub.markSynced()
+	hub.publish(watch.Added, newWatchedDGS("dgs2", dgsv1alpha1.DGSIdle, 11))
+	hub.publish(watch.Modified, newWatchedDGS("dgs1", dgsv1alpha1.DGSRunning, 12))
+
+	since := uint64(11)
+	_, initial, err := hub.watch("default", newWatchOptions(t, ""), &since)
+	assert.NoError(t, err)
+	if assert.Len(t, initial, 1) {
+		assert.Equal(t, watch.Modified, initial[0].event.Type)
+		assert.Equal(t, uint64(12), initial[0].resourceVersion)
+	}
+
+	// the changes before the initial listing are unknown
+	since = 9
+	_, _, err = hub.watch("default", newWatchOptions(t, ""), &since)
+	assert.True(t, apierrors.IsGone(err))
+
+	// the oldest changes are dropped from the history
+	for i := 0; i < watchHistorySize; i++ {
+		hub.publish(watch.Modified, newWatchedDGS("dgs2", dgsv1alpha1.DGSIdle, 13+i))
+	}
+	since = 11
+	_, _, err = hub.watch("default", newWatchOptions(t, ""), &since)
+	assert.True(t, apierrors.IsGone(err))
+	since = 12
+	_, initial, err = hub.watch("default", newWatchOptions(t, ""), &since)
+	assert.NoError(t, err)
+	assert.Len(t, initial, watchHistorySize)
+}
+
+func TestDGSWatchHubClosesSlowWatchers(t *testing.T) {
+	hub := newDGSWatchHub()
+	hub.markSynced()
+	watcher, _, err := hub.watch("default", newWatchOptions(t, ""), nil)
+	assert.NoError(t, err)
+
+	for i := 0; i <= watchBufferSize; i++ {
+		hub.publish(watch.Modified, newWatchedDGS("dgs1", dgsv1alpha1.DGSIdle, i+1))
+	}
+	received := 0
+	for range watcher.events {
+		received++
+	}
+	assert.Equal(t, watchBufferSize, received, "A watcher that falls behind should be closed")
+	assert.Empty(t, hub.watchers)
+}
+
+// startWatchServer serves the v1 API with the informer cache of a fake clientset
+func startWatchServer(t *testing.T, stopCh chan struct{}) (*httptest.Server, *fake.Clientset) {
+	client := startWatchedCache(t, stopCh)
+	router := mux.NewRouter()
+	registerV1Routes(router, false)
+	return httptest.NewServer(router), client
+}
+
+// startWatchedCache sets the informer cache to the one of a fake clientset with the DedicatedGameServer dgs1
+// and waits until the DedicatedGameServers can be watched
+func startWatchedCache(t *testing.T, stopCh chan struct{}) *fake.Clientset {
+	client := fake.NewSimpleClientset(newCachedDGS("default", "dgs1", dgsv1alpha1.DGSHealthy))
+	informerCache = newObjectCache(client, nil, stopCh)
+	assert.NoError(t, wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		informerCache.dgsEvents.mutex.Lock()
+		defer informerCache.dgsEvents.mutex.Unlock()
+		return informerCache.dgsEvents.synced, nil
+	}))
+	return client
+}
+
+func TestWatchDGSServerSentEvents(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	server, client := startWatchServer(t, stopCh)
+	defer server.Close()
+	defer func() { informerCache = nil }()
+
+	resp, err := http.Get(server.URL + "/api/v1/namespaces/default/dedicatedgameservers?watch=true")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	readEvent := func() DGSWatchEvent {
+		var event DGSWatchEvent
+		for {
+			line, err := reader.ReadString('\n')
+			if !assert.NoError(t, err) {
+				return event
+			}
+			if strings.HasPrefix(line, "data: ") {
+				assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+				return event
+			}
+		}
+	}
+
+	event := readEvent()
+	assert.Equal(t, watch.Added, event.Type)
+	assert.Equal(t, "dgs1", event.Object.Name)
+
+	_, err = client.AzuregamingV1alpha1().DedicatedGameServers("default").Create(newCachedDGS("default", "dgs2", dgsv1alpha1.DGSHealthy))
+	assert.NoError(t, err)
+	event = readEvent()
+	assert.Equal(t, watch.Added, event.Type)
+	assert.Equal(t, "dgs2", event.Object.Name)
+}
+
+func TestWatchDGSWebsocket(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	server, _ := startWatchServer(t, stopCh)
+	defer server.Close()
+	defer func() { informerCache = nil }()
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/namespaces/default/dedicatedgameservers?watch=true", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// the first message is the ADDED event of dgs1
+	messageType, payload, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, messageType)
+	var event struct {
+		DGSWatchEvent
+		ResourceVersion string `json:"resourceVersion"`
+	}
+	assert.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, watch.Added, event.Type)
+	assert.Equal(t, "dgs1", event.Object.Name)
+
+	// a close message is answered with a close message
+	assert.NoError(t, conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "Expected a close message, got %v", err)
+	waitForWatchersToStop(t)
+}
+
+func TestWatchDGSWebsocketClosesUnresponsiveClients(t *testing.T) {
+	websocketPingPeriod, websocketPongTimeout = 50*time.Millisecond, 200*time.Millisecond
+	defer func() {
+		websocketPingPeriod, websocketPongTimeout = watchKeepalivePeriod, watchKeepalivePeriod+websocketWriteTimeout
+	}()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	server, _ := startWatchServer(t, stopCh)
+	defer server.Close()
+	defer func() { informerCache = nil }()
+
+	// the client does not read, so it does not answer the pings
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/namespaces/default/dedicatedgameservers?watch=true", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	time.Sleep(2 * websocketPongTimeout)
+
+	// the pending messages are read (the pings are answered too late), then the closed connection fails
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	netErr, ok := err.(net.Error)
+	assert.False(t, ok && netErr.Timeout(), "The server should close the connection, got %v", err)
+	waitForWatchersToStop(t)
+}
+
+// waitForWatchersToStop waits until the watch handlers have returned, i.e. they have stopped their watchers
+func waitForWatchersToStop(t *testing.T) {
+	assert.NoError(t, wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		informerCache.dgsEvents.mutex.Lock()
+		defer informerCache.dgsEvents.mutex.Unlock()
+		return len(informerCache.dgsEvents.watchers) == 0, nil
+	}))
+}
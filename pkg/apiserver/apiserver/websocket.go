@@ -0,0 +1,93 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// websocketMaxClientMessage is the maximum size of the messages of the client, which are discarded anyway
+const websocketMaxClientMessage = 64 * 1024
+
+var (
+	// websocketPingPeriod is the period of the pings that keep the connection alive and detect clients that went away
+	websocketPingPeriod = watchKeepalivePeriod
+	// websocketWriteTimeout is the time allowed to write a message to the client, so a stalled client cannot block the watch
+	websocketWriteTimeout = 10 * time.Second
+	// websocketPongTimeout is the time allowed to receive the pong of a ping (or any other message) from the client
+	websocketPongTimeout = websocketPingPeriod + websocketWriteTimeout
+)
+
+// websocketUpgrader completes the WebSocket handshake of the watch requests
+var websocketUpgrader = websocket.Upgrader{
+	// the requests are authenticated via the access code or a bearer token instead of cookies,
+	// so a page of another origin cannot use the credentials of the browser
+	CheckOrigin: func(r *http.Request) bool { return true },
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		if status >= http.StatusInternalServerError {
+			// e.g. HTTP/2 connections, which cannot be hijacked
+			writeError(w, apierrors.NewInternalError(fmt.Errorf("WebSocket is not supported on this connection, use Server-Sent Events instead: %v", reason)))
+			return
+		}
+		writeError(w, apierrors.NewBadRequest("Invalid WebSocket handshake: "+reason.Error()))
+	},
+}
+
+// isWebsocketRequest returns true if the client asks to upgrade the connection to WebSocket
+func isWebsocketRequest(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r)
+}
+
+// websocketConn is a server-side WebSocket connection that sends messages to the client
+// Each write has a deadline, so a client that stops reading does not block the sender forever
+type websocketConn struct {
+	conn *websocket.Conn
+}
+
+// upgradeWebsocket completes the WebSocket handshake and takes over the connection of the request
+// If it fails, the error has already been written to the ResponseWriter
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	conn, err := websocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &websocketConn{conn: conn}, nil
+}
+
+// writeText sends a text message
+func (c *websocketConn) writeText(data []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// ping sends a ping, which the client answers with a pong
+func (c *websocketConn) ping() error {
+	return c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(websocketWriteTimeout))
+}
+
+// close sends a close message and closes the connection
+func (c *websocketConn) close(code int, reason string) error {
+	err := c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(websocketWriteTimeout))
+	c.conn.Close()
+	return err
+}
+
+// readMessages reads the messages of the client until the connection fails or is closed,
+// which answers pings and close messages. The messages themselves are discarded
+// The connection fails if the client does not answer the pings within websocketPongTimeout
+func (c *websocketConn) readMessages() error {
+	c.conn.SetReadLimit(websocketMaxClientMessage)
+	c.conn.SetReadDeadline(time.Now().Add(websocketPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(websocketPongTimeout))
+	})
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,1484 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: apiserver.proto
+
+package grpcapi // import "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/grpcapi"
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Health int32
+
+const (
+	Health_HEALTH_UNSPECIFIED Health = 0
+	Health_CREATING           Health = 1
+	Health_HEALTHY            Health = 2
+	Health_FAILED             Health = 3
+)
+
+var Health_name = map[int32]string{
+	0: "HEALTH_UNSPECIFIED",
+	1: "CREATING",
+	2: "HEALTHY",
+	3: "FAILED",
+}
+var Health_value = map[string]int32{
+	"HEALTH_UNSPECIFIED": 0,
+	"CREATING":           1,
+	"HEALTHY":            2,
+	"FAILED":             3,
+}
+
+func (x Health) String() string {
+	return proto.EnumName(Health_name, int32(x))
+}
+func (Health) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{0}
+}
+
+type State int32
+
+const (
+	State_STATE_UNSPECIFIED State = 0
+	State_IDLE              State = 1
+	State_ASSIGNED          State = 2
+	State_RUNNING           State = 3
+	State_POST_MATCH        State = 4
+)
+
+var State_name = map[int32]string{
+	0: "STATE_UNSPECIFIED",
+	1: "IDLE",
+	2: "ASSIGNED",
+	3: "RUNNING",
+	4: "POST_MATCH",
+}
+var State_value = map[string]int32{
+	"STATE_UNSPECIFIED": 0,
+	"IDLE":              1,
+	"ASSIGNED":          2,
+	"RUNNING":           3,
+	"POST_MATCH":        4,
+}
+
+func (x State) String() string {
+	return proto.EnumName(State_name, int32(x))
+}
+func (State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{1}
+}
+
+type WatchEvent_Type int32
+
+const (
+	WatchEvent_TYPE_UNSPECIFIED WatchEvent_Type = 0
+	WatchEvent_ADDED            WatchEvent_Type = 1
+	WatchEvent_MODIFIED         WatchEvent_Type = 2
+	WatchEvent_DELETED          WatchEvent_Type = 3
+)
+
+var WatchEvent_Type_name = map[int32]string{
+	0: "TYPE_UNSPECIFIED",
+	1: "ADDED",
+	2: "MODIFIED",
+	3: "DELETED",
+}
+var WatchEvent_Type_value = map[string]int32{
+	"TYPE_UNSPECIFIED": 0,
+	"ADDED":            1,
+	"MODIFIED":         2,
+	"DELETED":          3,
+}
+
+func (x WatchEvent_Type) String() string {
+	return proto.EnumName(WatchEvent_Type_name, int32(x))
+}
+func (WatchEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{14, 0}
+}
+
+type DedicatedGameServer struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// collection is the name of the DedicatedGameServerCollection, empty if the DedicatedGameServer does not belong to one
+	Collection           string                     `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	Labels               map[string]string          `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ResourceVersion      string                     `protobuf:"bytes,5,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	CreationTimestamp    *timestamp.Timestamp       `protobuf:"bytes,6,opt,name=creation_timestamp,json=creationTimestamp,proto3" json:"creation_timestamp,omitempty"`
+	Status               *DedicatedGameServerStatus `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *DedicatedGameServer) Reset()         { *m = DedicatedGameServer{} }
+func (m *DedicatedGameServer) String() string { return proto.CompactTextString(m) }
+func (*DedicatedGameServer) ProtoMessage()    {}
+func (*DedicatedGameServer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{0}
+}
+func (m *DedicatedGameServer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DedicatedGameServer.Unmarshal(m, b)
+}
+func (m *DedicatedGameServer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DedicatedGameServer.Marshal(b, m, deterministic)
+}
+func (dst *DedicatedGameServer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DedicatedGameServer.Merge(dst, src)
+}
+func (m *DedicatedGameServer) XXX_Size() int {
+	return xxx_messageInfo_DedicatedGameServer.Size(m)
+}
+func (m *DedicatedGameServer) XXX_DiscardUnknown() {
+	xxx_messageInfo_DedicatedGameServer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DedicatedGameServer proto.InternalMessageInfo
+
+func (m *DedicatedGameServer) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *DedicatedGameServer) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DedicatedGameServer) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *DedicatedGameServer) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *DedicatedGameServer) GetResourceVersion() string {
+	if m != nil {
+		return m.ResourceVersion
+	}
+	return ""
+}
+
+func (m *DedicatedGameServer) GetCreationTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreationTimestamp
+	}
+	return nil
+}
+
+func (m *DedicatedGameServer) GetStatus() *DedicatedGameServerStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type DedicatedGameServerStatus struct {
+	PodPhase             string     `protobuf:"bytes,1,opt,name=pod_phase,json=podPhase,proto3" json:"pod_phase,omitempty"`
+	Health               Health     `protobuf:"varint,2,opt,name=health,proto3,enum=azuregaming.apiserver.v1.Health" json:"health,omitempty"`
+	State                State      `protobuf:"varint,3,opt,name=state,proto3,enum=azuregaming.apiserver.v1.State" json:"state,omitempty"`
+	MarkedForDeletion    bool       `protobuf:"varint,4,opt,name=marked_for_deletion,json=markedForDeletion,proto3" json:"marked_for_deletion,omitempty"`
+	PublicIp             string     `protobuf:"bytes,5,opt,name=public_ip,json=publicIp,proto3" json:"public_ip,omitempty"`
+	PublicIpv6           string     `protobuf:"bytes,6,opt,name=public_ipv6,json=publicIpv6,proto3" json:"public_ipv6,omitempty"`
+	NodeName             string     `protobuf:"bytes,7,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	ActivePlayers        int32      `protobuf:"varint,8,opt,name=active_players,json=activePlayers,proto3" json:"active_players,omitempty"`
+	Addresses            []*Address `protobuf:"bytes,9,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Ports                []*Port    `protobuf:"bytes,10,rep,name=ports,proto3" json:"ports,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *DedicatedGameServerStatus) Reset()         { *m = DedicatedGameServerStatus{} }
+func (m *DedicatedGameServerStatus) String() string { return proto.CompactTextString(m) }
+func (*DedicatedGameServerStatus) ProtoMessage()    {}
+func (*DedicatedGameServerStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{1}
+}
+func (m *DedicatedGameServerStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DedicatedGameServerStatus.Unmarshal(m, b)
+}
+func (m *DedicatedGameServerStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DedicatedGameServerStatus.Marshal(b, m, deterministic)
+}
+func (dst *DedicatedGameServerStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DedicatedGameServerStatus.Merge(dst, src)
+}
+func (m *DedicatedGameServerStatus) XXX_Size() int {
+	return xxx_messageInfo_DedicatedGameServerStatus.Size(m)
+}
+func (m *DedicatedGameServerStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_DedicatedGameServerStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DedicatedGameServerStatus proto.InternalMessageInfo
+
+func (m *DedicatedGameServerStatus) GetPodPhase() string {
+	if m != nil {
+		return m.PodPhase
+	}
+	return ""
+}
+
+func (m *DedicatedGameServerStatus) GetHealth() Health {
+	if m != nil {
+		return m.Health
+	}
+	return Health_HEALTH_UNSPECIFIED
+}
+
+func (m *DedicatedGameServerStatus) GetState() State {
+	if m != nil {
+		return m.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+func (m *DedicatedGameServerStatus) GetMarkedForDeletion() bool {
+	if m != nil {
+		return m.MarkedForDeletion
+	}
+	return false
+}
+
+func (m *DedicatedGameServerStatus) GetPublicIp() string {
+	if m != nil {
+		return m.PublicIp
+	}
+	return ""
+}
+
+func (m *DedicatedGameServerStatus) GetPublicIpv6() string {
+	if m != nil {
+		return m.PublicIpv6
+	}
+	return ""
+}
+
+func (m *DedicatedGameServerStatus) GetNodeName() string {
+	if m != nil {
+		return m.NodeName
+	}
+	return ""
+}
+
+func (m *DedicatedGameServerStatus) GetActivePlayers() int32 {
+	if m != nil {
+		return m.ActivePlayers
+	}
+	return 0
+}
+
+func (m *DedicatedGameServerStatus) GetAddresses() []*Address {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *DedicatedGameServerStatus) GetPorts() []*Port {
+	if m != nil {
+		return m.Ports
+	}
+	return nil
+}
+
+type Address struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	IpFamily             string   `protobuf:"bytes,3,opt,name=ip_family,json=ipFamily,proto3" json:"ip_family,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Address) Reset()         { *m = Address{} }
+func (m *Address) String() string { return proto.CompactTextString(m) }
+func (*Address) ProtoMessage()    {}
+func (*Address) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{2}
+}
+func (m *Address) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Address.Unmarshal(m, b)
+}
+func (m *Address) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Address.Marshal(b, m, deterministic)
+}
+func (dst *Address) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Address.Merge(dst, src)
+}
+func (m *Address) XXX_Size() int {
+	return xxx_messageInfo_Address.Size(m)
+}
+func (m *Address) XXX_DiscardUnknown() {
+	xxx_messageInfo_Address.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Address proto.InternalMessageInfo
+
+func (m *Address) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Address) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Address) GetIpFamily() string {
+	if m != nil {
+		return m.IpFamily
+	}
+	return ""
+}
+
+type Port struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Protocol             string   `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	ContainerPort        int32    `protobuf:"varint,3,opt,name=container_port,json=containerPort,proto3" json:"container_port,omitempty"`
+	HostPort             int32    `protobuf:"varint,4,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
+	ExposureMode         string   `protobuf:"bytes,5,opt,name=exposure_mode,json=exposureMode,proto3" json:"exposure_mode,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Port) Reset()         { *m = Port{} }
+func (m *Port) String() string { return proto.CompactTextString(m) }
+func (*Port) ProtoMessage()    {}
+func (*Port) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{3}
+}
+func (m *Port) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Port.Unmarshal(m, b)
+}
+func (m *Port) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Port.Marshal(b, m, deterministic)
+}
+func (dst *Port) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Port.Merge(dst, src)
+}
+func (m *Port) XXX_Size() int {
+	return xxx_messageInfo_Port.Size(m)
+}
+func (m *Port) XXX_DiscardUnknown() {
+	xxx_messageInfo_Port.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Port proto.InternalMessageInfo
+
+func (m *Port) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Port) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *Port) GetContainerPort() int32 {
+	if m != nil {
+		return m.ContainerPort
+	}
+	return 0
+}
+
+func (m *Port) GetHostPort() int32 {
+	if m != nil {
+		return m.HostPort
+	}
+	return 0
+}
+
+func (m *Port) GetExposureMode() string {
+	if m != nil {
+		return m.ExposureMode
+	}
+	return ""
+}
+
+type SetHealthRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Health               Health   `protobuf:"varint,3,opt,name=health,proto3,enum=azuregaming.apiserver.v1.Health" json:"health,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetHealthRequest) Reset()         { *m = SetHealthRequest{} }
+func (m *SetHealthRequest) String() string { return proto.CompactTextString(m) }
+func (*SetHealthRequest) ProtoMessage()    {}
+func (*SetHealthRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{4}
+}
+func (m *SetHealthRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetHealthRequest.Unmarshal(m, b)
+}
+func (m *SetHealthRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetHealthRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetHealthRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetHealthRequest.Merge(dst, src)
+}
+func (m *SetHealthRequest) XXX_Size() int {
+	return xxx_messageInfo_SetHealthRequest.Size(m)
+}
+func (m *SetHealthRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetHealthRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetHealthRequest proto.InternalMessageInfo
+
+func (m *SetHealthRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *SetHealthRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SetHealthRequest) GetHealth() Health {
+	if m != nil {
+		return m.Health
+	}
+	return Health_HEALTH_UNSPECIFIED
+}
+
+type SetStateRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	State                State    `protobuf:"varint,3,opt,name=state,proto3,enum=azuregaming.apiserver.v1.State" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetStateRequest) Reset()         { *m = SetStateRequest{} }
+func (m *SetStateRequest) String() string { return proto.CompactTextString(m) }
+func (*SetStateRequest) ProtoMessage()    {}
+func (*SetStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{5}
+}
+func (m *SetStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetStateRequest.Unmarshal(m, b)
+}
+func (m *SetStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetStateRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetStateRequest.Merge(dst, src)
+}
+func (m *SetStateRequest) XXX_Size() int {
+	return xxx_messageInfo_SetStateRequest.Size(m)
+}
+func (m *SetStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetStateRequest proto.InternalMessageInfo
+
+func (m *SetStateRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *SetStateRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SetStateRequest) GetState() State {
+	if m != nil {
+		return m.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+type SetPlayersRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	PlayerCount          int32    `protobuf:"varint,3,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPlayersRequest) Reset()         { *m = SetPlayersRequest{} }
+func (m *SetPlayersRequest) String() string { return proto.CompactTextString(m) }
+func (*SetPlayersRequest) ProtoMessage()    {}
+func (*SetPlayersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{6}
+}
+func (m *SetPlayersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPlayersRequest.Unmarshal(m, b)
+}
+func (m *SetPlayersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPlayersRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetPlayersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPlayersRequest.Merge(dst, src)
+}
+func (m *SetPlayersRequest) XXX_Size() int {
+	return xxx_messageInfo_SetPlayersRequest.Size(m)
+}
+func (m *SetPlayersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPlayersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPlayersRequest proto.InternalMessageInfo
+
+func (m *SetPlayersRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *SetPlayersRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SetPlayersRequest) GetPlayerCount() int32 {
+	if m != nil {
+		return m.PlayerCount
+	}
+	return 0
+}
+
+type MarkForDeletionRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	MarkedForDeletion    bool     `protobuf:"varint,3,opt,name=marked_for_deletion,json=markedForDeletion,proto3" json:"marked_for_deletion,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MarkForDeletionRequest) Reset()         { *m = MarkForDeletionRequest{} }
+func (m *MarkForDeletionRequest) String() string { return proto.CompactTextString(m) }
+func (*MarkForDeletionRequest) ProtoMessage()    {}
+func (*MarkForDeletionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{7}
+}
+func (m *MarkForDeletionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MarkForDeletionRequest.Unmarshal(m, b)
+}
+func (m *MarkForDeletionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MarkForDeletionRequest.Marshal(b, m, deterministic)
+}
+func (dst *MarkForDeletionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MarkForDeletionRequest.Merge(dst, src)
+}
+func (m *MarkForDeletionRequest) XXX_Size() int {
+	return xxx_messageInfo_MarkForDeletionRequest.Size(m)
+}
+func (m *MarkForDeletionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MarkForDeletionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MarkForDeletionRequest proto.InternalMessageInfo
+
+func (m *MarkForDeletionRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *MarkForDeletionRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *MarkForDeletionRequest) GetMarkedForDeletion() bool {
+	if m != nil {
+		return m.MarkedForDeletion
+	}
+	return false
+}
+
+// Filters are the filters of the listing methods of the JSON API
+type Filters struct {
+	LabelSelector string `protobuf:"bytes,1,opt,name=label_selector,json=labelSelector,proto3" json:"label_selector,omitempty"`
+	Collection    string `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	State         State  `protobuf:"varint,3,opt,name=state,proto3,enum=azuregaming.apiserver.v1.State" json:"state,omitempty"`
+	Node          string `protobuf:"bytes,4,opt,name=node,proto3" json:"node,omitempty"`
+	MinFreeSlots  int32  `protobuf:"varint,5,opt,name=min_free_slots,json=minFreeSlots,proto3" json:"min_free_slots,omitempty"`
+	// ready_only returns only the DedicatedGameServers that are ready to accept players, as the /running method
+	// It is not supported by Watch
+	ReadyOnly            bool     `protobuf:"varint,6,opt,name=ready_only,json=readyOnly,proto3" json:"ready_only,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Filters) Reset()         { *m = Filters{} }
+func (m *Filters) String() string { return proto.CompactTextString(m) }
+func (*Filters) ProtoMessage()    {}
+func (*Filters) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{8}
+}
+func (m *Filters) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Filters.Unmarshal(m, b)
+}
+func (m *Filters) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Filters.Marshal(b, m, deterministic)
+}
+func (dst *Filters) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Filters.Merge(dst, src)
+}
+func (m *Filters) XXX_Size() int {
+	return xxx_messageInfo_Filters.Size(m)
+}
+func (m *Filters) XXX_DiscardUnknown() {
+	xxx_messageInfo_Filters.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Filters proto.InternalMessageInfo
+
+func (m *Filters) GetLabelSelector() string {
+	if m != nil {
+		return m.LabelSelector
+	}
+	return ""
+}
+
+func (m *Filters) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *Filters) GetState() State {
+	if m != nil {
+		return m.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+func (m *Filters) GetNode() string {
+	if m != nil {
+		return m.Node
+	}
+	return ""
+}
+
+func (m *Filters) GetMinFreeSlots() int32 {
+	if m != nil {
+		return m.MinFreeSlots
+	}
+	return 0
+}
+
+func (m *Filters) GetReadyOnly() bool {
+	if m != nil {
+		return m.ReadyOnly
+	}
+	return false
+}
+
+type ListRequest struct {
+	Namespace string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Filters   *Filters `protobuf:"bytes,2,opt,name=filters,proto3" json:"filters,omitempty"`
+	// sort_by is name, activePlayers or creationTimestamp, a '-' prefix sorts in descending order
+	SortBy               string   `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	Limit                int32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Continue             string   `protobuf:"bytes,5,opt,name=continue,proto3" json:"continue,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{9}
+}
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(dst, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+func (m *ListRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *ListRequest) GetFilters() *Filters {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *ListRequest) GetSortBy() string {
+	if m != nil {
+		return m.SortBy
+	}
+	return ""
+}
+
+func (m *ListRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListRequest) GetContinue() string {
+	if m != nil {
+		return m.Continue
+	}
+	return ""
+}
+
+type ListResponse struct {
+	Items []*DedicatedGameServer `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// continue is the token of the next page, empty on the last page
+	Continue             string   `protobuf:"bytes,2,opt,name=continue,proto3" json:"continue,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListResponse) Reset()         { *m = ListResponse{} }
+func (m *ListResponse) String() string { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()    {}
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{10}
+}
+func (m *ListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListResponse.Unmarshal(m, b)
+}
+func (m *ListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListResponse.Merge(dst, src)
+}
+func (m *ListResponse) XXX_Size() int {
+	return xxx_messageInfo_ListResponse.Size(m)
+}
+func (m *ListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListResponse proto.InternalMessageInfo
+
+func (m *ListResponse) GetItems() []*DedicatedGameServer {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *ListResponse) GetContinue() string {
+	if m != nil {
+		return m.Continue
+	}
+	return ""
+}
+
+type AllocateRequest struct {
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// filters select the DedicatedGameServers that can be allocated, ready_only is implied and state can only be IDLE
+	Filters *Filters `protobuf:"bytes,2,opt,name=filters,proto3" json:"filters,omitempty"`
+	// sort_by determines which of the matching DedicatedGameServers is allocated first, as in ListRequest
+	SortBy               string   `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AllocateRequest) Reset()         { *m = AllocateRequest{} }
+func (m *AllocateRequest) String() string { return proto.CompactTextString(m) }
+func (*AllocateRequest) ProtoMessage()    {}
+func (*AllocateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{11}
+}
+func (m *AllocateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AllocateRequest.Unmarshal(m, b)
+}
+func (m *AllocateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AllocateRequest.Marshal(b, m, deterministic)
+}
+func (dst *AllocateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AllocateRequest.Merge(dst, src)
+}
+func (m *AllocateRequest) XXX_Size() int {
+	return xxx_messageInfo_AllocateRequest.Size(m)
+}
+func (m *AllocateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AllocateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AllocateRequest proto.InternalMessageInfo
+
+func (m *AllocateRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *AllocateRequest) GetFilters() *Filters {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *AllocateRequest) GetSortBy() string {
+	if m != nil {
+		return m.SortBy
+	}
+	return ""
+}
+
+type AllocateResponse struct {
+	// object is the allocated DedicatedGameServer, its status contains the ports that the players connect to
+	Object               *DedicatedGameServer `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AllocateResponse) Reset()         { *m = AllocateResponse{} }
+func (m *AllocateResponse) String() string { return proto.CompactTextString(m) }
+func (*AllocateResponse) ProtoMessage()    {}
+func (*AllocateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{12}
+}
+func (m *AllocateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AllocateResponse.Unmarshal(m, b)
+}
+func (m *AllocateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AllocateResponse.Marshal(b, m, deterministic)
+}
+func (dst *AllocateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AllocateResponse.Merge(dst, src)
+}
+func (m *AllocateResponse) XXX_Size() int {
+	return xxx_messageInfo_AllocateResponse.Size(m)
+}
+func (m *AllocateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AllocateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AllocateResponse proto.InternalMessageInfo
+
+func (m *AllocateResponse) GetObject() *DedicatedGameServer {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	Namespace string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Filters   *Filters `protobuf:"bytes,2,opt,name=filters,proto3" json:"filters,omitempty"`
+	// resource_version resumes the watch after this change, it fails with OUT_OF_RANGE if the change is too old
+	ResourceVersion      string   `protobuf:"bytes,3,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{13}
+}
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (dst *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(dst, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *WatchRequest) GetFilters() *Filters {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *WatchRequest) GetResourceVersion() string {
+	if m != nil {
+		return m.ResourceVersion
+	}
+	return ""
+}
+
+type WatchEvent struct {
+	Type                 WatchEvent_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=azuregaming.apiserver.v1.WatchEvent_Type" json:"type,omitempty"`
+	Object               *DedicatedGameServer `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+	ResourceVersion      string               `protobuf:"bytes,3,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *WatchEvent) Reset()         { *m = WatchEvent{} }
+func (m *WatchEvent) String() string { return proto.CompactTextString(m) }
+func (*WatchEvent) ProtoMessage()    {}
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_apiserver_b47d658c6e3dc392, []int{14}
+}
+func (m *WatchEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchEvent.Unmarshal(m, b)
+}
+func (m *WatchEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchEvent.Marshal(b, m, deterministic)
+}
+func (dst *WatchEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchEvent.Merge(dst, src)
+}
+func (m *WatchEvent) XXX_Size() int {
+	return xxx_messageInfo_WatchEvent.Size(m)
+}
+func (m *WatchEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchEvent proto.InternalMessageInfo
+
+func (m *WatchEvent) GetType() WatchEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return WatchEvent_TYPE_UNSPECIFIED
+}
+
+func (m *WatchEvent) GetObject() *DedicatedGameServer {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+func (m *WatchEvent) GetResourceVersion() string {
+	if m != nil {
+		return m.ResourceVersion
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*DedicatedGameServer)(nil), "azuregaming.apiserver.v1.DedicatedGameServer")
+	proto.RegisterMapType((map[string]string)(nil), "azuregaming.apiserver.v1.DedicatedGameServer.LabelsEntry")
+	proto.RegisterType((*DedicatedGameServerStatus)(nil), "azuregaming.apiserver.v1.DedicatedGameServerStatus")
+	proto.RegisterType((*Address)(nil), "azuregaming.apiserver.v1.Address")
+	proto.RegisterType((*Port)(nil), "azuregaming.apiserver.v1.Port")
+	proto.RegisterType((*SetHealthRequest)(nil), "azuregaming.apiserver.v1.SetHealthRequest")
+	proto.RegisterType((*SetStateRequest)(nil), "azuregaming.apiserver.v1.SetStateRequest")
+	proto.RegisterType((*SetPlayersRequest)(nil), "azuregaming.apiserver.v1.SetPlayersRequest")
+	proto.RegisterType((*MarkForDeletionRequest)(nil), "azuregaming.apiserver.v1.MarkForDeletionRequest")
+	proto.RegisterType((*Filters)(nil), "azuregaming.apiserver.v1.Filters")
+	proto.RegisterType((*ListRequest)(nil), "azuregaming.apiserver.v1.ListRequest")
+	proto.RegisterType((*ListResponse)(nil), "azuregaming.apiserver.v1.ListResponse")
+	proto.RegisterType((*AllocateRequest)(nil), "azuregaming.apiserver.v1.AllocateRequest")
+	proto.RegisterType((*AllocateResponse)(nil), "azuregaming.apiserver.v1.AllocateResponse")
+	proto.RegisterType((*WatchRequest)(nil), "azuregaming.apiserver.v1.WatchRequest")
+	proto.RegisterType((*WatchEvent)(nil), "azuregaming.apiserver.v1.WatchEvent")
+	proto.RegisterEnum("azuregaming.apiserver.v1.Health", Health_name, Health_value)
+	proto.RegisterEnum("azuregaming.apiserver.v1.State", State_name, State_value)
+	proto.RegisterEnum("azuregaming.apiserver.v1.WatchEvent_Type", WatchEvent_Type_name, WatchEvent_Type_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DedicatedGameServersClient is the client API for DedicatedGameServers service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DedicatedGameServersClient interface {
+	// SetHealth sets the Health of the DedicatedGameServer
+	SetHealth(ctx context.Context, in *SetHealthRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error)
+	// SetState sets the state of the DedicatedGameServer
+	SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error)
+	// SetPlayers sets the number of active players of the DedicatedGameServer
+	SetPlayers(ctx context.Context, in *SetPlayersRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error)
+	// MarkForDeletion sets the MarkedForDeletion field of the DedicatedGameServer
+	MarkForDeletion(ctx context.Context, in *MarkForDeletionRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error)
+	// List returns the DedicatedGameServers of the namespace that pass the filters
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Allocate sets the state of an IDLE DedicatedGameServer that is ready to accept players and passes the filters to ASSIGNED, and returns it
+	// A DedicatedGameServer that is modified during the allocation (e.g. allocated by a concurrent call) is not allocated twice
+	// It fails with UNAVAILABLE if no DedicatedGameServer could be allocated
+	Allocate(ctx context.Context, in *AllocateRequest, opts ...grpc.CallOption) (*AllocateResponse, error)
+	// Watch streams the changes of the DedicatedGameServers of the namespace that pass the filters
+	// The stream ends with UNAVAILABLE if the client falls behind or the API Server shuts down, the client should resume from the last resource_version
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DedicatedGameServers_WatchClient, error)
+}
+
+type dedicatedGameServersClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDedicatedGameServersClient(cc *grpc.ClientConn) DedicatedGameServersClient {
+	return &dedicatedGameServersClient{cc}
+}
+
+func (c *dedicatedGameServersClient) SetHealth(ctx context.Context, in *SetHealthRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error) {
+	out := new(DedicatedGameServerStatus)
+	err := c.cc.Invoke(ctx, "/azuregaming.apiserver.v1.DedicatedGameServers/SetHealth", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dedicatedGameServersClient) SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error) {
+	out := new(DedicatedGameServerStatus)
+	err := c.cc.Invoke(ctx, "/azuregaming.apiserver.v1.DedicatedGameServers/SetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dedicatedGameServersClient) SetPlayers(ctx context.Context, in *SetPlayersRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error) {
+	out := new(DedicatedGameServerStatus)
+	err := c.cc.Invoke(ctx, "/azuregaming.apiserver.v1.DedicatedGameServers/SetPlayers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dedicatedGameServersClient) MarkForDeletion(ctx context.Context, in *MarkForDeletionRequest, opts ...grpc.CallOption) (*DedicatedGameServerStatus, error) {
+	out := new(DedicatedGameServerStatus)
+	err := c.cc.Invoke(ctx, "/azuregaming.apiserver.v1.DedicatedGameServers/MarkForDeletion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dedicatedGameServersClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/azuregaming.apiserver.v1.DedicatedGameServers/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dedicatedGameServersClient) Allocate(ctx context.Context, in *AllocateRequest, opts ...grpc.CallOption) (*AllocateResponse, error) {
+	out := new(AllocateResponse)
+	err := c.cc.Invoke(ctx, "/azuregaming.apiserver.v1.DedicatedGameServers/Allocate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dedicatedGameServersClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DedicatedGameServers_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DedicatedGameServers_serviceDesc.Streams[0], "/azuregaming.apiserver.v1.DedicatedGameServers/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dedicatedGameServersWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DedicatedGameServers_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type dedicatedGameServersWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *dedicatedGameServersWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DedicatedGameServersServer is the server API for DedicatedGameServers service.
+type DedicatedGameServersServer interface {
+	// SetHealth sets the Health of the DedicatedGameServer
+	SetHealth(context.Context, *SetHealthRequest) (*DedicatedGameServerStatus, error)
+	// SetState sets the state of the DedicatedGameServer
+	SetState(context.Context, *SetStateRequest) (*DedicatedGameServerStatus, error)
+	// SetPlayers sets the number of active players of the DedicatedGameServer
+	SetPlayers(context.Context, *SetPlayersRequest) (*DedicatedGameServerStatus, error)
+	// MarkForDeletion sets the MarkedForDeletion field of the DedicatedGameServer
+	MarkForDeletion(context.Context, *MarkForDeletionRequest) (*DedicatedGameServerStatus, error)
+	// List returns the DedicatedGameServers of the namespace that pass the filters
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Allocate sets the state of an IDLE DedicatedGameServer that is ready to accept players and passes the filters to ASSIGNED, and returns it
+	// A DedicatedGameServer that is modified during the allocation (e.g. allocated by a concurrent call) is not allocated twice
+	// It fails with UNAVAILABLE if no DedicatedGameServer could be allocated
+	Allocate(context.Context, *AllocateRequest) (*AllocateResponse, error)
+	// Watch streams the changes of the DedicatedGameServers of the namespace that pass the filters
+	// The stream ends with UNAVAILABLE if the client falls behind or the API Server shuts down, the client should resume from the last resource_version
+	Watch(*WatchRequest, DedicatedGameServers_WatchServer) error
+}
+
+func RegisterDedicatedGameServersServer(s *grpc.Server, srv DedicatedGameServersServer) {
+	s.RegisterService(&_DedicatedGameServers_serviceDesc, srv)
+}
+
+func _DedicatedGameServers_SetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DedicatedGameServersServer).SetHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.apiserver.v1.DedicatedGameServers/SetHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DedicatedGameServersServer).SetHealth(ctx, req.(*SetHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DedicatedGameServers_SetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DedicatedGameServersServer).SetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.apiserver.v1.DedicatedGameServers/SetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DedicatedGameServersServer).SetState(ctx, req.(*SetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DedicatedGameServers_SetPlayers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPlayersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DedicatedGameServersServer).SetPlayers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.apiserver.v1.DedicatedGameServers/SetPlayers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DedicatedGameServersServer).SetPlayers(ctx, req.(*SetPlayersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DedicatedGameServers_MarkForDeletion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkForDeletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DedicatedGameServersServer).MarkForDeletion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.apiserver.v1.DedicatedGameServers/MarkForDeletion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DedicatedGameServersServer).MarkForDeletion(ctx, req.(*MarkForDeletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DedicatedGameServers_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DedicatedGameServersServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.apiserver.v1.DedicatedGameServers/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DedicatedGameServersServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DedicatedGameServers_Allocate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DedicatedGameServersServer).Allocate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.apiserver.v1.DedicatedGameServers/Allocate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DedicatedGameServersServer).Allocate(ctx, req.(*AllocateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DedicatedGameServers_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DedicatedGameServersServer).Watch(m, &dedicatedGameServersWatchServer{stream})
+}
+
+type DedicatedGameServers_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type dedicatedGameServersWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *dedicatedGameServersWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _DedicatedGameServers_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "azuregaming.apiserver.v1.DedicatedGameServers",
+	HandlerType: (*DedicatedGameServersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetHealth",
+			Handler:    _DedicatedGameServers_SetHealth_Handler,
+		},
+		{
+			MethodName: "SetState",
+			Handler:    _DedicatedGameServers_SetState_Handler,
+		},
+		{
+			MethodName: "SetPlayers",
+			Handler:    _DedicatedGameServers_SetPlayers_Handler,
+		},
+		{
+			MethodName: "MarkForDeletion",
+			Handler:    _DedicatedGameServers_MarkForDeletion_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _DedicatedGameServers_List_Handler,
+		},
+		{
+			MethodName: "Allocate",
+			Handler:    _DedicatedGameServers_Allocate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _DedicatedGameServers_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "apiserver.proto",
+}
+
+func init() { proto.RegisterFile("apiserver.proto", fileDescriptor_apiserver_b47d658c6e3dc392) }
+
+var fileDescriptor_apiserver_b47d658c6e3dc392 = []byte{
+	// 1320 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xdd, 0x72, 0xdb, 0xc4,
+	0x17, 0xff, 0xcb, 0xdf, 0x3e, 0x71, 0x1c, 0x67, 0xdb, 0x7f, 0x31, 0x2e, 0xb4, 0xa9, 0x68, 0x3b,
+	0x69, 0x19, 0xec, 0x92, 0x42, 0xa7, 0xa5, 0xc3, 0x30, 0x6e, 0xec, 0x24, 0x1e, 0x92, 0x34, 0xc8,
+	0x6e, 0x4b, 0xb9, 0xd1, 0xac, 0xa5, 0x13, 0x5b, 0x8d, 0xac, 0x15, 0xda, 0xb5, 0xa7, 0xee, 0x05,
+	0x33, 0x5c, 0xf0, 0x06, 0xdc, 0xf3, 0x10, 0xdc, 0xf0, 0x1c, 0x3c, 0x01, 0x2f, 0xc0, 0x3b, 0x30,
+	0xbb, 0x92, 0x6c, 0xd7, 0xc4, 0x69, 0x62, 0x06, 0xae, 0xec, 0x3d, 0x7b, 0x7e, 0xe7, 0x77, 0xce,
+	0xd9, 0xf3, 0x21, 0x58, 0xa3, 0xbe, 0xc3, 0x31, 0x18, 0x61, 0x50, 0xf5, 0x03, 0x26, 0x18, 0x29,
+	0xd3, 0x37, 0xc3, 0x00, 0x7b, 0x74, 0xe0, 0x78, 0xbd, 0xea, 0xf4, 0x72, 0xf4, 0x69, 0xe5, 0x7a,
+	0x8f, 0xb1, 0x9e, 0x8b, 0x35, 0xa5, 0xd7, 0x1d, 0x1e, 0xd7, 0x84, 0x33, 0x40, 0x2e, 0xe8, 0xc0,
+	0x0f, 0xa1, 0xfa, 0x6f, 0x49, 0xb8, 0xd4, 0x40, 0xdb, 0xb1, 0xa8, 0x40, 0x7b, 0x97, 0x0e, 0xb0,
+	0xad, 0xb0, 0xe4, 0x03, 0xc8, 0x7b, 0x74, 0x80, 0xdc, 0xa7, 0x16, 0x96, 0xb5, 0x0d, 0x6d, 0x33,
+	0x6f, 0x4c, 0x05, 0x84, 0x40, 0x4a, 0x1e, 0xca, 0x09, 0x75, 0xa1, 0xfe, 0x93, 0x6b, 0x00, 0x16,
+	0x73, 0x5d, 0xb4, 0x84, 0xc3, 0xbc, 0x72, 0x52, 0xdd, 0xcc, 0x48, 0xc8, 0x37, 0x90, 0x71, 0x69,
+	0x17, 0x5d, 0x5e, 0x4e, 0x6d, 0x24, 0x37, 0x57, 0xb6, 0x1e, 0x55, 0x17, 0x79, 0x5d, 0x3d, 0xc5,
+	0xa1, 0xea, 0xbe, 0xc2, 0x36, 0x3d, 0x11, 0x8c, 0x8d, 0xc8, 0x10, 0xb9, 0x03, 0xa5, 0x00, 0x39,
+	0x1b, 0x06, 0x16, 0x9a, 0x23, 0x0c, 0xb8, 0x24, 0x4e, 0x2b, 0xe2, 0xb5, 0x58, 0xfe, 0x3c, 0x14,
+	0x93, 0x16, 0x10, 0x2b, 0x40, 0x2a, 0x3d, 0x31, 0x27, 0x39, 0x28, 0x67, 0x36, 0xb4, 0xcd, 0x95,
+	0xad, 0x4a, 0x35, 0xcc, 0x52, 0x35, 0xce, 0x52, 0xb5, 0x13, 0x6b, 0x18, 0xeb, 0x31, 0x6a, 0x22,
+	0x22, 0x5f, 0x43, 0x86, 0x0b, 0x2a, 0x86, 0xbc, 0x9c, 0x55, 0xf0, 0xfb, 0x17, 0x0a, 0xa4, 0xad,
+	0xa0, 0x46, 0x64, 0xa2, 0xf2, 0x08, 0x56, 0x66, 0x22, 0x23, 0x25, 0x48, 0x9e, 0xe0, 0x38, 0x4a,
+	0xb8, 0xfc, 0x4b, 0x2e, 0x43, 0x7a, 0x44, 0xdd, 0x61, 0x9c, 0xeb, 0xf0, 0xf0, 0x45, 0xe2, 0xa1,
+	0xa6, 0xff, 0x9e, 0x84, 0xf7, 0x17, 0x12, 0x90, 0xab, 0x90, 0xf7, 0x99, 0x6d, 0xfa, 0x7d, 0xca,
+	0xe3, 0x07, 0xcc, 0xf9, 0xcc, 0x3e, 0x92, 0x67, 0xf2, 0x10, 0x32, 0x7d, 0xa4, 0xae, 0xe8, 0x2b,
+	0xab, 0xc5, 0xad, 0x8d, 0xc5, 0x21, 0xec, 0x29, 0x3d, 0x23, 0xd2, 0x27, 0x9f, 0x43, 0x5a, 0x7a,
+	0x8e, 0xea, 0x81, 0x8b, 0x5b, 0xd7, 0x17, 0x03, 0xa5, 0x1f, 0x68, 0x84, 0xda, 0xa4, 0x0a, 0x97,
+	0x06, 0x34, 0x38, 0x41, 0xdb, 0x3c, 0x66, 0x81, 0x69, 0xa3, 0x8b, 0xaa, 0x4a, 0x52, 0x1b, 0xda,
+	0x66, 0xce, 0x58, 0x0f, 0xaf, 0x76, 0x58, 0xd0, 0x88, 0x2e, 0x94, 0xf7, 0xc3, 0xae, 0xeb, 0x58,
+	0xa6, 0xe3, 0x47, 0x4f, 0x9a, 0x0b, 0x05, 0x2d, 0x9f, 0x5c, 0x87, 0x95, 0xc9, 0xe5, 0xe8, 0x81,
+	0x7a, 0xc4, 0xbc, 0x01, 0xf1, 0xf5, 0xe8, 0x81, 0x44, 0x7b, 0xcc, 0x46, 0x53, 0xd5, 0x68, 0x36,
+	0x44, 0x4b, 0xc1, 0xa1, 0xac, 0xd3, 0x5b, 0x50, 0xa4, 0x96, 0x70, 0x46, 0x68, 0xfa, 0x2e, 0x1d,
+	0x63, 0xc0, 0xcb, 0xb9, 0x0d, 0x6d, 0x33, 0x6d, 0xac, 0x86, 0xd2, 0xa3, 0x50, 0x48, 0xbe, 0x82,
+	0x3c, 0xb5, 0xed, 0x00, 0x39, 0x47, 0x5e, 0xce, 0xab, 0x8a, 0xbd, 0xb1, 0x38, 0xd8, 0x7a, 0xa8,
+	0x6a, 0x4c, 0x31, 0xe4, 0x33, 0x48, 0xfb, 0x2c, 0x10, 0xbc, 0x0c, 0x0a, 0x7c, 0x6d, 0x31, 0xf8,
+	0x88, 0x05, 0xc2, 0x08, 0x95, 0xf5, 0x0e, 0x64, 0x23, 0x5b, 0xb2, 0xc9, 0xc4, 0xd8, 0x8f, 0x1f,
+	0x4f, 0xfd, 0x27, 0x65, 0xc8, 0x46, 0x0c, 0x51, 0x3d, 0xc4, 0x47, 0x19, 0xb3, 0xe3, 0x9b, 0xc7,
+	0x74, 0xe0, 0xb8, 0xe3, 0xa8, 0xfb, 0x72, 0x8e, 0xbf, 0xa3, 0xce, 0xfa, 0x2f, 0x1a, 0xa4, 0x24,
+	0xcb, 0xa4, 0x71, 0xb5, 0x99, 0xc6, 0xad, 0x40, 0x4e, 0x15, 0xbe, 0xc5, 0xdc, 0xc8, 0xe8, 0xe4,
+	0x2c, 0x93, 0x65, 0x31, 0x4f, 0x50, 0xc7, 0xc3, 0xc0, 0x94, 0x1e, 0x2a, 0xd3, 0x69, 0x63, 0x75,
+	0x22, 0x55, 0x66, 0xaf, 0x42, 0xbe, 0xcf, 0xb8, 0x08, 0x35, 0x52, 0x4a, 0x23, 0x27, 0x05, 0xea,
+	0xf2, 0x23, 0x58, 0xc5, 0xd7, 0x3e, 0xe3, 0xc3, 0x00, 0xcd, 0x01, 0xb3, 0x31, 0x7a, 0xcf, 0x42,
+	0x2c, 0x3c, 0x60, 0x36, 0xea, 0x3f, 0x40, 0xa9, 0x8d, 0x22, 0x2a, 0x36, 0xfc, 0x7e, 0x88, 0x5c,
+	0x2c, 0x31, 0x83, 0xa6, 0x75, 0x9d, 0xbc, 0x58, 0x5d, 0xeb, 0x6f, 0x60, 0xad, 0x8d, 0x22, 0xac,
+	0xd9, 0xa5, 0xe9, 0x97, 0x6b, 0x0e, 0xbd, 0x0f, 0xeb, 0x6d, 0x14, 0x51, 0xe1, 0x2d, 0xcf, 0x7e,
+	0x03, 0x0a, 0x61, 0x45, 0x9b, 0x16, 0x1b, 0x7a, 0xf1, 0x4b, 0xad, 0x84, 0xb2, 0x6d, 0x29, 0xd2,
+	0xdf, 0xc0, 0x95, 0x03, 0x1a, 0x9c, 0xcc, 0x74, 0xda, 0xf2, 0x74, 0x0b, 0x5a, 0x3a, 0xb9, 0xa0,
+	0xa5, 0xf5, 0x3f, 0x34, 0xc8, 0xee, 0x38, 0xae, 0x90, 0xcd, 0x75, 0x0b, 0x8a, 0x6a, 0x84, 0x9b,
+	0x1c, 0xe5, 0x7a, 0x60, 0x41, 0x44, 0xb9, 0xaa, 0xa4, 0xed, 0x48, 0x38, 0xb7, 0x52, 0x12, 0x7f,
+	0x5b, 0x29, 0x4b, 0x0e, 0x23, 0x19, 0x8d, 0xac, 0xc3, 0x54, 0x14, 0x0d, 0xb3, 0x91, 0xdc, 0x84,
+	0xe2, 0xc0, 0xf1, 0xcc, 0xe3, 0x00, 0xd1, 0xe4, 0x2e, 0x13, 0x5c, 0x55, 0x69, 0xda, 0x28, 0x0c,
+	0x1c, 0x6f, 0x27, 0x40, 0x6c, 0x4b, 0x19, 0xf9, 0x10, 0x20, 0x40, 0x6a, 0x8f, 0x4d, 0xe6, 0xb9,
+	0x63, 0x35, 0x78, 0x72, 0x46, 0x5e, 0x49, 0x9e, 0x7a, 0xee, 0x58, 0xff, 0x55, 0x83, 0x95, 0x7d,
+	0x87, 0x8b, 0xf3, 0x25, 0xf5, 0x31, 0x64, 0x8f, 0xc3, 0x7c, 0xa8, 0xd0, 0xce, 0x9c, 0x2f, 0x51,
+	0xe2, 0x8c, 0x18, 0x41, 0xde, 0x83, 0x2c, 0x67, 0x81, 0x30, 0xbb, 0x71, 0xb3, 0x67, 0xe4, 0xf1,
+	0x89, 0xda, 0x17, 0xae, 0x33, 0x70, 0xe2, 0x36, 0x0c, 0x0f, 0xb2, 0xc7, 0x65, 0xc7, 0x3a, 0xde,
+	0x30, 0x6e, 0xbf, 0xc9, 0x59, 0x67, 0x50, 0x08, 0x9d, 0xe6, 0x3e, 0xf3, 0x38, 0x92, 0x6d, 0x48,
+	0x3b, 0x02, 0x07, 0xbc, 0xac, 0xa9, 0xc1, 0xf5, 0xc9, 0x85, 0xd6, 0x9b, 0x11, 0x62, 0xdf, 0x22,
+	0x4c, 0xcc, 0x11, 0xfe, 0xa4, 0xc1, 0x5a, 0xdd, 0x75, 0x99, 0x75, 0xee, 0x66, 0xfb, 0x57, 0x52,
+	0xa5, 0xbf, 0x84, 0xd2, 0xd4, 0x8d, 0x28, 0xf8, 0x26, 0x64, 0x58, 0xf7, 0x15, 0x5a, 0x42, 0x39,
+	0x71, 0xe1, 0xe8, 0x23, 0xb0, 0xfe, 0xb3, 0x06, 0x85, 0x17, 0x54, 0x58, 0xfd, 0xff, 0x20, 0xbe,
+	0xd3, 0xbe, 0x82, 0x92, 0xa7, 0x7e, 0x05, 0xe9, 0x3f, 0x26, 0x00, 0x94, 0x5b, 0xcd, 0x11, 0x7a,
+	0x82, 0x7c, 0x39, 0xb3, 0x61, 0x8a, 0x5b, 0x77, 0x16, 0x73, 0x4e, 0x31, 0xd5, 0xce, 0xd8, 0xc7,
+	0x68, 0x19, 0x4d, 0x73, 0x95, 0xf8, 0x07, 0xb9, 0xba, 0x88, 0xff, 0x4f, 0x20, 0x25, 0xf9, 0xc9,
+	0x65, 0x28, 0x75, 0x5e, 0x1e, 0x35, 0xcd, 0x67, 0x87, 0xed, 0xa3, 0xe6, 0x76, 0x6b, 0xa7, 0xd5,
+	0x6c, 0x94, 0xfe, 0x47, 0xf2, 0x90, 0xae, 0x37, 0x1a, 0xcd, 0x46, 0x49, 0x23, 0x05, 0xc8, 0x1d,
+	0x3c, 0x6d, 0x84, 0x17, 0x09, 0xb2, 0x02, 0xd9, 0x46, 0x73, 0xbf, 0xd9, 0x69, 0x36, 0x4a, 0xc9,
+	0xbb, 0xbb, 0x90, 0x09, 0x67, 0x3f, 0xb9, 0x02, 0x64, 0xaf, 0x59, 0xdf, 0xef, 0xec, 0xcd, 0xd9,
+	0x29, 0x40, 0x6e, 0xdb, 0x68, 0xd6, 0x3b, 0xad, 0xc3, 0xdd, 0x92, 0x26, 0xc1, 0xa1, 0xd6, 0xcb,
+	0x52, 0x82, 0x00, 0x64, 0x76, 0xea, 0xad, 0x7d, 0x65, 0xa8, 0x0d, 0x69, 0x35, 0x56, 0xc8, 0xff,
+	0x61, 0xbd, 0xdd, 0xa9, 0x77, 0xe6, 0xdd, 0xc9, 0x41, 0xaa, 0xd5, 0xd8, 0x6f, 0x86, 0xde, 0xd4,
+	0xdb, 0xed, 0xd6, 0xee, 0x61, 0xec, 0x8d, 0xf1, 0xec, 0xf0, 0x50, 0x5a, 0x4f, 0x92, 0x22, 0xc0,
+	0xd1, 0xd3, 0x76, 0xc7, 0x3c, 0xa8, 0x77, 0xb6, 0xf7, 0x4a, 0xa9, 0xad, 0x3f, 0xd3, 0x70, 0xf9,
+	0x94, 0x64, 0x71, 0xe2, 0x42, 0x7e, 0xb2, 0x20, 0xc9, 0xdd, 0x33, 0x26, 0xdd, 0xdc, 0x16, 0xad,
+	0x2c, 0xf3, 0x79, 0x4a, 0x5e, 0x41, 0x2e, 0x5e, 0x87, 0xe4, 0xce, 0x99, 0x64, 0xb3, 0x2b, 0x73,
+	0x39, 0x2e, 0x0f, 0x60, 0xba, 0xfe, 0xc8, 0xc7, 0x67, 0xb2, 0xbd, 0xbd, 0x24, 0x97, 0xe3, 0x7b,
+	0x0d, 0x6b, 0x73, 0x4b, 0x90, 0xdc, 0x5b, 0x6c, 0xe7, 0xf4, 0x7d, 0xb9, 0x1c, 0xf3, 0x33, 0x48,
+	0xc9, 0x49, 0x4b, 0x6e, 0x2d, 0x06, 0xcf, 0xac, 0x8f, 0xca, 0xed, 0x77, 0xa9, 0x45, 0x33, 0x8b,
+	0x42, 0x2e, 0x9e, 0x63, 0x67, 0x3d, 0xd6, 0xdc, 0xc8, 0xad, 0xdc, 0x3d, 0x8f, 0x6a, 0x44, 0xf1,
+	0x02, 0xd2, 0x6a, 0x06, 0x90, 0xdb, 0xef, 0x18, 0x12, 0xb1, 0xf1, 0x9b, 0xe7, 0x19, 0x26, 0xf7,
+	0xb4, 0x27, 0xdf, 0x7e, 0xf7, 0xbc, 0xe7, 0x88, 0xfe, 0xb0, 0x5b, 0xb5, 0xd8, 0xa0, 0x66, 0xf7,
+	0x4e, 0xa8, 0x47, 0x05, 0x77, 0x18, 0xaf, 0xc5, 0x78, 0x0c, 0x91, 0x9c, 0x5b, 0xd4, 0x75, 0xbc,
+	0xde, 0xc9, 0xb0, 0x8b, 0x81, 0x87, 0x02, 0x79, 0xcd, 0x3f, 0xe9, 0xd5, 0x26, 0x96, 0x6b, 0xbd,
+	0xc0, 0xb7, 0xa8, 0xef, 0x3c, 0x8e, 0x7e, 0xbb, 0x19, 0xf5, 0x11, 0x7b, 0xff, 0xaf, 0x01, 0x00,
+	0xfe, 0x22, 0xe1, 0x95, 0x2e, 0x0f, 0x00, 0x00,
+}
@@ -0,0 +1,92 @@
+package grpcapi
+
+import (
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+// Healths and States map the DGS Healths and States to the values of the gRPC API
+var (
+	Healths = map[dgsv1alpha1.DGSHealth]Health{
+		dgsv1alpha1.DGSCreating: Health_CREATING,
+		dgsv1alpha1.DGSHealthy:  Health_HEALTHY,
+		dgsv1alpha1.DGSFailed:   Health_FAILED,
+	}
+	States = map[dgsv1alpha1.DGSState]State{
+		dgsv1alpha1.DGSIdle:      State_IDLE,
+		dgsv1alpha1.DGSAssigned:  State_ASSIGNED,
+		dgsv1alpha1.DGSRunning:   State_RUNNING,
+		dgsv1alpha1.DGSPostMatch: State_POST_MATCH,
+	}
+)
+
+// DGSHealth returns the DGS Health of the gRPC value
+// The name of the value is returned if it is not a DGS Health, so that the validation rejects it
+func DGSHealth(health Health) string {
+	for dgsHealth, value := range Healths {
+		if value == health {
+			return string(dgsHealth)
+		}
+	}
+	return health.String()
+}
+
+// DGSState returns the DGS State of the gRPC value
+// The name of the value is returned if it is not a DGS State, so that the validation rejects it
+func DGSState(state State) string {
+	for dgsState, value := range States {
+		if value == state {
+			return string(dgsState)
+		}
+	}
+	return state.String()
+}
+
+// FromDGS returns the gRPC message of the DedicatedGameServer
+func FromDGS(dgs *dgsv1alpha1.DedicatedGameServer) *DedicatedGameServer {
+	result := &DedicatedGameServer{
+		Namespace:       dgs.Namespace,
+		Name:            dgs.Name,
+		Collection:      dgs.Labels[shared.LabelDedicatedGameServerCollectionName],
+		Labels:          dgs.Labels,
+		ResourceVersion: dgs.ResourceVersion,
+		Status:          FromDGSStatus(&dgs.Status),
+	}
+	if !dgs.CreationTimestamp.IsZero() {
+		result.CreationTimestamp, _ = ptypes.TimestampProto(dgs.CreationTimestamp.Time)
+	}
+	return result
+}
+
+// FromDGSStatus returns the gRPC message of the DedicatedGameServer status
+func FromDGSStatus(dgsStatus *dgsv1alpha1.DedicatedGameServerStatus) *DedicatedGameServerStatus {
+	result := &DedicatedGameServerStatus{
+		PodPhase:          string(dgsStatus.PodPhase),
+		Health:            Healths[dgsStatus.Health],
+		State:             States[dgsStatus.DGSState],
+		MarkedForDeletion: dgsStatus.MarkedForDeletion,
+		PublicIp:          dgsStatus.PublicIP,
+		PublicIpv6:        dgsStatus.PublicIPv6,
+		NodeName:          dgsStatus.NodeName,
+		ActivePlayers:     int32(dgsStatus.ActivePlayers),
+	}
+	for _, address := range dgsStatus.Addresses {
+		result.Addresses = append(result.Addresses, &Address{
+			Type:     string(address.Type),
+			Address:  address.Address,
+			IpFamily: string(address.IPFamily),
+		})
+	}
+	for _, port := range dgsStatus.Ports {
+		result.Ports = append(result.Ports, &Port{
+			Name:          port.Name,
+			Protocol:      string(port.Protocol),
+			ContainerPort: port.ContainerPort,
+			HostPort:      port.HostPort,
+			ExposureMode:  string(port.ExposureMode),
+		})
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+package grpcapi
+
+import (
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuesCoverAllDGSValues(t *testing.T) {
+	for _, state := range dgsv1alpha1.AllDGSStates {
+		assert.Contains(t, States, state)
+		assert.Equal(t, string(state), DGSState(States[state]))
+	}
+	for _, health := range dgsv1alpha1.AllDGSHealths {
+		assert.Contains(t, Healths, health)
+		assert.Equal(t, string(health), DGSHealth(Healths[health]))
+	}
+	assert.Equal(t, "STATE_UNSPECIFIED", DGSState(State_STATE_UNSPECIFIED))
+}
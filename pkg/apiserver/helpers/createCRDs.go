@@ -1,47 +1,118 @@
 package helpers
 
 import (
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
 	log "github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func CreateDedicatedGameServerCRD(dgsName string, podSpec corev1.PodSpec, portsToExpose []int32) (finalDDGSName string, err error) {
-	log.Printf("Creating DedicatedGameServer %s", dgsName)
+var (
+	dgsGroupKind    = schema.GroupKind{Group: dgsv1alpha1.SchemeGroupVersion.Group, Kind: shared.DedicatedGameServerKind}
+	dgsColGroupKind = schema.GroupKind{Group: dgsv1alpha1.SchemeGroupVersion.Group, Kind: "DedicatedGameServerCollection"}
+)
 
-	dgs := shared.NewDedicatedGameServerWithNoParent(shared.GameNamespace, dgsName, podSpec, portsToExpose)
+// CreateDedicatedGameServerCRD validates the designated DedicatedGameServer and creates it without a parent DedicatedGameServerCollection
+// HostPorts for the PortsToExpose are assigned by the DedicatedGameServer controller, before the Pod is created
+// If dryRun is true, the validated object is returned without being persisted
+func CreateDedicatedGameServerCRD(dgs *dgsv1alpha1.DedicatedGameServer, dryRun bool) (*dgsv1alpha1.DedicatedGameServer, error) {
+	log.Printf("Creating DedicatedGameServer %s (dryRun: %t)", dgs.Name, dryRun)
 
-	_, dgsClient, err := shared.GetClientSet()
-	if err != nil {
-		return "", err
+	dgsToCreate := shared.NewDedicatedGameServerWithNoParent(dgs.Namespace, dgs.Name, dgs.Spec.Template, dgs.Spec.PortsToExpose)
+	dgsToCreate.GenerateName = dgs.GenerateName
+	dgsToCreate.Labels = dgs.Labels
+	dgsToCreate.Annotations = dgs.Annotations
+	dgsToCreate.Spec.AddressTypePriority = dgs.Spec.AddressTypePriority
+	dgsToCreate.Spec.HealthCheck = dgs.Spec.HealthCheck
+
+	// HostPorts are managed by the PortRegistry
+	for i := range dgsToCreate.Spec.Template.Containers {
+		for j := range dgsToCreate.Spec.Template.Containers[i].Ports {
+			dgsToCreate.Spec.Template.Containers[i].Ports[j].HostPort = 0
+		}
 	}
 
-	dgsInstance, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServers(shared.GameNamespace).Create(dgs)
+	if errs := shared.ValidateDedicatedGameServer(dgsToCreate); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(dgsGroupKind, dgsToCreate.Name, errs)
+	}
+
+	if dryRun {
+		return dgsToCreate, nil
+	}
 
+	_, dgsClient, err := shared.GetClientSet()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return dgsInstance.ObjectMeta.Name, nil
 
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServers(dgsToCreate.Namespace).Create(dgsToCreate)
 }
 
-func CreateDedicatedGameServerCollectionCRD(dgsColName string, replicas int32, podSpec corev1.PodSpec) (finalDGSColName string, err error) {
-	log.Printf("Creating DedicatedGameServerCollection %s", dgsColName)
+// CreateDedicatedGameServerCollectionCRD validates the designated DedicatedGameServerCollection and creates it
+// If dryRun is true, the validated object is returned without being persisted
+func CreateDedicatedGameServerCollectionCRD(dgsCol *dgsv1alpha1.DedicatedGameServerCollection, dryRun bool) (*dgsv1alpha1.DedicatedGameServerCollection, error) {
+	log.Printf("Creating DedicatedGameServerCollection %s (dryRun: %t)", dgsCol.Name, dryRun)
+
+	dgsCol.ResourceVersion = ""
+	dgsCol.Status = dgsv1alpha1.DedicatedGameServerCollectionStatus{
+		DGSCollectionHealth: dgsv1alpha1.DGSColCreating,
+		PodCollectionState:  corev1.PodPending,
+	}
+
+	if errs := shared.ValidateDedicatedGameServerCollection(dgsCol); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(dgsColGroupKind, dgsCol.Name, errs)
+	}
 
-	dgsCol := shared.NewDedicatedGameServerCollection(dgsColName, shared.GameNamespace, replicas, podSpec)
+	if dryRun {
+		return dgsCol, nil
+	}
 
 	_, dgsClient, err := shared.GetClientSet()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	dgsColInstance, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(shared.GameNamespace).Create(dgsCol)
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(dgsCol.Namespace).Create(dgsCol)
+}
+
+// UpdateDedicatedGameServerCollectionCRD validates the designated DedicatedGameServerCollection and replaces the Spec, Labels and Annotations
+// of the existing one. If the object has a ResourceVersion, the update fails with a Conflict if the existing object has been modified since
+// If dryRun is true, the validated object is returned without being persisted
+// It returns both the updated and the existing object
+func UpdateDedicatedGameServerCollectionCRD(dgsCol *dgsv1alpha1.DedicatedGameServerCollection, dryRun bool) (*dgsv1alpha1.DedicatedGameServerCollection, *dgsv1alpha1.DedicatedGameServerCollection, error) {
+	log.Printf("Updating DedicatedGameServerCollection %s (dryRun: %t)", dgsCol.Name, dryRun)
+
+	_, dgsClient, err := shared.GetClientSet()
+	if err != nil {
+		return nil, nil, err
+	}
 
+	existing, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(dgsCol.Namespace).Get(dgsCol.Name, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+
+	dgsColToUpdate := existing.DeepCopy()
+	dgsColToUpdate.Spec = dgsCol.Spec
+	dgsColToUpdate.Labels = dgsCol.Labels
+	dgsColToUpdate.Annotations = dgsCol.Annotations
+	if dgsCol.ResourceVersion != "" {
+		dgsColToUpdate.ResourceVersion = dgsCol.ResourceVersion
+	}
+
+	if errs := shared.ValidateDedicatedGameServerCollection(dgsColToUpdate); len(errs) > 0 {
+		return nil, existing, apierrors.NewInvalid(dgsColGroupKind, dgsColToUpdate.Name, errs)
+	}
+
+	if dryRun {
+		return dgsColToUpdate, existing, nil
 	}
-	return dgsColInstance.ObjectMeta.Name, nil
 
+	updated, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServerCollections(dgsColToUpdate.Namespace).Update(dgsColToUpdate)
+	return updated, existing, err
 }
@@ -6,10 +6,27 @@ import (
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 )
 
-func IsAPICallAuthenticated(w http.ResponseWriter, r *http.Request) (bool, error) {
-	code := r.FormValue("code")
+// AccessCodeHeader is the HTTP header that can carry the access code (or the DedicatedGameServer token)
+// instead of the 'code' parameter, so it does not end up in the URL
+const AccessCodeHeader = "X-Access-Code"
 
-	result, err := shared.AuthenticateWebServerCode(code)
+// ContinueHeader is the HTTP header that carries the continue token of the next page of the /running method
+const ContinueHeader = "X-Continue"
+
+// RequestCode returns the access code of the request
+// The AccessCodeHeader takes precedence over the 'code' parameter
+func RequestCode(r *http.Request) string {
+	if code := r.Header.Get(AccessCodeHeader); code != "" {
+		return code
+	}
+	return r.FormValue("code")
+}
+
+// IsAPICallAuthenticated checks the access code of the request against the access code of the designated namespace
+func IsAPICallAuthenticated(r *http.Request, namespace string) (bool, error) {
+	code := RequestCode(r)
+
+	result, err := shared.AuthenticateWebServerCode(code, namespace)
 
 	if err != nil {
 		return false, err
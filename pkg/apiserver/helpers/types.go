@@ -1,5 +1,9 @@
 package helpers
 
+import (
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+)
+
 // ServerMarkedForDeletion represents the markedForDeletion status of the dedicated game server
 type ServerMarkedForDeletion struct {
 	ServerName        string `json:"serverName"`
@@ -27,3 +31,54 @@ type ServerActivePlayers struct {
 	Namespace   string `json:"namespace"`
 	PlayerCount int    `json:"playerCount"`
 }
+
+// DGSStatusUpdate contains the DedicatedGameServer Status fields that can be modified via the API Server
+// Fields that are omitted (nil) are not modified
+type DGSStatusUpdate struct {
+	Health            *string `json:"health,omitempty"`
+	State             *string `json:"state,omitempty"`
+	MarkedForDeletion *bool   `json:"markedForDeletion,omitempty"`
+}
+
+// DGSPlayers represents the active players count of the dedicated game server
+type DGSPlayers struct {
+	PlayerCount int `json:"playerCount"`
+}
+
+// DGSBatchUpdate contains the updates of one or more DedicatedGameServers of a namespace
+type DGSBatchUpdate struct {
+	Items []DGSUpdate `json:"items"`
+}
+
+// DGSUpdate contains the fields of a DedicatedGameServer that can be modified via the batch update method
+// Fields that are omitted (nil) are not modified
+type DGSUpdate struct {
+	Name              string  `json:"name"`
+	Health            *string `json:"health,omitempty"`
+	State             *string `json:"state,omitempty"`
+	MarkedForDeletion *bool   `json:"markedForDeletion,omitempty"`
+	ActivePlayers     *int    `json:"activePlayers,omitempty"`
+	// Labels contains the labels to be set, a null value removes the label
+	Labels map[string]*string `json:"labels,omitempty"`
+}
+
+// DGSBatchUpdateResult contains the result of each update of a DGSBatchUpdate, in the same order
+type DGSBatchUpdateResult struct {
+	Items []DGSUpdateResult `json:"items"`
+}
+
+// DGSUpdateResult contains the updated Status and labels of the DedicatedGameServer, or the error if the update failed
+type DGSUpdateResult struct {
+	Name   string                                 `json:"name"`
+	Code   int                                    `json:"code"`
+	Status *dgsv1alpha1.DedicatedGameServerStatus `json:"status,omitempty"`
+	Labels map[string]string                      `json:"labels,omitempty"`
+	Error  *APIError                              `json:"error,omitempty"`
+}
+
+// APIError is the JSON body that the API Server returns when a request fails
+type APIError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
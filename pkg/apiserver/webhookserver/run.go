@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"strconv"
 
 	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
 	dgsscheme "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned/scheme"
 	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sidecar"
 
 	log "github.com/sirupsen/logrus"
 
@@ -17,9 +20,11 @@ import (
 	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 var (
@@ -39,6 +44,8 @@ var verboseLogging = false
 // WebhookServer represents the webhook server object
 type WebhookServer struct {
 	http.Server
+	// sidecarImage is the image of the SDK sidecar container, empty if the sidecar cannot be injected
+	sidecarImage string
 }
 
 type patchOperation struct {
@@ -63,22 +70,19 @@ func (whsvr *WebhookServer) mutate(ar *v1beta1.AdmissionReview) *v1beta1.Admissi
 	isDGSCol := false
 	isDGS := false
 	hasExistingAffinity := false
-	var podSpec *corev1.PodSpec
 
 	var dgsCol dgsv1alpha1.DedicatedGameServerCollection
 	err := json.Unmarshal(req.Object.Raw, &dgsCol)
 	if err == nil {
 		isDGSCol = true
 		hasExistingAffinity = dgsCol.Spec.Template.Affinity != nil
-		podSpec = &dgsCol.Spec.Template
 	}
 
 	var dgs dgsv1alpha1.DedicatedGameServer
 	err = json.Unmarshal(req.Object.Raw, &dgs)
 	if err == nil {
 		isDGS = true
-		hasExistingAffinity = dgsCol.Spec.Template.Affinity != nil
-		podSpec = &dgsCol.Spec.Template
+		hasExistingAffinity = dgs.Spec.Template.Affinity != nil
 	}
 
 	if !isDGSCol && !isDGS {
@@ -90,32 +94,27 @@ func (whsvr *WebhookServer) mutate(ar *v1beta1.AdmissionReview) *v1beta1.Admissi
 		}
 	}
 
-	//check if all the containers in the PodSpec have requests and limits (CPU and RAM) set
-	for _, container := range podSpec.Containers {
-		//check for requests
-		if container.Resources.Requests.Cpu() == nil ||
-			container.Resources.Requests.Cpu().IsZero() ||
-			container.Resources.Requests.Memory() == nil ||
-			container.Resources.Requests.Memory().IsZero() {
-			return &v1beta1.AdmissionResponse{
-				Allowed: false,
-				Result: &metav1.Status{
-					Message: fmt.Sprintf("Container called %s does not have Cpu and/or Memory requests defined", container.Name),
-				},
-			}
+	//validate the object using the same rules as the API Server
+	//updates that keep the spec (e.g. the status writes of the controllers and the API Server) are not validated,
+	//so that the objects created before a rule was added can still be updated
+	var errs field.ErrorList
+	if isDGS && req.Kind.Kind == "DedicatedGameServer" {
+		var oldDGS dgsv1alpha1.DedicatedGameServer
+		if !isUpdateOf(req, &oldDGS) || !reflect.DeepEqual(oldDGS.Spec, dgs.Spec) {
+			errs = shared.ValidateDedicatedGameServer(&dgs)
 		}
-
-		//check for limits
-		if container.Resources.Limits.Cpu() == nil ||
-			container.Resources.Limits.Cpu().IsZero() ||
-			container.Resources.Limits.Memory() == nil ||
-			container.Resources.Limits.Memory().IsZero() {
-			return &v1beta1.AdmissionResponse{
-				Allowed: false,
-				Result: &metav1.Status{
-					Message: fmt.Sprintf("Container called %s does not have Cpu and/or Memory limits defined", container.Name),
-				},
-			}
+	} else {
+		var oldDGSCol dgsv1alpha1.DedicatedGameServerCollection
+		if !isUpdateOf(req, &oldDGSCol) || !reflect.DeepEqual(oldDGSCol.Spec, dgsCol.Spec) {
+			errs = shared.ValidateDedicatedGameServerCollection(&dgsCol)
+		}
+	}
+	if len(errs) > 0 {
+		return &v1beta1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: errs.ToAggregate().Error(),
+			},
 		}
 	}
 
@@ -127,6 +126,14 @@ func (whsvr *WebhookServer) mutate(ar *v1beta1.AdmissionReview) *v1beta1.Admissi
 	var patch []patchOperation
 	patch = append(patch, addAffinity(hasExistingAffinity))
 
+	if req.Kind.Kind == "DedicatedGameServerCollection" && dgsCol.Annotations[shared.AnnotationSDKSidecar] == "true" {
+		if whsvr.sidecarImage == "" {
+			log.Warnf("DGSCol %s/%s requests the SDK sidecar but no sidecar image has been set", req.Namespace, dgsCol.Name)
+		} else if sidecarPatch, ok := addSDKSidecar(dgsCol.Spec.Template.Containers, whsvr.sidecarImage); ok {
+			patch = append(patch, sidecarPatch)
+		}
+	}
+
 	patchBytes, err := json.Marshal(patch)
 	if err != nil {
 		log.Errorf("Error during marshaling: %v", err.Error())
@@ -150,6 +157,14 @@ func (whsvr *WebhookServer) mutate(ar *v1beta1.AdmissionReview) *v1beta1.Admissi
 	}
 }
 
+// isUpdateOf returns true if the request is an update, in which case the previous version of the object is decoded into oldObject
+func isUpdateOf(req *v1beta1.AdmissionRequest, oldObject interface{}) bool {
+	if req.Operation != v1beta1.Update || len(req.OldObject.Raw) == 0 {
+		return false
+	}
+	return json.Unmarshal(req.OldObject.Raw, oldObject) == nil
+}
+
 // Serve method for webhook server
 func (whsvr *WebhookServer) serve(w http.ResponseWriter, r *http.Request) {
 	var body []byte
@@ -206,17 +221,19 @@ func (whsvr *WebhookServer) serve(w http.ResponseWriter, r *http.Request) {
 }
 
 // Run starts the webhook server in a new goroutine
-func Run(certFile, keyFile string, port int) *WebhookServer {
+// sidecarImage is the image of the SDK sidecar that is added to the DGSCols with the SDKSidecar annotation
+func Run(certFile, keyFile string, port int, sidecarImage string) *WebhookServer {
 	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		log.Errorf("Failed to load key pair: %v", err)
 	}
 
 	whsvr := &WebhookServer{
-		http.Server{
+		Server: http.Server{
 			Addr:      fmt.Sprintf(":%v", port),
 			TLSConfig: &tls.Config{Certificates: []tls.Certificate{pair}},
 		},
+		sidecarImage: sidecarImage,
 	}
 
 	// define http server and server handler
@@ -266,3 +283,45 @@ func addAffinity(affinityExists bool) patchOperation {
 
 	return patchAffinity
 }
+
+// addSDKSidecar returns the patch that adds the SDK sidecar container to the containers of the template
+// and sets the URL and the gRPC address of the sidecar to the game server containers. It returns false if the sidecar has already been added
+func addSDKSidecar(containers []corev1.Container, sidecarImage string) (patchOperation, bool) {
+	newContainers := make([]corev1.Container, 0, len(containers)+1)
+	for _, container := range containers {
+		if container.Name == shared.SDKSidecarContainerName {
+			return patchOperation{}, false
+		}
+		container = *container.DeepCopy()
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  shared.EnvSDKSidecarURL,
+			Value: fmt.Sprintf("http://localhost:%d", sidecar.DefaultPort),
+		}, corev1.EnvVar{
+			Name:  shared.EnvSDKSidecarGRPCAddress,
+			Value: fmt.Sprintf("localhost:%d", sidecar.DefaultGRPCPort),
+		})
+		newContainers = append(newContainers, container)
+	}
+
+	newContainers = append(newContainers, corev1.Container{
+		Name:  shared.SDKSidecarContainerName,
+		Image: sidecarImage,
+		Args:  []string{"./sdksidecar", "--port", strconv.Itoa(sidecar.DefaultPort), "--grpcport", strconv.Itoa(sidecar.DefaultGRPCPort)},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("30Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+		},
+	})
+
+	return patchOperation{
+		Op:    "replace",
+		Path:  "/spec/template/containers",
+		Value: newContainers,
+	}, true
+}
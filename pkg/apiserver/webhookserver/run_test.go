@@ -0,0 +1,81 @@
+package webhookserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestAddSDKSidecar(t *testing.T) {
+	containers := []corev1.Container{
+		{Name: "game", Image: "game:1", Env: []corev1.EnvVar{{Name: "MAP", Value: "q3dm17"}}},
+	}
+
+	patch, ok := addSDKSidecar(containers, "sdksidecar:1")
+	assert.True(t, ok)
+	assert.Equal(t, "replace", patch.Op)
+	assert.Equal(t, "/spec/template/containers", patch.Path)
+
+	newContainers := patch.Value.([]corev1.Container)
+	if assert.Len(t, newContainers, 2) {
+		assert.Equal(t, []corev1.EnvVar{{Name: "MAP", Value: "q3dm17"}, {Name: shared.EnvSDKSidecarURL, Value: "http://localhost:9358"}, {Name: shared.EnvSDKSidecarGRPCAddress, Value: "localhost:9359"}}, newContainers[0].Env)
+		assert.Equal(t, shared.SDKSidecarContainerName, newContainers[1].Name)
+		assert.Equal(t, "sdksidecar:1", newContainers[1].Image)
+		assert.Equal(t, []string{"./sdksidecar", "--port", "9358", "--grpcport", "9359"}, newContainers[1].Args)
+	}
+	// the original containers are not modified
+	assert.Len(t, containers[0].Env, 1)
+
+	// the sidecar is added only once
+	_, ok = addSDKSidecar(newContainers, "sdksidecar:1")
+	assert.False(t, ok)
+}
+
+// newDGSAdmissionReview returns the AdmissionReview of the operation on the DedicatedGameServer, oldDGS is nil for a create
+func newDGSAdmissionReview(t *testing.T, operation v1beta1.Operation, dgs, oldDGS *dgsv1alpha1.DedicatedGameServer) *v1beta1.AdmissionReview {
+	raw, err := json.Marshal(dgs)
+	assert.NoError(t, err)
+	request := &v1beta1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Kind: "DedicatedGameServer"},
+		Operation: operation,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+	if oldDGS != nil {
+		request.OldObject.Raw, err = json.Marshal(oldDGS)
+		assert.NoError(t, err)
+	}
+	return &v1beta1.AdmissionReview{Request: request}
+}
+
+func TestMutateValidatesChangedSpecs(t *testing.T) {
+	whsvr := &WebhookServer{}
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("64Mi"),
+	}
+	dgs := &dgsv1alpha1.DedicatedGameServer{ObjectMeta: metav1.ObjectMeta{Name: "dgs1"}}
+	dgs.Spec.Template.Containers = []corev1.Container{{Name: "game", Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources}}}
+	dgs.Spec.HealthCheck = &dgsv1alpha1.DGSHealthCheck{PeriodSeconds: -1}
+
+	assert.False(t, whsvr.mutate(newDGSAdmissionReview(t, v1beta1.Create, dgs, nil)).Allowed)
+
+	// the status of an object that breaks a rule can still be updated
+	updated := dgs.DeepCopy()
+	updated.Status.Health = dgsv1alpha1.DGSHealthy
+	assert.True(t, whsvr.mutate(newDGSAdmissionReview(t, v1beta1.Update, updated, dgs)).Allowed)
+
+	// its spec has to pass the rules once it changes
+	updated.Spec.HealthCheck.FailureThreshold = 3
+	assert.False(t, whsvr.mutate(newDGSAdmissionReview(t, v1beta1.Update, updated, dgs)).Allowed)
+	updated.Spec.HealthCheck.PeriodSeconds = 10
+	assert.True(t, whsvr.mutate(newDGSAdmissionReview(t, v1beta1.Update, updated, dgs)).Allowed)
+}
@@ -2,6 +2,7 @@ package dgs
 
 import (
 	"fmt"
+	"time"
 
 	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
 	dgsclientset "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned"
@@ -9,8 +10,10 @@ import (
 	informerdgs "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/informers/externalversions/azuregaming/v1alpha1"
 	listerdgs "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/listers/azuregaming/v1alpha1"
 	controllers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller/notifications"
 	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
+	"github.com/jonboulle/clockwork"
 	logrus "github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +32,9 @@ import (
 
 const dgsControllerAgentName = "dedigated-game-server-controller"
 
+// dgsNodeNameIndex is the name of the DGS informer index that maps Node names to the DGSs running on them
+const dgsNodeNameIndex = "nodeName"
+
 // Controller represents the Dedicated Game Server Controller
 type Controller struct {
 	dgsClient  dgsclientset.Interface
@@ -39,6 +45,9 @@ type Controller struct {
 	podLister  listercorev1.PodLister
 	nodeLister listercorev1.NodeLister
 
+	// dgsIndexer allows us to find the DGSs that are running on a specific Node
+	dgsIndexer cache.Indexer
+
 	dgsListerSynced  cache.InformerSynced
 	podListerSynced  cache.InformerSynced
 	nodeListerSynced cache.InformerSynced
@@ -46,9 +55,13 @@ type Controller struct {
 	logger *logrus.Logger
 
 	portRegistry *controllers.PortRegistry
+	// notifier delivers the lifecycle transitions of the DGSs to the webhook subscriptions, nil if there are none
+	notifier *notifications.Notifier
 	// recorder is an event recorder for recording Event resources to the
 	// Kubernetes API.
 	recorder record.EventRecorder
+	// clock is used to check the heartbeats of the DGSs that have a HealthCheck
+	clock clockwork.Clock
 
 	controllerHelper *controllers.ControllerHelper
 }
@@ -56,7 +69,8 @@ type Controller struct {
 // NewDedicatedGameServerController creates a new DedicatedGameServerController
 func NewDedicatedGameServerController(client kubernetes.Interface, dgsclient dgsclientset.Interface,
 	dgsInformer informerdgs.DedicatedGameServerInformer,
-	podInformer informercorev1.PodInformer, nodeInformer informercorev1.NodeInformer, portRegistry *controllers.PortRegistry) *Controller {
+	podInformer informercorev1.PodInformer, nodeInformer informercorev1.NodeInformer, portRegistry *controllers.PortRegistry,
+	notifier *notifications.Notifier, clockImpl clockwork.Clock) *Controller {
 
 	c := &Controller{
 		dgsClient:        dgsclient,
@@ -65,10 +79,13 @@ func NewDedicatedGameServerController(client kubernetes.Interface, dgsclient dgs
 		dgsLister:        dgsInformer.Lister(),
 		podLister:        podInformer.Lister(), //lister hits the cache
 		nodeLister:       nodeInformer.Lister(),
+		dgsIndexer:       dgsInformer.Informer().GetIndexer(),
 		dgsListerSynced:  dgsInformer.Informer().HasSynced,
 		podListerSynced:  podInformer.Informer().HasSynced,
 		nodeListerSynced: nodeInformer.Informer().HasSynced,
 		portRegistry:     portRegistry,
+		notifier:         notifier,
+		clock:            clockImpl,
 		logger:           shared.Logger(),
 	}
 
@@ -89,6 +106,12 @@ func NewDedicatedGameServerController(client kubernetes.Interface, dgsclient dgs
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
 	c.recorder = eventBroadcaster.NewRecorder(dgsscheme.Scheme, corev1.EventSource{Component: dgsControllerAgentName})
 
+	// index DGSs by the Node they are running on, so we can find them when the Node's addresses change
+	err := dgsInformer.Informer().AddIndexers(cache.Indexers{dgsNodeNameIndex: dgsNodeNameIndexFunc})
+	if err != nil {
+		c.logger.Errorf("Cannot add Node name indexer to DedicatedGameServer informer: %s", err.Error())
+	}
+
 	c.logger.Info("Setting up event handlers for DedicatedGameServer controller")
 
 	dgsInformer.Informer().AddEventHandler(
@@ -106,6 +129,8 @@ func NewDedicatedGameServerController(client kubernetes.Interface, dgsclient dgs
 					return
 				}
 
+				c.notifier.OnUpdate(oldDGS, newDGS)
+
 				if c.hasDGSChanged(oldDGS, newDGS) {
 					c.handleDedicatedGameServer(newObj)
 				}
@@ -139,9 +164,47 @@ func NewDedicatedGameServerController(client kubernetes.Interface, dgsclient dgs
 			},
 		},
 	)
+	nodeInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNode := oldObj.(*corev1.Node)
+				newNode := newObj.(*corev1.Node)
+
+				if oldNode.ResourceVersion == newNode.ResourceVersion {
+					return
+				}
+
+				if c.haveNodeAddressesChanged(oldNode, newNode) {
+					c.logger.WithField("Node", newNode.Name).Info("DedicatedGameServer controller - Node addresses changed")
+					c.handleNode(newNode)
+				}
+			},
+		},
+	)
 	return c
 }
 
+// dgsNodeNameIndexFunc indexes DedicatedGameServers by the name of the Node they are running on
+func dgsNodeNameIndexFunc(obj interface{}) ([]string, error) {
+	dgs, ok := obj.(*dgsv1alpha1.DedicatedGameServer)
+	if !ok || dgs.Status.NodeName == "" {
+		return []string{}, nil
+	}
+	return []string{dgs.Status.NodeName}, nil
+}
+
+// handleNode enqueues all the DedicatedGameServers that are running on the Node
+func (c *Controller) handleNode(node *corev1.Node) {
+	dgss, err := c.dgsIndexer.ByIndex(dgsNodeNameIndex, node.Name)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("cannot get DedicatedGameServers for Node %s because of %s", node.Name, err.Error()))
+		return
+	}
+	for _, dgs := range dgss {
+		c.enqueueDedicatedGameServer(dgs)
+	}
+}
+
 func (c *Controller) handlePod(obj interface{}) {
 	var object metav1.Object
 	var ok bool
@@ -194,10 +257,14 @@ func (c *Controller) handleDedicatedGameServer(obj interface{}) {
 }
 
 func (c *Controller) handleDedicatedGameServerDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
 	dgs, ok := obj.(*dgsv1alpha1.DedicatedGameServer)
 	if ok {
 		//make sure all ports are deleted from the registry
-		c.portRegistry.DeregisterServerPorts(dgs.Spec.PortsToExpose)
+		c.portRegistry.DeregisterServerPorts(controllers.GetHostPorts(dgs))
+		c.notifier.OnDelete(dgs)
 	}
 }
 
@@ -244,6 +311,12 @@ func (c *Controller) syncHandler(key string) error {
 	}
 
 	if pod == nil {
+		// DedicatedGameServers that do not belong to a DedicatedGameServerCollection (e.g. the ones created via the API Server)
+		// get their HostPorts here, before their Pod is created
+		if controllers.NeedsHostPorts(dgsTemp) {
+			return c.assignHostPorts(dgsTemp) // Pod will be created on DGS.Update event
+		}
+
 		// no pod found, so create one
 		err = c.createNewPod(dgsTemp)
 		if err != nil {
@@ -257,10 +330,10 @@ func (c *Controller) syncHandler(key string) error {
 	// pod found
 
 	// try to update DGS with Node's Public IP
-	// get the Node/Public IP for this Pod
-	var ip string
+	// get the Node addresses for this Pod
+	var addresses nodeAddresses
 	if pod.Spec.NodeName != "" { //no-empty string => pod has been scheduled
-		ip, err = c.getPublicIPForNode(pod.Spec.NodeName)
+		addresses, err = c.getAddressesForNode(pod.Spec.NodeName, dgsTemp.Spec.AddressTypePriority)
 		if err != nil {
 			c.logger.WithField("Node", pod.Spec.NodeName).Error("Error in getting Public IP for Node")
 			c.recorder.Event(pod, corev1.EventTypeWarning, "Error in getting Public IP for the Node", err.Error())
@@ -271,21 +344,38 @@ func (c *Controller) syncHandler(key string) error {
 	// let's update the DGS
 	dgsToUpdate := dgsTemp.DeepCopy()
 	c.logger.WithFields(logrus.Fields{
-		"serverName":       dgsTemp.Name,
-		"currentDGSHealth": dgsTemp.Status.Health,
-		"currentDGSState":  dgsTemp.Status.DGSState,
-		"currentPodPhase":  dgsTemp.Status.PodPhase,
-		"currentPublicIP":  dgsTemp.Status.PublicIP,
-		"currentNodeName":  dgsTemp.Status.NodeName,
-		"updatedPodPhase":  pod.Status.Phase,
-		"updatedPublicIP":  ip,
-		"updatedNodeName":  pod.Spec.NodeName,
+		"serverName":        dgsTemp.Name,
+		"currentDGSHealth":  dgsTemp.Status.Health,
+		"currentDGSState":   dgsTemp.Status.DGSState,
+		"currentPodPhase":   dgsTemp.Status.PodPhase,
+		"currentPublicIP":   dgsTemp.Status.PublicIP,
+		"currentPublicIPv6": dgsTemp.Status.PublicIPv6,
+		"currentNodeName":   dgsTemp.Status.NodeName,
+		"updatedPodPhase":   pod.Status.Phase,
+		"updatedPublicIP":   addresses.publicIP,
+		"updatedPublicIPv6": addresses.publicIPv6,
+		"updatedNodeName":   pod.Spec.NodeName,
 	}).Info("Updating DedicatedGameServer")
 
 	dgsToUpdate.Status.PodPhase = pod.Status.Phase
 
-	dgsToUpdate.Status.PublicIP = ip
+	dgsToUpdate.Status.PublicIP = addresses.publicIP
+	dgsToUpdate.Status.PublicIPv6 = addresses.publicIPv6
+	dgsToUpdate.Status.Addresses = addresses.addresses
 	dgsToUpdate.Status.NodeName = pod.Spec.NodeName
+	dgsToUpdate.Status.Ports = shared.GetDGSPorts(dgsTemp)
+
+	c.checkHeartbeats(key, dgsToUpdate, pod)
+
+	if pod.Spec.NodeName != "" {
+		// let the game server process know about its Public IP and ports
+		err = c.updatePodDGSInfo(pod, dgsToUpdate)
+		if err != nil {
+			c.logger.WithField("Name", dgsTemp.Name).Error("Error in updating DGSInfo annotation for Pod")
+			c.recorder.Event(pod, corev1.EventTypeWarning, "Error in updating DGSInfo annotation for Pod", err.Error())
+			return err
+		}
+	}
 
 	_, err = c.dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).Update(dgsToUpdate)
 
@@ -303,6 +393,32 @@ func (c *Controller) syncHandler(key string) error {
 	return nil
 }
 
+// checkHeartbeats sets the Health of a DGS that has a HealthCheck to Failed if its heartbeats stopped
+// Otherwise the DGS is enqueued again for the time that the next heartbeat is due, since heartbeats do not trigger a sync
+func (c *Controller) checkHeartbeats(key string, dgs *dgsv1alpha1.DedicatedGameServer, pod *corev1.Pod) {
+	// heartbeats are expected once the Pod has started
+	if dgs.Status.Health == dgsv1alpha1.DGSFailed || pod.Status.StartTime == nil {
+		return
+	}
+	deadline, ok := shared.HeartbeatDeadline(dgs, pod.Status.StartTime.Time)
+	if !ok {
+		return
+	}
+
+	now := c.clock.Now()
+	if now.After(deadline) {
+		c.logger.WithFields(logrus.Fields{
+			"serverName":    dgs.Name,
+			"lastHeartbeat": dgs.Status.LastHeartbeat,
+		}).Info("DedicatedGameServer missed its heartbeats, setting its Health to Failed")
+		c.recorder.Event(dgs, corev1.EventTypeWarning, "HeartbeatsMissed", "DedicatedGameServer missed its heartbeats, its Health was set to Failed")
+		dgs.Status.Health = dgsv1alpha1.DGSFailed
+		return
+	}
+	// one second later, so the heartbeats that arrive exactly at the deadline are taken into account
+	c.controllerHelper.Workqueue.AddAfter(key, deadline.Sub(now)+time.Second)
+}
+
 func (c *Controller) getPodForDGS(dgs *dgsv1alpha1.DedicatedGameServer) (*corev1.Pod, error) {
 	// Let's see if the corresponding pod for this DGS exists
 	// grab all the DedicatedGameServers that belong to this DedicatedGameServerCollection
@@ -350,14 +466,14 @@ func (c *Controller) enqueueDedicatedGameServer(obj interface{}) {
 }
 
 func (c *Controller) createNewPod(dgs *dgsv1alpha1.DedicatedGameServer) error {
-	accesscode, err := shared.GetAccessCode(c.podClient)
+	key, err := shared.GetDGSTokenSigningKey(c.podClient, dgs.Namespace)
 	if err != nil {
-		return fmt.Errorf("Cannot get API Server Access Code because of: %s", err.Error())
+		return fmt.Errorf("Cannot get DGS token signing key because of: %s", err.Error())
 	}
 	pod := shared.NewPod(dgs,
 		shared.APIDetails{
-			APIServerURL: shared.APIServerURL,
-			Code:         accesscode,
+			APIServerURL:    shared.APIServerURL,
+			TokenSigningKey: key,
 		})
 	_, err = c.podClient.CoreV1().Pods(dgs.Namespace).Create(pod)
 	if err != nil {
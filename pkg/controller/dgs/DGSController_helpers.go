@@ -2,8 +2,10 @@ package dgs
 
 import (
 	"fmt"
+	"net"
 
 	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	controllers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
 	logrus "github.com/sirupsen/logrus"
@@ -14,7 +16,7 @@ import (
 )
 
 // hasDGSChanged returns true if *all* of the following DGS properties have changed
-// dgsHealth, podPhase, publicIP, nodeName, activePlayers
+// dgsHealth, podPhase, publicIP, publicIPv6, nodeName, activePlayers, addresses, ports
 // As expected, it returns false if at least one has changed
 func (c *Controller) hasDGSChanged(oldDGS, newDGS *dgsv1alpha1.DedicatedGameServer) bool {
 
@@ -30,12 +32,21 @@ func (c *Controller) hasDGSChanged(oldDGS, newDGS *dgsv1alpha1.DedicatedGameServ
 		}
 	}
 
+	//check if HostPorts have been assigned
+	if controllers.NeedsHostPorts(oldDGS) != controllers.NeedsHostPorts(newDGS) {
+		return true
+	}
+
 	// we check if all of the following fields are the same
+	// LastHeartbeat is not one of them, the heartbeats are checked when the DGS is enqueued again by checkHeartbeats
 	if oldDGS.Status.Health != newDGS.Status.Health ||
 		oldDGS.Status.PodPhase != newDGS.Status.PodPhase ||
 		oldDGS.Status.PublicIP != newDGS.Status.PublicIP ||
+		oldDGS.Status.PublicIPv6 != newDGS.Status.PublicIPv6 ||
 		oldDGS.Status.NodeName != newDGS.Status.NodeName ||
 		oldDGS.Status.ActivePlayers != newDGS.Status.ActivePlayers ||
+		!areDGSAddressesSame(oldDGS.Status.Addresses, newDGS.Status.Addresses) ||
+		!areDGSPortsSame(oldDGS.Status.Ports, newDGS.Status.Ports) ||
 		!shared.AreMapsSame(oldDGS.Labels, newDGS.Labels) {
 
 		return true
@@ -44,6 +55,45 @@ func (c *Controller) hasDGSChanged(oldDGS, newDGS *dgsv1alpha1.DedicatedGameServ
 	return false
 }
 
+// areDGSAddressesSame returns true if both lists contain the same addresses, in the same order
+func areDGSAddressesSame(oldAddresses, newAddresses []dgsv1alpha1.DGSAddress) bool {
+	if len(oldAddresses) != len(newAddresses) {
+		return false
+	}
+	for i := range oldAddresses {
+		if oldAddresses[i] != newAddresses[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// areDGSPortsSame returns true if both lists contain the same ports, in the same order
+func areDGSPortsSame(oldPorts, newPorts []dgsv1alpha1.DGSPort) bool {
+	if len(oldPorts) != len(newPorts) {
+		return false
+	}
+	for i := range oldPorts {
+		if oldPorts[i] != newPorts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// haveNodeAddressesChanged returns true if any of the Node's addresses has been added, removed or modified
+func (c *Controller) haveNodeAddressesChanged(oldNode, newNode *corev1.Node) bool {
+	if len(oldNode.Status.Addresses) != len(newNode.Status.Addresses) {
+		return true
+	}
+	for i := range oldNode.Status.Addresses {
+		if oldNode.Status.Addresses[i] != newNode.Status.Addresses[i] {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Controller) handleDGSMarkedForDeletionWithZeroPlayers(dgsTemp *dgsv1alpha1.DedicatedGameServer) error {
 	err := c.dgsClient.AzuregamingV1alpha1().DedicatedGameServers(dgsTemp.Namespace).Delete(dgsTemp.Name, &metav1.DeleteOptions{})
 	if err != nil {
@@ -59,24 +109,128 @@ func (c *Controller) handleDGSMarkedForDeletionWithZeroPlayers(dgsTemp *dgsv1alp
 	return nil //nothing more to do here
 }
 
-func (c *Controller) getPublicIPForNode(nodeName string) (string, error) {
+// defaultAddressTypePriority is used for the DGS PublicIP when the DedicatedGameServer does not specify an AddressTypePriority
+var defaultAddressTypePriority = []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP}
+
+// nodeAddresses contains the addresses of a Node, as well as the ones chosen as the DGS Public IPs
+type nodeAddresses struct {
+	publicIP   string
+	publicIPv6 string
+	addresses  []dgsv1alpha1.DGSAddress
+}
+
+// getAddressesForNode returns all the addresses of the Node, along with the IPv4 and IPv6 Public IPs
+// chosen according to the designated address type priority
+func (c *Controller) getAddressesForNode(nodeName string, addressTypePriority []corev1.NodeAddressType) (nodeAddresses, error) {
 	node, err := c.nodeLister.Get(nodeName)
 	if err != nil {
-		return "", err
+		return nodeAddresses{}, err
 	}
-	for _, x := range node.Status.Addresses {
-		if x.Type == corev1.NodeExternalIP {
-			return x.Address, nil
-		}
+
+	if len(addressTypePriority) == 0 {
+		addressTypePriority = defaultAddressTypePriority
+	}
+
+	result := nodeAddresses{
+		addresses: getDGSAddresses(node),
+	}
+	result.publicIPv6 = selectAddress(result.addresses, addressTypePriority, dgsv1alpha1.DGSIPv6)
+	result.publicIP = selectAddress(result.addresses, addressTypePriority, dgsv1alpha1.DGSIPv4)
+	if result.publicIP == "" {
+		// single-stack IPv6 Node
+		result.publicIP = result.publicIPv6
+	}
+
+	if result.publicIP == "" {
+		return nodeAddresses{}, fmt.Errorf("Node with name %s does not have an address of types %v", nodeName, addressTypePriority)
 	}
-	c.logger.Infof("Node with name %s does not have a Public IP, will try to return the InternalIP", nodeName)
-	// externalIP not found, try InternalIP
+
+	return result, nil
+}
+
+// getDGSAddresses returns all the addresses of the Node, along with their IP family
+func getDGSAddresses(node *corev1.Node) []dgsv1alpha1.DGSAddress {
+	addresses := make([]dgsv1alpha1.DGSAddress, 0, len(node.Status.Addresses))
 	for _, x := range node.Status.Addresses {
-		if x.Type == corev1.NodeInternalIP {
-			return x.Address, nil
+		addresses = append(addresses, dgsv1alpha1.DGSAddress{
+			Type:     x.Type,
+			Address:  x.Address,
+			IPFamily: getIPFamily(x.Address),
+		})
+	}
+	return addresses
+}
+
+// selectAddress returns the first address that belongs to the designated IP family, following the address type priority
+// Addresses that are not IPs (e.g. Hostname or ExternalDNS) are considered to be IPv4, so they can be used as the primary Public IP
+func selectAddress(addresses []dgsv1alpha1.DGSAddress, addressTypePriority []corev1.NodeAddressType, family dgsv1alpha1.DGSIPFamily) string {
+	for _, addressType := range addressTypePriority {
+		for _, x := range addresses {
+			if x.Type != addressType {
+				continue
+			}
+			if x.IPFamily == family || (x.IPFamily == "" && family == dgsv1alpha1.DGSIPv4) {
+				return x.Address
+			}
 		}
 	}
-	return "", fmt.Errorf("Node with name %s does not have a Public or Internal IP", nodeName)
+	return ""
+}
+
+// getIPFamily returns the IP family of the address or an empty string if the address is not an IP
+func getIPFamily(address string) dgsv1alpha1.DGSIPFamily {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return dgsv1alpha1.DGSIPv4
+	}
+	return dgsv1alpha1.DGSIPv6
+}
+
+// assignHostPorts assigns HostPorts from the PortRegistry to the DedicatedGameServer's PortsToExpose and updates it
+func (c *Controller) assignHostPorts(dgsTemp *dgsv1alpha1.DedicatedGameServer) error {
+	dgsToUpdate := dgsTemp.DeepCopy()
+	// on failure, only the ports assigned here are deregistered, the ones assigned before belong to the DedicatedGameServer
+	assigned, err := c.portRegistry.AssignHostPorts(dgsToUpdate)
+	if err != nil {
+		c.portRegistry.DeregisterServerPorts(assigned)
+		c.recorder.Event(dgsTemp, corev1.EventTypeWarning, "Error assigning HostPorts to DedicatedGameServer", err.Error())
+		return err
+	}
+
+	c.logger.WithFields(logrus.Fields{"DGSName": dgsTemp.Name, "HostPorts": assigned}).Info("Assigned HostPorts to DedicatedGameServer")
+
+	_, err = c.dgsClient.AzuregamingV1alpha1().DedicatedGameServers(dgsToUpdate.Namespace).Update(dgsToUpdate)
+	if err != nil {
+		// the ports will be assigned again on the next sync
+		c.portRegistry.DeregisterServerPorts(assigned)
+		return err
+	}
+	return nil
+}
+
+// updatePodDGSInfo sets the DGSInfo annotation on the Pod, if it has changed
+// The annotation is projected via the Downward API to a file that the game server can read
+func (c *Controller) updatePodDGSInfo(pod *corev1.Pod, dgs *dgsv1alpha1.DedicatedGameServer) error {
+	info, err := shared.NewDGSInfo(dgs).Marshal()
+	if err != nil {
+		return err
+	}
+
+	if pod.Annotations[shared.AnnotationDedicatedGameServerInfo] == info {
+		return nil // nothing changed
+	}
+
+	podToUpdate := pod.DeepCopy()
+	if podToUpdate.Annotations == nil {
+		podToUpdate.Annotations = make(map[string]string)
+	}
+	podToUpdate.Annotations[shared.AnnotationDedicatedGameServerInfo] = info
+
+	_, err = c.podClient.CoreV1().Pods(pod.Namespace).Update(podToUpdate)
+	return err
 }
 
 func (c *Controller) isDGSMarkedForDeletionWithZeroPlayers(dgs *dgsv1alpha1.DedicatedGameServer) bool {
@@ -2,17 +2,23 @@ package dgs
 
 import (
 	"testing"
+	"time"
 
 	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned/fake"
 	dgsinformers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/informers/externalversions"
+	controllers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/controller/testhelpers"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
@@ -27,14 +33,18 @@ type dgsFixture struct {
 	dgsClient *fake.Clientset
 	// Objects to put in the store.
 
-	dgsLister []*dgsv1alpha1.DedicatedGameServer
-	podLister []*corev1.Pod
+	dgsLister  []*dgsv1alpha1.DedicatedGameServer
+	podLister  []*corev1.Pod
+	nodeLister []*corev1.Node
 	// Actions expected to happen on the client.
 	k8sActions []testhelpers.ExtendedAction
 	dgsActions []testhelpers.ExtendedAction
 	// Objects from here preloaded into NewSimpleFake.
 	k8sObjects []runtime.Object
 	dgsObjects []runtime.Object
+
+	portRegistry *controllers.PortRegistry
+	clock        clockwork.FakeClock
 }
 
 func newDGSFixture(t *testing.T) *dgsFixture {
@@ -44,6 +54,7 @@ func newDGSFixture(t *testing.T) *dgsFixture {
 
 	f.dgsObjects = []runtime.Object{}
 	f.k8sObjects = []runtime.Object{}
+	f.clock = clockwork.NewFakeClockAt(testhelpers.FixedTime)
 
 	return f
 }
@@ -61,10 +72,11 @@ func (f *dgsFixture) newDedicatedGameServerController() (*Controller,
 		f.dgsClient,
 		dgsInformers.Azuregaming().V1alpha1().DedicatedGameServers(),
 		k8sInformers.Core().V1().Pods(),
-		k8sInformers.Core().V1().Nodes(), nil)
+		k8sInformers.Core().V1().Nodes(), f.portRegistry, nil, f.clock)
 
 	testController.dgsListerSynced = testhelpers.AlwaysReady
 	testController.podListerSynced = testhelpers.AlwaysReady
+	testController.nodeListerSynced = testhelpers.AlwaysReady
 
 	testController.recorder = &record.FakeRecorder{}
 
@@ -76,6 +88,10 @@ func (f *dgsFixture) newDedicatedGameServerController() (*Controller,
 		k8sInformers.Core().V1().Pods().Informer().GetIndexer().Add(pod)
 	}
 
+	for _, node := range f.nodeLister {
+		k8sInformers.Core().V1().Nodes().Informer().GetIndexer().Add(node)
+	}
+
 	return testController, dgsInformers, k8sInformers
 }
 
@@ -138,6 +154,12 @@ func (f *dgsFixture) expectCreatePodAction(p *corev1.Pod, assertions func(runtim
 	f.k8sActions = append(f.k8sActions, extAction)
 }
 
+func (f *dgsFixture) expectUpdatePodAction(p *corev1.Pod, assertions func(runtime.Object)) {
+	action := core.NewUpdateAction(schema.GroupVersionResource{Resource: "pods"}, p.Namespace, p)
+	extAction := testhelpers.ExtendedAction{Action: action, Assertions: assertions}
+	f.k8sActions = append(f.k8sActions, extAction)
+}
+
 func (f *dgsFixture) expectDeleteDGSAction(dgs *dgsv1alpha1.DedicatedGameServer, assertions func(runtime.Object)) {
 	action := core.NewDeleteAction(schema.GroupVersionResource{Group: "azuregaming.com", Resource: "dedicatedgameservers", Version: "v1alpha1"}, dgs.Namespace, dgs.Name)
 	extAction := testhelpers.ExtendedAction{Action: action, Assertions: assertions}
@@ -171,19 +193,51 @@ func TestCreatesPod(t *testing.T) {
 
 	f.k8sObjects = append(f.k8sObjects, &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "apiaccesscode",
+			Name:      shared.DGSTokenSigningKeySecretName,
 			Namespace: shared.GameNamespace,
 		},
-		StringData: map[string]string{"code": ""},
+		Data: map[string][]byte{"key": []byte("testkey")},
 	})
 
-	expPod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", Code: ""})
+	expPod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
 
 	f.expectCreatePodAction(expPod, nil)
 
 	f.run(getKeyDGS(dgs, t))
 }
 
+func TestAssignsHostPortsToStandaloneDGS(t *testing.T) {
+	f := newDGSFixture(t)
+
+	portRegistry, err := controllers.NewPortRegistry(fake.NewSimpleClientset(), 20000, 20010, shared.GameNamespace)
+	assert.NoError(t, err)
+	defer portRegistry.Stop()
+	f.portRegistry = portRegistry
+
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "test",
+				Image: "testimage",
+				Ports: []corev1.ContainerPort{{ContainerPort: 7777}, {ContainerPort: 8080}},
+			},
+		},
+	}
+	dgs := shared.NewDedicatedGameServerWithNoParent(shared.GameNamespace, "standalone", podSpec, []int32{7777})
+
+	f.dgsLister = append(f.dgsLister, dgs)
+	f.dgsObjects = append(f.dgsObjects, dgs)
+
+	f.expectUpdateDGSAction(dgs, func(actual runtime.Object) {
+		dgs := actual.(*dgsv1alpha1.DedicatedGameServer)
+		ports := dgs.Spec.Template.Containers[0].Ports
+		assert.True(t, ports[0].HostPort >= 20000 && ports[0].HostPort <= 20010)
+		assert.Equal(t, int32(0), ports[1].HostPort)
+	})
+
+	f.run(getKeyDGS(dgs, t))
+}
+
 func TestDeleteDGSWithZeroActivePlayers(t *testing.T) {
 	f := newDGSFixture(t)
 
@@ -194,7 +248,7 @@ func TestDeleteDGSWithZeroActivePlayers(t *testing.T) {
 	dgs.Status.Health = dgsv1alpha1.DGSHealthy
 	dgs.Status.MarkedForDeletion = true
 
-	delPod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", Code: ""})
+	delPod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
 
 	f.podLister = append(f.podLister, delPod)
 	f.k8sObjects = append(f.k8sObjects, delPod)
@@ -215,7 +269,7 @@ func TestDGSStatusIsUpdated(t *testing.T) {
 
 	//dgs.Status.ActivePlayers = 0
 
-	pod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", Code: ""})
+	pod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
 
 	f.podLister = append(f.podLister, pod)
 	f.k8sObjects = append(f.k8sObjects, pod)
@@ -228,6 +282,228 @@ func TestDGSStatusIsUpdated(t *testing.T) {
 	f.run(getKeyDGS(dgs, t))
 }
 
+func TestDGSWithMissedHeartbeatsFails(t *testing.T) {
+	f := newDGSFixture(t)
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 1, testhelpers.PodSpec)
+	dgs := shared.NewDedicatedGameServer(dgsCol, testhelpers.PodSpec)
+	dgs.Spec.HealthCheck = &dgsv1alpha1.DGSHealthCheck{PeriodSeconds: 10, FailureThreshold: 3}
+	dgs.Status.Health = dgsv1alpha1.DGSHealthy
+	dgs.Status.LastHeartbeat = &metav1.Time{Time: testhelpers.FixedTime.Add(-31 * time.Second)}
+
+	pod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
+	pod.Status.StartTime = &metav1.Time{Time: testhelpers.FixedTime.Add(-time.Hour)}
+
+	f.podLister = append(f.podLister, pod)
+	f.k8sObjects = append(f.k8sObjects, pod)
+
+	f.dgsLister = append(f.dgsLister, dgs)
+	f.dgsObjects = append(f.dgsObjects, dgs)
+
+	f.expectUpdateDGSAction(dgs, func(actual runtime.Object) {
+		assert.Equal(t, dgsv1alpha1.DGSFailed, actual.(*dgsv1alpha1.DedicatedGameServer).Status.Health)
+	})
+
+	f.run(getKeyDGS(dgs, t))
+}
+
+func TestDGSWithRecentHeartbeatStaysHealthy(t *testing.T) {
+	f := newDGSFixture(t)
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 1, testhelpers.PodSpec)
+	dgs := shared.NewDedicatedGameServer(dgsCol, testhelpers.PodSpec)
+	dgs.Spec.HealthCheck = &dgsv1alpha1.DGSHealthCheck{PeriodSeconds: 10, FailureThreshold: 3}
+	dgs.Status.Health = dgsv1alpha1.DGSHealthy
+	dgs.Status.LastHeartbeat = &metav1.Time{Time: testhelpers.FixedTime.Add(-29 * time.Second)}
+
+	pod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
+	pod.Status.StartTime = &metav1.Time{Time: testhelpers.FixedTime.Add(-time.Hour)}
+
+	f.podLister = append(f.podLister, pod)
+	f.k8sObjects = append(f.k8sObjects, pod)
+
+	f.dgsLister = append(f.dgsLister, dgs)
+	f.dgsObjects = append(f.dgsObjects, dgs)
+
+	f.expectUpdateDGSAction(dgs, func(actual runtime.Object) {
+		assert.Equal(t, dgsv1alpha1.DGSHealthy, actual.(*dgsv1alpha1.DedicatedGameServer).Status.Health)
+	})
+
+	f.run(getKeyDGS(dgs, t))
+}
+
+func TestHeartbeatDoesNotTriggerSync(t *testing.T) {
+	f := newDGSFixture(t)
+	testController, _, _ := f.newDedicatedGameServerController()
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 1, testhelpers.PodSpec)
+	oldDGS := shared.NewDedicatedGameServer(dgsCol, testhelpers.PodSpec)
+	newDGS := oldDGS.DeepCopy()
+	newDGS.Status.LastHeartbeat = &metav1.Time{Time: testhelpers.FixedTime}
+	assert.False(t, testController.hasDGSChanged(oldDGS, newDGS))
+}
+
+func TestPodDGSInfoIsUpdated(t *testing.T) {
+	f := newDGSFixture(t)
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 1, testhelpers.PodSpec)
+	dgs := shared.NewDedicatedGameServer(dgsCol, corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Ports: []corev1.ContainerPort{{Name: "game", ContainerPort: 7777, HostPort: 20001, Protocol: corev1.ProtocolUDP}},
+			},
+		},
+	})
+	dgs.Spec.PortsToExpose = []int32{7777}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.4"},
+				{Type: corev1.NodeExternalIP, Address: "1.2.3.4"},
+			},
+		},
+	}
+
+	pod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
+	pod.Spec.NodeName = node.Name
+
+	f.nodeLister = append(f.nodeLister, node)
+	f.k8sObjects = append(f.k8sObjects, node)
+
+	f.podLister = append(f.podLister, pod)
+	f.k8sObjects = append(f.k8sObjects, pod)
+
+	f.dgsLister = append(f.dgsLister, dgs)
+	f.dgsObjects = append(f.dgsObjects, dgs)
+
+	f.expectUpdatePodAction(pod, func(actual runtime.Object) {
+		p := actual.(*corev1.Pod)
+		assert.Equal(t, `{"publicIP":"1.2.3.4","addresses":[{"type":"InternalIP","address":"10.0.0.4","ipFamily":"IPv4"},{"type":"ExternalIP","address":"1.2.3.4","ipFamily":"IPv4"}],"nodeName":"node1","ports":[{"name":"game","protocol":"UDP","containerPort":7777,"hostPort":20001,"exposureMode":"HostPort"}]}`,
+			p.Annotations[shared.AnnotationDedicatedGameServerInfo])
+	})
+	f.expectUpdateDGSAction(dgs, func(actual runtime.Object) {
+		d := actual.(*dgsv1alpha1.DedicatedGameServer)
+		assert.Equal(t, "1.2.3.4", d.Status.PublicIP)
+		assert.Equal(t, "node1", d.Status.NodeName)
+		assert.Equal(t, []dgsv1alpha1.DGSPort{
+			{Name: "game", Protocol: corev1.ProtocolUDP, ContainerPort: 7777, HostPort: 20001, ExposureMode: dgsv1alpha1.DGSPortHostPort},
+		}, d.Status.Ports)
+	})
+
+	f.run(getKeyDGS(dgs, t))
+}
+
+func TestDualStackNodeAddresses(t *testing.T) {
+	f := newDGSFixture(t)
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 1, testhelpers.PodSpec)
+	dgsCol.Spec.AddressTypePriority = []corev1.NodeAddressType{corev1.NodeInternalIP, corev1.NodeExternalIP}
+	dgs := shared.NewDedicatedGameServer(dgsCol, testhelpers.PodSpec)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "node1"},
+				{Type: corev1.NodeExternalIP, Address: "2001:db8::1"},
+				{Type: corev1.NodeExternalIP, Address: "1.2.3.4"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.4"},
+			},
+		},
+	}
+
+	pod := shared.NewPod(dgs, shared.APIDetails{APIServerURL: "", TokenSigningKey: ""})
+	pod.Spec.NodeName = node.Name
+
+	f.nodeLister = append(f.nodeLister, node)
+	f.k8sObjects = append(f.k8sObjects, node)
+
+	f.podLister = append(f.podLister, pod)
+	f.k8sObjects = append(f.k8sObjects, pod)
+
+	f.dgsLister = append(f.dgsLister, dgs)
+	f.dgsObjects = append(f.dgsObjects, dgs)
+
+	f.expectUpdatePodAction(pod, nil)
+	f.expectUpdateDGSAction(dgs, func(actual runtime.Object) {
+		d := actual.(*dgsv1alpha1.DedicatedGameServer)
+		assert.Equal(t, "10.0.0.4", d.Status.PublicIP)
+		assert.Equal(t, "2001:db8::1", d.Status.PublicIPv6)
+		assert.Equal(t, []dgsv1alpha1.DGSAddress{
+			{Type: corev1.NodeHostName, Address: "node1"},
+			{Type: corev1.NodeExternalIP, Address: "2001:db8::1", IPFamily: dgsv1alpha1.DGSIPv6},
+			{Type: corev1.NodeExternalIP, Address: "1.2.3.4", IPFamily: dgsv1alpha1.DGSIPv4},
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.4", IPFamily: dgsv1alpha1.DGSIPv4},
+		}, d.Status.Addresses)
+	})
+
+	f.run(getKeyDGS(dgs, t))
+}
+
+func TestHasDGSChanged(t *testing.T) {
+	f := newDGSFixture(t)
+	testController, _, _ := f.newDedicatedGameServerController()
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 1, testhelpers.PodSpec)
+	oldDGS := shared.NewDedicatedGameServer(dgsCol, testhelpers.PodSpec)
+	oldDGS.Status.Addresses = []dgsv1alpha1.DGSAddress{{Type: corev1.NodeExternalIP, Address: "1.2.3.4", IPFamily: dgsv1alpha1.DGSIPv4}}
+	oldDGS.Status.Ports = []dgsv1alpha1.DGSPort{{Name: "game", Protocol: corev1.ProtocolUDP, ContainerPort: 7777, HostPort: 20001, ExposureMode: dgsv1alpha1.DGSPortHostPort}}
+
+	assert.False(t, testController.hasDGSChanged(oldDGS, oldDGS.DeepCopy()))
+
+	newDGS := oldDGS.DeepCopy()
+	newDGS.Status.Addresses[0].Address = "5.6.7.8"
+	assert.True(t, testController.hasDGSChanged(oldDGS, newDGS), "A changed address should trigger a sync")
+
+	newDGS = oldDGS.DeepCopy()
+	newDGS.Status.Addresses = append(newDGS.Status.Addresses, dgsv1alpha1.DGSAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.4", IPFamily: dgsv1alpha1.DGSIPv4})
+	assert.True(t, testController.hasDGSChanged(oldDGS, newDGS), "An added address should trigger a sync")
+
+	newDGS = oldDGS.DeepCopy()
+	newDGS.Status.Ports[0].HostPort = 20002
+	assert.True(t, testController.hasDGSChanged(oldDGS, newDGS), "A changed port should trigger a sync")
+
+	newDGS = oldDGS.DeepCopy()
+	newDGS.Status.Ports = nil
+	assert.True(t, testController.hasDGSChanged(oldDGS, newDGS), "A removed port should trigger a sync")
+}
+
+func TestNodeAddressChangeEnqueuesDGSs(t *testing.T) {
+	f := newDGSFixture(t)
+
+	dgsCol := shared.NewDedicatedGameServerCollection("test", shared.GameNamespace, 3, testhelpers.PodSpec)
+	for _, nodeName := range []string{"node1", "node1", "node2"} {
+		dgs := shared.NewDedicatedGameServer(dgsCol, testhelpers.PodSpec)
+		dgs.Status.NodeName = nodeName
+		f.dgsLister = append(f.dgsLister, dgs)
+	}
+
+	testController, _, _ := f.newDedicatedGameServerController()
+
+	oldNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", ResourceVersion: "1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeExternalIP, Address: "1.2.3.4"}},
+		},
+	}
+	newNode := oldNode.DeepCopy()
+	newNode.ResourceVersion = "2"
+	newNode.Status.Addresses[0].Address = "5.6.7.8"
+
+	assert.False(t, testController.haveNodeAddressesChanged(oldNode, oldNode.DeepCopy()))
+	assert.True(t, testController.haveNodeAddressesChanged(oldNode, newNode))
+
+	testController.handleNode(newNode)
+
+	queue := testController.controllerHelper.Workqueue
+	err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return queue.Len() == 2, nil
+	})
+	assert.NoError(t, err, "both DGSs on node1 should have been enqueued")
+}
+
 // filterInformerActionsDGS filters list and watch actions for testing resources.
 // Since list and watch don't change resource state we can filter it to lower
 // noise level in our tests.
@@ -105,25 +105,18 @@ func (c *Controller) addDGSColReplicas(dgsCol *dgsv1alpha1.DedicatedGameServerCo
 	for i := 0; i < increaseCount; i++ {
 		dgs := shared.NewDedicatedGameServer(dgsCol, dgsCol.Spec.Template)
 		// if we want to expose ports for this DGS
+		var assigned []int32
 		if dgsCol.Spec.PortsToExpose != nil {
-			// for each container on the pod
-			for k := 0; k < len(dgs.Spec.Template.Containers); k++ {
-				// assign random port for each port request
-				for j := 0; j < len(dgs.Spec.Template.Containers[k].Ports); j++ {
-					// if we want to expose this specific ContainerPort
-					if shared.SliceContains(dgsCol.Spec.PortsToExpose, dgs.Spec.Template.Containers[k].Ports[j].ContainerPort) {
-						hostport, errPort := c.portRegistry.GetNewPort() //get a random port
-						if errPort != nil {
-							return errPort
-						}
-						dgs.Spec.Template.Containers[k].Ports[j].HostPort = hostport
-					}
-				}
+			var err error
+			if assigned, err = c.portRegistry.AssignHostPorts(dgs); err != nil {
+				c.portRegistry.DeregisterServerPorts(assigned)
+				return err
 			}
 		}
 
 		_, err := c.dgsClient.AzuregamingV1alpha1().DedicatedGameServers(dgsCol.Namespace).Create(dgs)
 		if err != nil {
+			c.portRegistry.DeregisterServerPorts(assigned)
 			return err
 		}
 	}
@@ -0,0 +1,162 @@
+package notifications
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// EventType is a lifecycle transition of a DedicatedGameServer that subscribers can be notified about
+type EventType string
+
+// the lifecycle transitions
+const (
+	// EventHealthy is sent when the Health of a DedicatedGameServer becomes Healthy
+	EventHealthy EventType = "Healthy"
+	// EventFailed is sent when the Health of a DedicatedGameServer becomes Failed
+	EventFailed EventType = "Failed"
+	// EventAssigned is sent when the state of a DedicatedGameServer becomes Assigned
+	EventAssigned EventType = "Assigned"
+	// EventPostMatch is sent when the state of a DedicatedGameServer becomes PostMatch
+	EventPostMatch EventType = "PostMatch"
+	// EventDeleted is sent when a DedicatedGameServer is deleted
+	EventDeleted EventType = "Deleted"
+)
+
+var allEvents = []EventType{EventHealthy, EventFailed, EventAssigned, EventPostMatch, EventDeleted}
+
+const (
+	defaultMaxRetries = 5
+	defaultTimeout    = 10 * time.Second
+)
+
+// Config contains the webhook subscriptions
+type Config struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// Subscription is a webhook endpoint and the events it is notified about
+type Subscription struct {
+	// Name identifies the subscription in the logs
+	Name string `json:"name"`
+	// URL is the endpoint that the events are POSTed to
+	URL string `json:"url"`
+	// Events are the event types that are sent, all of them if empty
+	Events []EventType `json:"events,omitempty"`
+	// Namespaces and Collections restrict the events to the DedicatedGameServers of these namespaces
+	// and DedicatedGameServerCollections, all of them if empty
+	Namespaces  []string `json:"namespaces,omitempty"`
+	Collections []string `json:"collections,omitempty"`
+	// SecretFile is the file (e.g. a mounted Secret) that contains the key that signs the requests, which are not signed if it is empty
+	SecretFile string `json:"secretFile,omitempty"`
+	// MaxRetries is the number of times that a failed delivery is retried, default is 5
+	MaxRetries *int `json:"maxRetries,omitempty"`
+	// Timeout is the timeout of each request (e.g. "5s"), default is 10s
+	Timeout string `json:"timeout,omitempty"`
+
+	secret     []byte
+	maxRetries int
+	timeout    time.Duration
+}
+
+// LoadConfig reads the webhook subscriptions from the YAML or JSON file and validates them
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var config Config
+	if err := yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(&config); err != nil {
+		return nil, fmt.Errorf("cannot parse notification config %s: %s", path, err.Error())
+	}
+	for i := range config.Subscriptions {
+		if err := config.Subscriptions[i].complete(); err != nil {
+			return nil, fmt.Errorf("invalid subscription %d (%s) in %s: %s", i, config.Subscriptions[i].Name, path, err.Error())
+		}
+	}
+	return &config, nil
+}
+
+// complete validates the subscription and sets its defaults
+func (s *Subscription) complete() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	for _, event := range s.Events {
+		if !isKnownEvent(event) {
+			return fmt.Errorf("unknown event %s", event)
+		}
+	}
+
+	s.maxRetries = defaultMaxRetries
+	if s.MaxRetries != nil {
+		if *s.MaxRetries < 0 {
+			return fmt.Errorf("maxRetries must not be negative")
+		}
+		s.maxRetries = *s.MaxRetries
+	}
+
+	s.timeout = defaultTimeout
+	if s.Timeout != "" {
+		if s.timeout, err = time.ParseDuration(s.Timeout); err != nil || s.timeout <= 0 {
+			return fmt.Errorf("timeout must be a positive duration")
+		}
+	}
+
+	if s.SecretFile != "" {
+		secret, err := ioutil.ReadFile(s.SecretFile)
+		if err != nil {
+			return err
+		}
+		s.secret = []byte(strings.TrimSpace(string(secret)))
+		if len(s.secret) == 0 {
+			return fmt.Errorf("secretFile %s is empty", s.SecretFile)
+		}
+	}
+	return nil
+}
+
+func isKnownEvent(event EventType) bool {
+	for _, known := range allEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the subscription is interested in the event of the DedicatedGameServer
+func (s *Subscription) matches(event EventType, namespace string, collection string) bool {
+	return (len(s.Events) == 0 || containsEvent(s.Events, event)) &&
+		(len(s.Namespaces) == 0 || containsString(s.Namespaces, namespace)) &&
+		(len(s.Collections) == 0 || containsString(s.Collections, collection))
+}
+
+func containsEvent(events []EventType, event EventType) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
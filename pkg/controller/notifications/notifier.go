@@ -0,0 +1,241 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	logrus "github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// the HTTP headers of the webhook requests
+const (
+	EventHeader     = "X-DGS-Event"
+	DeliveryHeader  = "X-DGS-Delivery"
+	TimestampHeader = "X-DGS-Timestamp"
+	// SignatureHeader contains "sha256=" followed by the hex encoded HMAC-SHA256 of the timestamp, a "." and the body,
+	// signed with the secret of the subscription
+	SignatureHeader = "X-DGS-Signature"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 5 * time.Minute
+)
+
+// Event is the body of a webhook request
+type Event struct {
+	// ID is the same for all the deliveries (and retries) of the event, so subscribers can discard duplicates
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	Timestamp  time.Time `json:"timestamp"`
+	Namespace  string    `json:"namespace"`
+	Name       string    `json:"name"`
+	Collection string    `json:"collection,omitempty"`
+	// DedicatedGameServer is the DedicatedGameServer after the transition
+	DedicatedGameServer *dgsv1alpha1.DedicatedGameServer `json:"dedicatedGameServer"`
+}
+
+// delivery is an event that is sent to a subscription
+type delivery struct {
+	subscription *Subscription
+	event        *Event
+	body         []byte
+}
+
+// Notifier detects the lifecycle transitions of the DedicatedGameServers and delivers them to the webhook subscriptions
+// A nil Notifier ignores all the transitions
+type Notifier struct {
+	subscriptions []Subscription
+	client        *http.Client
+	// queue contains the pending deliveries, failed deliveries are retried with exponential backoff
+	queue  workqueue.RateLimitingInterface
+	logger *logrus.Logger
+}
+
+// NewNotifier returns a Notifier for the subscriptions of the config
+func NewNotifier(config *Config) *Notifier {
+	return newNotifier(config, workqueue.NewItemExponentialFailureRateLimiter(retryBaseDelay, retryMaxDelay))
+}
+
+func newNotifier(config *Config, rateLimiter workqueue.RateLimiter) *Notifier {
+	return &Notifier{
+		subscriptions: config.Subscriptions,
+		client:        &http.Client{},
+		queue:         workqueue.NewNamedRateLimitingQueue(rateLimiter, "DedicatedGameServerNotifications"),
+		logger:        shared.Logger(),
+	}
+}
+
+// Transitions returns the lifecycle transitions between the two versions of the DedicatedGameServer
+func Transitions(oldDGS, newDGS *dgsv1alpha1.DedicatedGameServer) []EventType {
+	var events []EventType
+	if oldDGS.Status.Health != newDGS.Status.Health {
+		switch newDGS.Status.Health {
+		case dgsv1alpha1.DGSHealthy:
+			events = append(events, EventHealthy)
+		case dgsv1alpha1.DGSFailed:
+			events = append(events, EventFailed)
+		}
+	}
+	if oldDGS.Status.DGSState != newDGS.Status.DGSState {
+		switch newDGS.Status.DGSState {
+		case dgsv1alpha1.DGSAssigned:
+			events = append(events, EventAssigned)
+		case dgsv1alpha1.DGSPostMatch:
+			events = append(events, EventPostMatch)
+		}
+	}
+	return events
+}
+
+// OnUpdate queues the deliveries of the lifecycle transitions of the updated DedicatedGameServer
+func (n *Notifier) OnUpdate(oldDGS, newDGS *dgsv1alpha1.DedicatedGameServer) {
+	if n == nil {
+		return
+	}
+	for _, eventType := range Transitions(oldDGS, newDGS) {
+		n.notify(eventType, newDGS)
+	}
+}
+
+// OnDelete queues the deliveries of the deletion of the DedicatedGameServer
+func (n *Notifier) OnDelete(dgs *dgsv1alpha1.DedicatedGameServer) {
+	if n == nil {
+		return
+	}
+	n.notify(EventDeleted, dgs)
+}
+
+func (n *Notifier) notify(eventType EventType, dgs *dgsv1alpha1.DedicatedGameServer) {
+	event := &Event{
+		ID:                  fmt.Sprintf("%s-%s-%s", dgs.UID, dgs.ResourceVersion, eventType),
+		Type:                eventType,
+		Timestamp:           time.Now().UTC(),
+		Namespace:           dgs.Namespace,
+		Name:                dgs.Name,
+		Collection:          dgs.Labels[shared.LabelDedicatedGameServerCollectionName],
+		DedicatedGameServer: dgs,
+	}
+
+	var body []byte
+	for i := range n.subscriptions {
+		subscription := &n.subscriptions[i]
+		if !subscription.matches(eventType, event.Namespace, event.Collection) {
+			continue
+		}
+		if body == nil {
+			var err error
+			if body, err = json.Marshal(event); err != nil {
+				n.logger.WithField("Name", dgs.Name).Errorf("Cannot marshal %s notification: %s", eventType, err.Error())
+				return
+			}
+		}
+		n.logger.WithFields(logrus.Fields{"Name": dgs.Name, "Event": eventType, "Subscription": subscription.Name}).Info("Queueing DedicatedGameServer notification")
+		n.queue.Add(&delivery{subscription: subscription, event: event, body: body})
+	}
+}
+
+// Run starts the workers that deliver the notifications and blocks until stopCh is closed
+func (n *Notifier) Run(workers int, stopCh <-chan struct{}) {
+	defer n.queue.ShutDown()
+	n.logger.Infof("Starting %d notification workers for %d subscriptions", workers, len(n.subscriptions))
+	for i := 0; i < workers; i++ {
+		go wait.Until(func() {
+			for n.processNextDelivery() {
+			}
+		}, time.Second, stopCh)
+	}
+	<-stopCh
+	n.logger.Info("Shutting down notification workers")
+}
+
+func (n *Notifier) processNextDelivery() bool {
+	item, shutdown := n.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer n.queue.Done(item)
+
+	d := item.(*delivery)
+	logger := n.logger.WithFields(logrus.Fields{"Name": d.event.Name, "Event": d.event.Type, "Subscription": d.subscription.Name})
+
+	err := n.deliver(d)
+	if err == nil {
+		n.queue.Forget(item)
+		return true
+	}
+
+	attempts := n.queue.NumRequeues(item)
+	if _, permanent := err.(permanentError); permanent || attempts >= d.subscription.maxRetries {
+		logger.Errorf("Dropping notification after %d attempt(s): %s", attempts+1, err.Error())
+		n.queue.Forget(item)
+		return true
+	}
+	logger.Warnf("Notification delivery failed, will retry: %s", err.Error())
+	n.queue.AddRateLimited(item)
+	return true
+}
+
+// permanentError is a delivery error that is not retried, e.g. the endpoint rejected the request
+type permanentError struct {
+	error
+}
+
+// deliver POSTs the event to the endpoint of the subscription
+func (n *Notifier) deliver(d *delivery) error {
+	req, err := http.NewRequest(http.MethodPost, d.subscription.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return permanentError{err}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(d.event.Type))
+	req.Header.Set(DeliveryHeader, d.event.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	if len(d.subscription.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(d.subscription.secret, timestamp, d.body))
+	}
+
+	client := *n.client
+	client.Timeout = d.subscription.timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	default:
+		return permanentError{fmt.Errorf("endpoint returned %d", resp.StatusCode)}
+	}
+}
+
+// Sign returns the value of the SignatureHeader for the body of a request that is sent at the timestamp
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
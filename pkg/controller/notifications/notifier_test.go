@@ -0,0 +1,213 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func newNotifiedDGS(health dgsv1alpha1.DGSHealth, state dgsv1alpha1.DGSState) *dgsv1alpha1.DedicatedGameServer {
+	return &dgsv1alpha1.DedicatedGameServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "dgs1",
+			Namespace:       "default",
+			UID:             "uid1",
+			ResourceVersion: "42",
+			Labels:          map[string]string{shared.LabelDedicatedGameServerCollectionName: "ctf"},
+		},
+		Status: dgsv1alpha1.DedicatedGameServerStatus{Health: health, DGSState: state},
+	}
+}
+
+// webhookRecorder is a webhook endpoint that returns the designated status codes, in order, and records the requests
+type webhookRecorder struct {
+	mutex    sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (wr *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+	wr.requests = append(wr.requests, r)
+	wr.bodies = append(wr.bodies, body)
+	code := http.StatusOK
+	if len(wr.codes) > 0 {
+		code, wr.codes = wr.codes[0], wr.codes[1:]
+	}
+	w.WriteHeader(code)
+}
+
+func (wr *webhookRecorder) count() int {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+	return len(wr.requests)
+}
+
+func newTestNotifier(t *testing.T, subscriptions ...Subscription) *Notifier {
+	for i := range subscriptions {
+		assert.NoError(t, subscriptions[i].complete())
+	}
+	return newNotifier(&Config{Subscriptions: subscriptions}, workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond))
+}
+
+// waitForRequests waits until the endpoint has received the designated number of requests and the queue is empty
+func waitForRequests(t *testing.T, notifier *Notifier, recorder *webhookRecorder, count int) {
+	for i := 0; i < 500; i++ {
+		if recorder.count() >= count && notifier.queue.Len() == 0 {
+			// make sure that no more requests arrive
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, count, recorder.count())
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d webhook requests, got %d", count, recorder.count())
+}
+
+func TestTransitions(t *testing.T) {
+	assert.Equal(t, []EventType{EventHealthy}, Transitions(newNotifiedDGS(dgsv1alpha1.DGSCreating, ""), newNotifiedDGS(dgsv1alpha1.DGSHealthy, "")))
+	assert.Equal(t, []EventType{EventFailed, EventPostMatch},
+		Transitions(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSRunning), newNotifiedDGS(dgsv1alpha1.DGSFailed, dgsv1alpha1.DGSPostMatch)))
+	assert.Equal(t, []EventType{EventAssigned}, Transitions(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle), newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSAssigned)))
+	assert.Empty(t, Transitions(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSAssigned), newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSRunning)))
+	assert.Empty(t, Transitions(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle), newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle)))
+}
+
+func TestNotifierDeliversSignedEvents(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	secretFile := filepath.Join(os.TempDir(), "notifier-test-secret")
+	assert.NoError(t, ioutil.WriteFile(secretFile, []byte("s3cret\n"), 0600))
+	defer os.Remove(secretFile)
+
+	notifier := newTestNotifier(t,
+		Subscription{Name: "all", URL: server.URL + "/all", SecretFile: secretFile},
+		Subscription{Name: "failures", URL: server.URL + "/failures", Events: []EventType{EventFailed}},
+		Subscription{Name: "other-collection", URL: server.URL + "/other", Collections: []string{"conquest"}},
+	)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go notifier.Run(1, stopCh)
+
+	notifier.OnUpdate(newNotifiedDGS(dgsv1alpha1.DGSCreating, dgsv1alpha1.DGSIdle), newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle))
+	waitForRequests(t, notifier, recorder, 1)
+
+	r := recorder.requests[0]
+	assert.Equal(t, "/all", r.URL.Path)
+	assert.Equal(t, "Healthy", r.Header.Get(EventHeader))
+	assert.Equal(t, "uid1-42-Healthy", r.Header.Get(DeliveryHeader))
+	assert.Equal(t, Sign([]byte("s3cret"), r.Header.Get(TimestampHeader), recorder.bodies[0]), r.Header.Get(SignatureHeader))
+
+	var event Event
+	assert.NoError(t, json.Unmarshal(recorder.bodies[0], &event))
+	assert.Equal(t, EventHealthy, event.Type)
+	assert.Equal(t, "dgs1", event.Name)
+	assert.Equal(t, "ctf", event.Collection)
+	assert.Equal(t, dgsv1alpha1.DGSHealthy, event.DedicatedGameServer.Status.Health)
+
+	notifier.OnDelete(newNotifiedDGS(dgsv1alpha1.DGSFailed, dgsv1alpha1.DGSIdle))
+	waitForRequests(t, notifier, recorder, 2)
+	assert.Equal(t, "Deleted", recorder.requests[1].Header.Get(EventHeader))
+	assert.NotEmpty(t, recorder.requests[1].Header.Get(SignatureHeader), "Signed subscriptions should carry a signature")
+}
+
+func TestNotifierRetries(t *testing.T) {
+	recorder := &webhookRecorder{codes: []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK}}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	notifier := newTestNotifier(t, Subscription{Name: "flaky", URL: server.URL})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go notifier.Run(1, stopCh)
+
+	notifier.OnDelete(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle))
+	waitForRequests(t, notifier, recorder, 3)
+	assert.Equal(t, recorder.requests[0].Header.Get(DeliveryHeader), recorder.requests[2].Header.Get(DeliveryHeader),
+		"Retries should have the same delivery id")
+}
+
+func TestNotifierGivesUp(t *testing.T) {
+	recorder := &webhookRecorder{codes: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	maxRetries := 1
+	notifier := newTestNotifier(t,
+		Subscription{Name: "down", URL: server.URL + "/down", MaxRetries: &maxRetries},
+	)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go notifier.Run(1, stopCh)
+
+	// the delivery is attempted once and retried once
+	notifier.OnDelete(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle))
+	waitForRequests(t, notifier, recorder, 2)
+
+	// client errors are not retried
+	recorder.mutex.Lock()
+	recorder.codes = []int{http.StatusBadRequest}
+	recorder.mutex.Unlock()
+	notifier.OnDelete(newNotifiedDGS(dgsv1alpha1.DGSHealthy, dgsv1alpha1.DGSIdle))
+	waitForRequests(t, notifier, recorder, 3)
+}
+
+func TestNilNotifierIgnoresTransitions(t *testing.T) {
+	var notifier *Notifier
+	notifier.OnUpdate(newNotifiedDGS(dgsv1alpha1.DGSCreating, ""), newNotifiedDGS(dgsv1alpha1.DGSHealthy, ""))
+	notifier.OnDelete(newNotifiedDGS(dgsv1alpha1.DGSHealthy, ""))
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(os.TempDir(), "notifier-test-config.yaml")
+	defer os.Remove(path)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`subscriptions:
+- name: backend
+  url: https://backend.example.com/dgs
+  events: ["Assigned", "Failed"]
+  namespaces: ["title1"]
+  maxRetries: 0
+  timeout: 3s
+`), 0600))
+	config, err := LoadConfig(path)
+	if assert.NoError(t, err) && assert.Len(t, config.Subscriptions, 1) {
+		subscription := config.Subscriptions[0]
+		assert.Equal(t, 0, subscription.maxRetries)
+		assert.Equal(t, 3*time.Second, subscription.timeout)
+		assert.True(t, subscription.matches(EventAssigned, "title1", "ctf"))
+		assert.False(t, subscription.matches(EventHealthy, "title1", "ctf"))
+		assert.False(t, subscription.matches(EventAssigned, "title2", "ctf"))
+	}
+
+	for _, invalid := range []string{
+		"subscriptions:\n- url: https://backend.example.com\n",
+		"subscriptions:\n- name: a\n  url: backend.example.com\n",
+		"subscriptions:\n- name: a\n  url: https://backend.example.com\n  events: [Sleeping]\n",
+		"subscriptions:\n- name: a\n  url: https://backend.example.com\n  timeout: soon\n",
+		"subscriptions:\n- name: a\n  url: https://backend.example.com\n  secretFile: /does/not/exist\n",
+	} {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(invalid), 0600))
+		_, err := LoadConfig(path)
+		assert.Error(t, err, invalid)
+	}
+}
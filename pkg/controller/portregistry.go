@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
 	dgsclientset "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/client/clientset/versioned"
 	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
 
@@ -22,6 +24,7 @@ type PortRegistry struct {
 	Max               int32         // Maximum Port
 	portRequests      chan struct{} // buffered channel to store port requests
 	portResponses     chan int32    // buffered channel to store port responses (system returns the HostPorts)
+	mutex             sync.Mutex    // guards Ports and NextFreePortIndex, which are written by portProducer and DeregisterServerPorts
 }
 
 // NewPortRegistry initializes the IndexedDictionary that holds the port registry.
@@ -103,28 +106,31 @@ func (pr *PortRegistry) GetNewPort() (int32, error) {
 
 func (pr *PortRegistry) portProducer() {
 	for range pr.portRequests { //wait till a new request comes
+		pr.portResponses <- pr.registerNextFreePort()
+	}
+}
 
-		initialIndex := pr.NextFreePortIndex
-		for {
-			if !pr.Ports[pr.Indexes[pr.NextFreePortIndex]] {
-				//we found a port
-				port := pr.Indexes[pr.NextFreePortIndex]
-				pr.Ports[port] = true
-
-				pr.increaseNextFreePortIndex()
+// registerNextFreePort registers and returns the next free port, or -1 if there are no free ports
+func (pr *PortRegistry) registerNextFreePort() int32 {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
 
-				//port is set
-				pr.portResponses <- port
-				break
-			}
+	initialIndex := pr.NextFreePortIndex
+	for {
+		if !pr.Ports[pr.Indexes[pr.NextFreePortIndex]] {
+			//we found a port
+			port := pr.Indexes[pr.NextFreePortIndex]
+			pr.Ports[port] = true
 
 			pr.increaseNextFreePortIndex()
+			return port
+		}
 
-			if initialIndex == pr.NextFreePortIndex {
-				//we did a full loop - no empty ports
-				pr.portResponses <- -1
-				break
-			}
+		pr.increaseNextFreePortIndex()
+
+		if initialIndex == pr.NextFreePortIndex {
+			//we did a full loop - no empty ports
+			return -1
 		}
 	}
 }
@@ -135,8 +141,56 @@ func (pr *PortRegistry) Stop() {
 	close(pr.portResponses)
 }
 
+// AssignHostPorts assigns a new HostPort to each of the DedicatedGameServer's ContainerPorts that is included in PortsToExpose
+// and does not have a HostPort yet. It returns the assigned HostPorts, also on error, so that the caller can deregister them
+func (pr *PortRegistry) AssignHostPorts(dgs *dgsv1alpha1.DedicatedGameServer) ([]int32, error) {
+	assigned := make([]int32, 0)
+	// for each container on the pod
+	for k := 0; k < len(dgs.Spec.Template.Containers); k++ {
+		// assign random port for each port request
+		for j := 0; j < len(dgs.Spec.Template.Containers[k].Ports); j++ {
+			port := &dgs.Spec.Template.Containers[k].Ports[j]
+			// if we want to expose this specific ContainerPort
+			if port.HostPort == 0 && shared.SliceContains(dgs.Spec.PortsToExpose, port.ContainerPort) {
+				hostport, err := pr.GetNewPort() //get a random port
+				if err != nil {
+					return assigned, err
+				}
+				port.HostPort = hostport
+				assigned = append(assigned, hostport)
+			}
+		}
+	}
+	return assigned, nil
+}
+
+// NeedsHostPorts returns true if any of the DedicatedGameServer's PortsToExpose has not been assigned a HostPort
+func NeedsHostPorts(dgs *dgsv1alpha1.DedicatedGameServer) bool {
+	for _, container := range dgs.Spec.Template.Containers {
+		for _, port := range container.Ports {
+			if port.HostPort == 0 && shared.SliceContains(dgs.Spec.PortsToExpose, port.ContainerPort) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetHostPorts returns the HostPorts that have been assigned to the DedicatedGameServer's PortsToExpose
+func GetHostPorts(dgs *dgsv1alpha1.DedicatedGameServer) []int32 {
+	hostPorts := make([]int32, 0)
+	for _, port := range shared.GetDGSPorts(dgs) {
+		if port.ExposureMode == dgsv1alpha1.DGSPortHostPort {
+			hostPorts = append(hostPorts, port.HostPort)
+		}
+	}
+	return hostPorts
+}
+
 // DeregisterServerPorts deregisters all ports
 func (pr *PortRegistry) DeregisterServerPorts(ports []int32) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
 	for i := 0; i < len(ports); i++ {
 		pr.Ports[ports[i]] = false
 	}
@@ -213,6 +213,56 @@ func TestPortRegistry(t *testing.T) {
 
 }
 
+func TestAssignHostPortsReturnsTheAssignedPorts(t *testing.T) {
+	portRegistry, err := NewPortRegistry(fake.NewSimpleClientset(), 20000, 20001, metav1.NamespaceAll)
+	if err != nil {
+		t.Fatalf("Cannot initialize PortRegistry due to: %s", err.Error())
+	}
+	defer portRegistry.Stop()
+
+	// the first port has been assigned before
+	dgs := shared.NewDedicatedGameServerWithNoParent(shared.GameNamespace, "default1", corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "test",
+				Ports: []corev1.ContainerPort{{ContainerPort: 7777, HostPort: 20005}, {ContainerPort: 7778}, {ContainerPort: 7779}, {ContainerPort: 7780}},
+			},
+		},
+	}, []int32{7777, 7778, 7779, 7780})
+
+	// there are two free ports, so the third one cannot be assigned
+	assigned, err := portRegistry.AssignHostPorts(dgs)
+	if err == nil {
+		t.Error("Should return an error")
+	}
+	if len(assigned) != 2 || shared.SliceContains(assigned, 20005) {
+		t.Errorf("Only the two new ports should be returned, got %v", assigned)
+	}
+
+	portRegistry.DeregisterServerPorts(assigned)
+	verifyGameServerPortsDoNotExist(portRegistry, "default1", []int32{20000, 20001}, t)
+}
+
+func TestPortRegistryDeregistersConcurrently(t *testing.T) {
+	portRegistry, err := NewPortRegistry(fake.NewSimpleClientset(), 20000, 20010, metav1.NamespaceAll)
+	if err != nil {
+		t.Fatalf("Cannot initialize PortRegistry due to: %s", err.Error())
+	}
+	defer portRegistry.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			portRegistry.DeregisterServerPorts([]int32{20000 + int32(i%11)})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		portRegistry.GetNewPort()
+	}
+	<-done
+}
+
 func verifyGameServerPortsExist(portRegistry *PortRegistry, serverName string, ports []int32, t *testing.T) {
 	for _, port := range ports {
 		valB, ok := portRegistry.Ports[port]
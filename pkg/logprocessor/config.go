@@ -0,0 +1,106 @@
+// Package logprocessor contains the log processor, which reports the Health, state and players of a DedicatedGameServer
+// by matching the lines of the game server log against a set of regex rules
+package logprocessor
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Action is the operation that a rule performs when a log line matches its pattern
+type Action string
+
+// the rule actions
+const (
+	// ActionSetHealth sets the Health of the DedicatedGameServer to the value of the rule
+	ActionSetHealth Action = "setHealth"
+	// ActionSetState sets the state of the DedicatedGameServer to the value of the rule
+	ActionSetState Action = "setState"
+	// ActionIncrementPlayers adds one to the active players
+	ActionIncrementPlayers Action = "incrementPlayers"
+	// ActionDecrementPlayers removes one from the active players, the count does not go below zero
+	ActionDecrementPlayers Action = "decrementPlayers"
+	// ActionResetPlayers sets the active players to zero
+	ActionResetPlayers Action = "resetPlayers"
+	// ActionSetPlayers sets the active players to the value of the rule
+	ActionSetPlayers Action = "setPlayers"
+	// ActionSetLabel sets the label of the rule to its value, an empty value removes the label
+	ActionSetLabel Action = "setLabel"
+)
+
+// Config contains the rules of the log processor
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule maps the log lines that match a regular expression to an action
+// All the rules that match a line are applied, in order
+type Rule struct {
+	// Name identifies the rule in the logs
+	Name string `json:"name"`
+	// Pattern is the regular expression (RE2 syntax) that the log lines are matched against
+	Pattern string `json:"pattern"`
+	Action  Action `json:"action"`
+	// Value is the argument of the setHealth, setState, setPlayers and setLabel actions
+	// It can refer to the submatches of the pattern, e.g. $1 or ${players}
+	Value string `json:"value,omitempty"`
+	// Label is the label that the setLabel action sets
+	Label string `json:"label,omitempty"`
+
+	regexp *regexp.Regexp
+}
+
+// LoadConfig reads the rules from the YAML or JSON file and validates them
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var config Config
+	if err := yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(&config); err != nil {
+		return nil, fmt.Errorf("cannot parse log processor config %s: %s", path, err.Error())
+	}
+	if len(config.Rules) == 0 {
+		return nil, fmt.Errorf("log processor config %s has no rules", path)
+	}
+	for i := range config.Rules {
+		if err := config.Rules[i].complete(); err != nil {
+			return nil, fmt.Errorf("invalid rule %d (%s) in %s: %s", i, config.Rules[i].Name, path, err.Error())
+		}
+	}
+	return &config, nil
+}
+
+// complete validates the rule and compiles its pattern
+func (r *Rule) complete() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	var err error
+	if r.regexp, err = regexp.Compile(r.Pattern); err != nil {
+		return fmt.Errorf("pattern is not a valid regular expression: %s", err.Error())
+	}
+
+	switch r.Action {
+	case ActionSetHealth, ActionSetState, ActionSetPlayers:
+		if r.Value == "" {
+			return fmt.Errorf("value is required for the %s action", r.Action)
+		}
+	case ActionSetLabel:
+		if r.Label == "" {
+			return fmt.Errorf("label is required for the %s action", r.Action)
+		}
+	case ActionIncrementPlayers, ActionDecrementPlayers, ActionResetPlayers:
+	default:
+		return fmt.Errorf("unknown action %s", r.Action)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package logprocessor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	logrus "github.com/sirupsen/logrus"
+)
+
+// State is the progress of the log processor, which is saved to the state file so it survives restarts
+type State struct {
+	// Offset is the position in the tailed file after the last processed line
+	Offset int64 `json:"offset"`
+	// ActivePlayers is the player count that the increment, decrement and reset actions apply to
+	ActivePlayers int `json:"activePlayers"`
+}
+
+// Processor applies the rules to the log lines and reports the results via the SDK
+// It is not safe for concurrent use
+type Processor struct {
+	sdk    sdk.Interface
+	rules  []Rule
+	logger *logrus.Logger
+
+	// stateFile is the file that the State is saved to, the State is not saved if it is empty
+	stateFile string
+	state     State
+	// restored is true if the State was read from the state file, so the player count has to be reported again
+	restored bool
+
+	// Echo, if set, receives every processed line, so the log of the game server is not lost when it is piped to the processor
+	Echo io.Writer
+}
+
+// NewProcessor returns a processor for the rules of the Config
+// The State is read from the stateFile if it exists
+func NewProcessor(s sdk.Interface, config *Config, stateFile string) (*Processor, error) {
+	p := &Processor{
+		sdk:       s,
+		rules:     config.Rules,
+		logger:    shared.Logger(),
+		stateFile: stateFile,
+	}
+	if stateFile == "" {
+		return p, nil
+	}
+
+	data, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &p.state); err != nil {
+		return nil, fmt.Errorf("cannot parse state file %s: %s", stateFile, err.Error())
+	}
+	p.restored = true
+	return p, nil
+}
+
+// State returns the current State of the processor
+func (p *Processor) State() State {
+	return p.state
+}
+
+// Process applies the rules to the lines of the reader, until it is closed
+func (p *Processor) Process(r io.Reader) error {
+	p.restore()
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			p.ProcessLine(line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Tail applies the rules to the lines that are appended to the file, until the stop channel is closed
+// Processing starts at the Offset of the State. If the file is truncated or replaced (e.g. by log rotation), it starts again from the beginning
+func (p *Processor) Tail(path string, pollInterval time.Duration, stopCh <-chan struct{}) error {
+	p.restore()
+	defer p.saveState()
+
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+	var reader *bufio.Reader
+	// partial is the last line of the file, until its newline is written
+	partial := ""
+
+	for {
+		if file == nil {
+			var err error
+			if file, err = p.openAtOffset(path); err != nil {
+				return err
+			}
+			reader = bufio.NewReader(file)
+			partial = ""
+		}
+
+		line, err := reader.ReadString('\n')
+		if err == nil {
+			p.state.Offset += int64(len(partial) + len(line))
+			line, partial = partial+line, ""
+			p.ProcessLine(line)
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		partial += line
+
+		select {
+		case <-stopCh:
+			return nil
+		case <-time.After(pollInterval):
+		}
+
+		// reopen the file if it was truncated or replaced
+		current, err := os.Stat(path)
+		if err != nil {
+			// the file may be missing for a moment during a rotation
+			continue
+		}
+		opened, err := file.Stat()
+		if err != nil || !os.SameFile(opened, current) || current.Size() < p.state.Offset+int64(len(partial)) {
+			p.logger.Infof("File %s was truncated or replaced, processing it from the beginning", path)
+			file.Close()
+			file = nil
+			p.state.Offset = 0
+		}
+	}
+}
+
+// openAtOffset opens the file and seeks to the Offset of the State, or to the beginning if the file is smaller than the Offset
+func (p *Processor) openAtOffset(path string) (*os.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() < p.state.Offset {
+		p.state.Offset = 0
+	}
+	if _, err := file.Seek(p.state.Offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// ProcessLine applies all the rules that match the line, in order
+// Failed actions are logged, since the next log lines should still be processed
+func (p *Processor) ProcessLine(line string) {
+	if p.Echo != nil {
+		io.WriteString(p.Echo, line)
+		if !strings.HasSuffix(line, "\n") {
+			io.WriteString(p.Echo, "\n")
+		}
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	matched := false
+	for i := range p.rules {
+		rule := &p.rules[i]
+		match := rule.regexp.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		matched = true
+		value := string(rule.regexp.ExpandString(nil, rule.Value, line, match))
+		if err := p.apply(rule, value); err != nil {
+			p.logger.Errorf("Rule %s failed for line %q: %s", rule.Name, line, err.Error())
+		}
+	}
+	// the State has to be saved only when it may have changed, the lines that do not match can be processed again after a restart
+	if matched {
+		p.saveState()
+	}
+}
+
+// apply performs the action of the rule, with the value expanded from the matched line
+func (p *Processor) apply(rule *Rule, value string) error {
+	switch rule.Action {
+	case ActionSetHealth:
+		return p.sdk.Update(helpers.DGSUpdate{Health: &value})
+	case ActionSetState:
+		return p.sdk.SetState(dgsv1alpha1.DGSState(value))
+	case ActionSetLabel:
+		update := helpers.DGSUpdate{Labels: map[string]*string{rule.Label: nil}}
+		if value != "" {
+			update.Labels[rule.Label] = &value
+		}
+		return p.sdk.Update(update)
+	case ActionIncrementPlayers:
+		return p.setPlayers(p.state.ActivePlayers + 1)
+	case ActionDecrementPlayers:
+		if p.state.ActivePlayers == 0 {
+			return nil
+		}
+		return p.setPlayers(p.state.ActivePlayers - 1)
+	case ActionResetPlayers:
+		return p.setPlayers(0)
+	case ActionSetPlayers:
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return fmt.Errorf("%q is not a valid player count", value)
+		}
+		return p.setPlayers(count)
+	}
+	return fmt.Errorf("unknown action %s", rule.Action)
+}
+
+// setPlayers reports the absolute player count, so a failed report is corrected by the next one
+func (p *Processor) setPlayers(count int) error {
+	p.state.ActivePlayers = count
+	return p.sdk.SetPlayers(count)
+}
+
+// restore reports the player count of the restored State, since the reports before the restart may have failed
+func (p *Processor) restore() {
+	if !p.restored {
+		return
+	}
+	p.restored = false
+	if err := p.sdk.SetPlayers(p.state.ActivePlayers); err != nil {
+		p.logger.Errorf("Cannot report the restored player count: %s", err.Error())
+	}
+}
+
+// saveState writes the State to a temporary file and renames it to the state file, so it is never half-written
+func (p *Processor) saveState() {
+	if p.stateFile == "" {
+		return
+	}
+	data, err := json.Marshal(p.state)
+	if err != nil {
+		p.logger.Errorf("Cannot serialize the state: %s", err.Error())
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p.stateFile), filepath.Base(p.stateFile))
+	if err != nil {
+		p.logger.Errorf("Cannot save the state: %s", err.Error())
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p.stateFile)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		p.logger.Errorf("Cannot save the state: %s", err.Error())
+	}
+}
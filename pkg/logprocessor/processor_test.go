@@ -0,0 +1,204 @@
+package logprocessor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk/fake"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// the rule set of the OpenArena demo
+const openArenaConfig = "../../demos/openarena/logprocessor.yaml"
+
+func writeConfig(t *testing.T, dir string, content string) string {
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logprocessor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(openArenaConfig)
+	if assert.NoError(t, err) {
+		assert.Len(t, config.Rules, 5)
+	}
+
+	invalid := map[string]string{
+		"rules: []": "has no rules",
+		"rules:\n- name: r\n  pattern: '('\n  action: resetPlayers": "not a valid regular expression",
+		"rules:\n- name: r\n  pattern: x\n  action: jump":           "unknown action jump",
+		"rules:\n- name: r\n  pattern: x\n  action: setState":       "value is required",
+		"rules:\n- name: r\n  pattern: x\n  action: setLabel":       "label is required",
+		"rules:\n- pattern: x\n  action: resetPlayers":              "name is required",
+	}
+	for content, message := range invalid {
+		_, err := LoadConfig(writeConfig(t, dir, content))
+		if assert.Error(t, err, content) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
+
+func TestOpenArenaRules(t *testing.T) {
+	config, err := LoadConfig(openArenaConfig)
+	assert.NoError(t, err)
+	fakeSDK := fake.NewSDK("default", "openarena1")
+	p, err := NewProcessor(fakeSDK, config, "")
+	assert.NoError(t, err)
+	var echo bytes.Buffer
+	p.Echo = &echo
+
+	log := strings.Join([]string{
+		"Opening IP socket: 0.0.0.0:27960",
+		"ClientBegin: 0",
+		"ClientBegin: 1",
+		"ClientDisconnect: 0",
+		"ClientBegin: 2",
+	}, "\n")
+	assert.NoError(t, p.Process(strings.NewReader(log)))
+	status := fakeSDK.DedicatedGameServer().Status
+	assert.Equal(t, dgsv1alpha1.DGSHealthy, status.Health)
+	assert.Equal(t, dgsv1alpha1.DGSAssigned, status.DGSState)
+	assert.Equal(t, 2, status.ActivePlayers)
+	assert.Equal(t, log+"\n", echo.String())
+
+	assert.NoError(t, p.Process(strings.NewReader("AAS shutdown.\nClientDisconnect: 1\n")))
+	assert.Equal(t, 0, fakeSDK.DedicatedGameServer().Status.ActivePlayers)
+}
+
+func TestCapturedValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logprocessor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(writeConfig(t, dir, `
+rules:
+- name: players
+  pattern: 'players: (\d+)'
+  action: setPlayers
+  value: $1
+- name: map
+  pattern: 'loading map (?P<map>\w+)'
+  action: setLabel
+  label: map
+  value: ${map}
+- name: idle
+  pattern: 'match ended'
+  action: setLabel
+  label: map
+`))
+	assert.NoError(t, err)
+	fakeSDK := fake.NewSDK("default", "dgs1")
+	p, err := NewProcessor(fakeSDK, config, "")
+	assert.NoError(t, err)
+
+	p.ProcessLine("players: 12\r\n")
+	p.ProcessLine("loading map q3dm17")
+	dgs := fakeSDK.DedicatedGameServer()
+	assert.Equal(t, 12, dgs.Status.ActivePlayers)
+	assert.Equal(t, "q3dm17", dgs.Labels["map"])
+
+	p.ProcessLine("match ended")
+	_, ok := fakeSDK.DedicatedGameServer().Labels["map"]
+	assert.False(t, ok)
+}
+
+func TestStateSurvivesRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logprocessor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	config, err := LoadConfig(openArenaConfig)
+	assert.NoError(t, err)
+	fakeSDK := fake.NewSDK("default", "openarena1")
+	p, err := NewProcessor(fakeSDK, config, stateFile)
+	assert.NoError(t, err)
+	assert.NoError(t, p.Process(strings.NewReader("ClientBegin: 0\nClientBegin: 1\n")))
+
+	// the report of the last player fails, the restarted processor reports the count again
+	fakeSDK.SetError(assert.AnError)
+	assert.NoError(t, p.Process(strings.NewReader("ClientBegin: 2\n")))
+	fakeSDK.SetError(nil)
+	assert.Equal(t, 2, fakeSDK.DedicatedGameServer().Status.ActivePlayers)
+
+	p, err = NewProcessor(fakeSDK, config, stateFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, p.State().ActivePlayers)
+	assert.NoError(t, p.Process(strings.NewReader("")))
+	assert.Equal(t, 3, fakeSDK.DedicatedGameServer().Status.ActivePlayers)
+	assert.NoError(t, p.Process(strings.NewReader("ClientDisconnect: 0\n")))
+	assert.Equal(t, 2, fakeSDK.DedicatedGameServer().Status.ActivePlayers)
+}
+
+func TestTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logprocessor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	logFile := filepath.Join(dir, "games.log")
+	stateFile := filepath.Join(dir, "state.json")
+	assert.NoError(t, ioutil.WriteFile(logFile, []byte("ClientBegin: 0\n"), 0644))
+
+	config, err := LoadConfig(openArenaConfig)
+	assert.NoError(t, err)
+	fakeSDK := fake.NewSDK("default", "openarena1")
+
+	tail := func() (*Processor, chan struct{}, chan struct{}) {
+		p, err := NewProcessor(fakeSDK, config, stateFile)
+		assert.NoError(t, err)
+		stopCh, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			assert.NoError(t, p.Tail(logFile, 5*time.Millisecond, stopCh))
+			close(done)
+		}()
+		return p, stopCh, done
+	}
+	appendLog := func(content string) {
+		file, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+		assert.NoError(t, err)
+		_, err = file.WriteString(content)
+		assert.NoError(t, err)
+		file.Close()
+	}
+	waitForPlayers := func(count int) {
+		err := wait.Poll(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return fakeSDK.DedicatedGameServer().Status.ActivePlayers == count, nil
+		})
+		assert.NoError(t, err, "Timed out waiting for %d players", count)
+	}
+
+	_, stopCh, done := tail()
+	waitForPlayers(1)
+	// the line is processed once its newline is written
+	appendLog("ClientBegin: 1")
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, fakeSDK.DedicatedGameServer().Status.ActivePlayers)
+	appendLog("\nClientBegin: 2\n")
+	waitForPlayers(3)
+	close(stopCh)
+	<-done
+
+	// the restarted processor continues after the processed lines
+	p, stopCh, done := tail()
+	appendLog("ClientDisconnect: 2\n")
+	waitForPlayers(2)
+
+	// the truncated file is processed from the beginning
+	assert.NoError(t, ioutil.WriteFile(logFile, []byte("AAS shutdown.\n"), 0644))
+	waitForPlayers(0)
+	close(stopCh)
+	<-done
+	assert.Equal(t, State{Offset: int64(len("AAS shutdown.\n")), ActivePlayers: 0}, p.State())
+}
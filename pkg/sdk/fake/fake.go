@@ -0,0 +1,211 @@
+// Package fake contains an in-memory implementation of the SDK, for the unit tests of game servers
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ sdk.Interface = &SDK{}
+
+// SDK applies the operations to an in-memory DedicatedGameServer instead of calling the API Server
+// WatchSelf functions are called synchronously with each change
+type SDK struct {
+	mutex    sync.Mutex
+	dgs      *dgsv1alpha1.DedicatedGameServer
+	players  map[string]bool
+	watchers []func(*dgsv1alpha1.DedicatedGameServer)
+	closed   bool
+	err      error
+}
+
+// NewSDK returns a fake SDK for a Creating DedicatedGameServer with the designated name and namespace
+func NewSDK(namespace string, name string) *SDK {
+	dgs := &dgsv1alpha1.DedicatedGameServer{}
+	dgs.Namespace = namespace
+	dgs.Name = name
+	dgs.ResourceVersion = "1"
+	dgs.Status.Health = dgsv1alpha1.DGSCreating
+	return &SDK{dgs: dgs, players: make(map[string]bool)}
+}
+
+// DedicatedGameServer returns a copy of the in-memory DedicatedGameServer
+func (s *SDK) DedicatedGameServer() *dgsv1alpha1.DedicatedGameServer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dgs.DeepCopy()
+}
+
+// SetError sets the error that all the operations return, nil makes them succeed again
+func (s *SDK) SetError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
+
+// Modify applies the function to the in-memory DedicatedGameServer (e.g. to simulate a change by a matchmaker) and notifies the watchers
+func (s *SDK) Modify(f func(*dgsv1alpha1.DedicatedGameServer)) {
+	s.update(func() error {
+		f(s.dgs)
+		return nil
+	})
+}
+
+// update applies the function under the lock, then notifies the watchers of the change
+func (s *SDK) update(f func() error) error {
+	s.mutex.Lock()
+	if s.err != nil {
+		s.mutex.Unlock()
+		return s.err
+	}
+	if err := f(); err != nil {
+		s.mutex.Unlock()
+		return err
+	}
+	version, _ := strconv.Atoi(s.dgs.ResourceVersion)
+	s.dgs.ResourceVersion = strconv.Itoa(version + 1)
+	var watchers []func(*dgsv1alpha1.DedicatedGameServer)
+	if !s.closed {
+		watchers = s.watchers
+	}
+	dgs := s.dgs.DeepCopy()
+	s.mutex.Unlock()
+
+	for _, watcher := range watchers {
+		watcher(dgs.DeepCopy())
+	}
+	return nil
+}
+
+// Ready marks the DedicatedGameServer as Healthy
+func (s *SDK) Ready() error {
+	return s.update(func() error {
+		s.dgs.Status.Health = dgsv1alpha1.DGSHealthy
+		return nil
+	})
+}
+
+// Heartbeat sets the LastHeartbeat of the DedicatedGameServer to the current time
+func (s *SDK) Heartbeat() error {
+	return s.update(func() error {
+		now := metav1.Now()
+		s.dgs.Status.LastHeartbeat = &now
+		return nil
+	})
+}
+
+// SetState sets the state of the DedicatedGameServer
+func (s *SDK) SetState(state dgsv1alpha1.DGSState) error {
+	return s.update(func() error {
+		if !state.IsValid() {
+			return fmt.Errorf("Wrong value for serverState: %s", state)
+		}
+		s.dgs.Status.DGSState = state
+		return nil
+	})
+}
+
+// SetPlayers sets the number of active players
+func (s *SDK) SetPlayers(count int) error {
+	return s.update(func() error {
+		if count < 0 {
+			return fmt.Errorf("Wrong value for playerCount: %d", count)
+		}
+		s.players = make(map[string]bool)
+		s.dgs.Status.ActivePlayers = count
+		return nil
+	})
+}
+
+// PlayerConnect adds the player to the active players
+func (s *SDK) PlayerConnect(playerID string) error {
+	return s.update(func() error {
+		if !s.players[playerID] {
+			s.players[playerID] = true
+			s.dgs.Status.ActivePlayers = len(s.players)
+		}
+		return nil
+	})
+}
+
+// PlayerDisconnect removes the player from the active players
+func (s *SDK) PlayerDisconnect(playerID string) error {
+	return s.update(func() error {
+		if s.players[playerID] {
+			delete(s.players, playerID)
+			s.dgs.Status.ActivePlayers = len(s.players)
+		}
+		return nil
+	})
+}
+
+// Update applies the non-nil fields of the update
+func (s *SDK) Update(update helpers.DGSUpdate) error {
+	return s.update(func() error {
+		if update.ActivePlayers != nil && *update.ActivePlayers < 0 {
+			return fmt.Errorf("Wrong value for playerCount: %d", *update.ActivePlayers)
+		}
+		if update.Health != nil {
+			s.dgs.Status.Health = dgsv1alpha1.DGSHealth(*update.Health)
+		}
+		if update.State != nil {
+			s.dgs.Status.DGSState = dgsv1alpha1.DGSState(*update.State)
+		}
+		if update.MarkedForDeletion != nil {
+			s.dgs.Status.MarkedForDeletion = *update.MarkedForDeletion
+		}
+		if update.ActivePlayers != nil {
+			s.players = make(map[string]bool)
+			s.dgs.Status.ActivePlayers = *update.ActivePlayers
+		}
+		for key, value := range update.Labels {
+			if value == nil {
+				delete(s.dgs.Labels, key)
+				continue
+			}
+			if s.dgs.Labels == nil {
+				s.dgs.Labels = make(map[string]string)
+			}
+			s.dgs.Labels[key] = *value
+		}
+		return nil
+	})
+}
+
+// Shutdown marks the DedicatedGameServer for deletion and stops the watchers
+func (s *SDK) Shutdown() error {
+	defer s.Close()
+	return s.update(func() error {
+		s.dgs.Status.MarkedForDeletion = true
+		return nil
+	})
+}
+
+// WatchSelf calls the function with the current DedicatedGameServer and then with every change
+func (s *SDK) WatchSelf(f func(*dgsv1alpha1.DedicatedGameServer)) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return fmt.Errorf("the SDK has been closed")
+	}
+	s.watchers = append(s.watchers, f)
+	dgs := s.dgs.DeepCopy()
+	s.mutex.Unlock()
+
+	f(dgs)
+	return nil
+}
+
+// Close stops the watchers
+func (s *SDK) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+}
@@ -0,0 +1,435 @@
+// Package sdk is the client that game servers use to report their Health, state and active players to the API Server
+// It uses the environment variables that the DedicatedGameServer controller sets on every container of a DedicatedGameServer Pod
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+)
+
+// the environment variables of the DedicatedGameServer Pod containers
+const (
+	EnvServerName      = "SERVER_NAME"
+	EnvServerNamespace = "SERVER_NAMESPACE"
+	EnvAPIServerURL    = "API_SERVER_URL"
+	EnvAPIServerCode   = "API_SERVER_CODE"
+	// EnvHeartbeatPeriod is only set if the DedicatedGameServer has a HealthCheck
+	EnvHeartbeatPeriod = "HEARTBEAT_PERIOD_SECONDS"
+)
+
+const (
+	defaultMaxRetries         = 5
+	defaultRetryBaseDelay     = 500 * time.Millisecond
+	defaultRetryMaxDelay      = 30 * time.Second
+	defaultRequestTimeout     = 10 * time.Second
+	defaultHealthPingInterval = 30 * time.Second
+	defaultWatchInterval      = 5 * time.Second
+)
+
+// Interface contains the operations of the SDK
+// Game servers should depend on it, so their unit tests can use the in-memory fake of the sdk/fake package
+type Interface interface {
+	// Ready marks the DedicatedGameServer as Healthy and starts the health pings
+	Ready() error
+	// Heartbeat reports that the game server is alive, so a DedicatedGameServer with a HealthCheck is not set to Failed
+	Heartbeat() error
+	// SetState sets the state of the DedicatedGameServer
+	SetState(state dgsv1alpha1.DGSState) error
+	// SetPlayers sets the number of active players, the players that were reported via PlayerConnect are forgotten
+	SetPlayers(count int) error
+	// PlayerConnect adds the player to the active players, connecting the same player twice has no effect
+	PlayerConnect(playerID string) error
+	// PlayerDisconnect removes the player from the active players, disconnecting an unknown player has no effect
+	PlayerDisconnect(playerID string) error
+	// Update applies several changes to the DedicatedGameServer in one request
+	Update(update helpers.DGSUpdate) error
+	// Shutdown marks the DedicatedGameServer for deletion and stops the background operations
+	// The DedicatedGameServer is deleted once it has no active players
+	Shutdown() error
+	// WatchSelf calls the function with the DedicatedGameServer every time it changes, until Shutdown or Close is called
+	WatchSelf(f func(*dgsv1alpha1.DedicatedGameServer)) error
+	// Close stops the background operations without modifying the DedicatedGameServer
+	Close()
+}
+
+// Config contains the settings of the SDK
+type Config struct {
+	// ServerName and Namespace identify the DedicatedGameServer
+	ServerName string
+	Namespace  string
+	// APIServerURL is the URL of the API Server and Code is the token of the DedicatedGameServer
+	APIServerURL string
+	Code         string
+	// MaxRetries is the number of times that a failed request is retried (network errors, 429 and 5xx status codes), default is 5
+	// A negative value disables the retries
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry, which doubles on each retry up to 30 seconds, default is 500ms
+	// A Retry-After header of the response takes precedence
+	RetryBaseDelay time.Duration
+	// RequestTimeout is the timeout of each request, default is 10s
+	RequestTimeout time.Duration
+	// HealthPingInterval is the interval of the health pings that start with Ready, default is 30s
+	// A negative value disables the health pings
+	HealthPingInterval time.Duration
+	// HealthCheck is called before each health ping, if it returns an error the DedicatedGameServer is reported as Failed
+	HealthCheck func() error
+	// Heartbeats makes the health pings send a Heartbeat instead of the Healthy Health, the Failed Health is still reported
+	// It is required for DedicatedGameServers with a HealthCheck, which are set to Failed if their heartbeats stop
+	Heartbeats bool
+	// WatchInterval is the interval that WatchSelf checks the DedicatedGameServer at, default is 5s
+	WatchInterval time.Duration
+}
+
+// ConfigFromEnv returns the Config of the DedicatedGameServer from the environment variables of the container
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		ServerName:   os.Getenv(EnvServerName),
+		Namespace:    os.Getenv(EnvServerNamespace),
+		APIServerURL: os.Getenv(EnvAPIServerURL),
+		Code:         os.Getenv(EnvAPIServerCode),
+	}
+	for name, value := range map[string]string{EnvServerName: config.ServerName, EnvServerNamespace: config.Namespace,
+		EnvAPIServerURL: config.APIServerURL, EnvAPIServerCode: config.Code} {
+		if value == "" {
+			return config, fmt.Errorf("environment variable %s is not set", name)
+		}
+	}
+	if value := os.Getenv(EnvHeartbeatPeriod); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return config, fmt.Errorf("environment variable %s is not a positive number of seconds", EnvHeartbeatPeriod)
+		}
+		config.HealthPingInterval = time.Duration(seconds) * time.Second
+		config.Heartbeats = true
+	}
+	return config, nil
+}
+
+// Error is returned when the API Server rejects a request
+type Error struct {
+	helpers.APIError
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("API Server returned %d %s: %s", e.Code, e.Reason, e.Message)
+}
+
+// IsRetryable returns true if the request that failed with the error can be retried, i.e. it is not an error
+// that the API Server returned for an invalid or unauthorized request
+func IsRetryable(err error) bool {
+	apiError, ok := err.(*Error)
+	return !ok || apiError.Code == http.StatusTooManyRequests || apiError.Code >= 500
+}
+
+// SDK reports the Health, state and active players of the DedicatedGameServer to the API Server
+type SDK struct {
+	config Config
+	// collectionURL is the URL of the DedicatedGameServers of the namespace, dgsPath is the path of the DedicatedGameServer relative to it
+	collectionURL string
+	dgsPath       string
+	client        *http.Client
+
+	// playersMutex serializes the player updates, so the API Server receives them in order
+	playersMutex sync.Mutex
+	players      map[string]bool
+
+	mutex       sync.Mutex
+	pingsActive bool
+	stopCh      chan struct{}
+	stopped     bool
+}
+
+// NewFromEnv returns an SDK for the DedicatedGameServer of the container, see ConfigFromEnv
+func NewFromEnv() (*SDK, error) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
+
+// New returns an SDK with the designated Config, the omitted settings get their default values
+func New(config Config) (*SDK, error) {
+	if config.ServerName == "" || config.Namespace == "" {
+		return nil, fmt.Errorf("ServerName and Namespace are required")
+	}
+	u, err := url.Parse(config.APIServerURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("APIServerURL must be an absolute http(s) URL")
+	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = defaultRetryBaseDelay
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = defaultRequestTimeout
+	}
+	if config.HealthPingInterval == 0 {
+		config.HealthPingInterval = defaultHealthPingInterval
+	}
+	if config.WatchInterval <= 0 {
+		config.WatchInterval = defaultWatchInterval
+	}
+
+	return &SDK{
+		config:        config,
+		collectionURL: fmt.Sprintf("%s/api/v1/namespaces/%s/dedicatedgameservers", strings.TrimSuffix(config.APIServerURL, "/"), config.Namespace),
+		dgsPath:       "/" + config.ServerName,
+		client:        &http.Client{Timeout: config.RequestTimeout},
+		players:       make(map[string]bool),
+		stopCh:        make(chan struct{}),
+	}, nil
+}
+
+// Ready marks the DedicatedGameServer as Healthy and starts the health pings
+func (s *SDK) Ready() error {
+	health := string(dgsv1alpha1.DGSHealthy)
+	if err := s.patchStatus(helpers.DGSStatusUpdate{Health: &health}); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.config.HealthPingInterval > 0 && !s.pingsActive && !s.stopped {
+		s.pingsActive = true
+		go s.runHealthPings()
+	}
+	return nil
+}
+
+// runHealthPings reports the Health of the DedicatedGameServer until the SDK is stopped
+// The Health is reported even if it has not changed, so the API Server can tell that the game server is alive
+func (s *SDK) runHealthPings() {
+	ticker := time.NewTicker(s.config.HealthPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			health := dgsv1alpha1.DGSHealthy
+			if s.config.HealthCheck != nil && s.config.HealthCheck() != nil {
+				health = dgsv1alpha1.DGSFailed
+			}
+			// a failed ping is not retried, the next one is sent on the next tick
+			if health == dgsv1alpha1.DGSHealthy && s.config.Heartbeats {
+				s.do(http.MethodPost, s.dgsPath+"/heartbeat", nil, nil, 0)
+				continue
+			}
+			value := string(health)
+			s.do(http.MethodPatch, s.dgsPath+"/status", helpers.DGSStatusUpdate{Health: &value}, nil, 0)
+		}
+	}
+}
+
+// Heartbeat reports that the game server is alive, so a DedicatedGameServer with a HealthCheck is not set to Failed
+// The health pings send the heartbeats if the Heartbeats setting is true, so game servers only need it if they disable the health pings
+func (s *SDK) Heartbeat() error {
+	return s.do(http.MethodPost, s.dgsPath+"/heartbeat", nil, nil, s.config.MaxRetries)
+}
+
+// SetState sets the state of the DedicatedGameServer
+func (s *SDK) SetState(state dgsv1alpha1.DGSState) error {
+	value := string(state)
+	return s.patchStatus(helpers.DGSStatusUpdate{State: &value})
+}
+
+// SetPlayers sets the number of active players, the players that were reported via PlayerConnect are forgotten
+func (s *SDK) SetPlayers(count int) error {
+	s.playersMutex.Lock()
+	defer s.playersMutex.Unlock()
+	s.players = make(map[string]bool)
+	return s.putPlayers(count)
+}
+
+// PlayerConnect adds the player to the active players, connecting the same player twice has no effect
+func (s *SDK) PlayerConnect(playerID string) error {
+	s.playersMutex.Lock()
+	defer s.playersMutex.Unlock()
+	if s.players[playerID] {
+		return nil
+	}
+	if err := s.putPlayers(len(s.players) + 1); err != nil {
+		return err
+	}
+	s.players[playerID] = true
+	return nil
+}
+
+// PlayerDisconnect removes the player from the active players, disconnecting an unknown player has no effect
+func (s *SDK) PlayerDisconnect(playerID string) error {
+	s.playersMutex.Lock()
+	defer s.playersMutex.Unlock()
+	if !s.players[playerID] {
+		return nil
+	}
+	if err := s.putPlayers(len(s.players) - 1); err != nil {
+		return err
+	}
+	delete(s.players, playerID)
+	return nil
+}
+
+// Shutdown marks the DedicatedGameServer for deletion and stops the background operations
+// The DedicatedGameServer is deleted once it has no active players
+func (s *SDK) Shutdown() error {
+	defer s.Close()
+	markedForDeletion := true
+	return s.patchStatus(helpers.DGSStatusUpdate{MarkedForDeletion: &markedForDeletion})
+}
+
+// Update applies several changes to the DedicatedGameServer in one request, via the batch update method of the API Server
+// The Name of the update is set to the name of the DedicatedGameServer
+func (s *SDK) Update(update helpers.DGSUpdate) error {
+	update.Name = s.config.ServerName
+	var result helpers.DGSBatchUpdateResult
+	if err := s.do(http.MethodPatch, "", helpers.DGSBatchUpdate{Items: []helpers.DGSUpdate{update}}, &result, s.config.MaxRetries); err != nil {
+		return err
+	}
+	if len(result.Items) != 1 {
+		return fmt.Errorf("API Server returned %d results for one update", len(result.Items))
+	}
+	if result.Items[0].Error != nil {
+		return &Error{*result.Items[0].Error}
+	}
+	return nil
+}
+
+// WatchSelf calls the function with the DedicatedGameServer every time it changes, until Shutdown or Close is called
+// The DedicatedGameServer is polled every WatchInterval, the function is called once with its current version
+func (s *SDK) WatchSelf(f func(*dgsv1alpha1.DedicatedGameServer)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stopped {
+		return fmt.Errorf("the SDK has been closed")
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.config.WatchInterval)
+		defer ticker.Stop()
+		resourceVersion := ""
+		for {
+			var dgs dgsv1alpha1.DedicatedGameServer
+			// errors are not retried, the DedicatedGameServer is polled again on the next tick
+			if err := s.do(http.MethodGet, s.dgsPath, nil, &dgs, 0); err == nil && dgs.ResourceVersion != resourceVersion {
+				resourceVersion = dgs.ResourceVersion
+				f(&dgs)
+			}
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops the background operations without modifying the DedicatedGameServer
+func (s *SDK) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopCh)
+	}
+}
+
+func (s *SDK) patchStatus(update helpers.DGSStatusUpdate) error {
+	return s.do(http.MethodPatch, s.dgsPath+"/status", update, nil, s.config.MaxRetries)
+}
+
+func (s *SDK) putPlayers(count int) error {
+	return s.do(http.MethodPut, s.dgsPath+"/players", helpers.DGSPlayers{PlayerCount: count}, nil, s.config.MaxRetries)
+}
+
+// do sends the request to the path (relative to the collectionURL) and decodes the response into out (if it is not nil)
+// Network errors, 429 and 5xx responses are retried with exponential backoff, up to maxRetries times
+func (s *SDK) do(method string, path string, in interface{}, out interface{}, maxRetries int) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	delay := s.config.RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := s.send(method, path, body, out)
+		if err == nil || retryAfter < 0 || attempt >= maxRetries {
+			return err
+		}
+		if retryAfter == 0 {
+			retryAfter = delay
+		}
+		time.Sleep(retryAfter)
+		if delay *= 2; delay > defaultRetryMaxDelay {
+			delay = defaultRetryMaxDelay
+		}
+	}
+}
+
+// send sends the request once
+// If the request failed, it returns the delay that the API Server requested before retrying (zero if it did not request one)
+// or a negative delay if the request should not be retried
+func (s *SDK) send(method string, path string, body []byte, out interface{}) (time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, s.collectionURL+path, reader)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set(helpers.AccessCodeHeader, s.config.Code)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out == nil {
+			return 0, nil
+		}
+		return -1, json.Unmarshal(respBody, out)
+	}
+
+	apiError := &Error{}
+	if json.Unmarshal(respBody, &apiError.APIError) != nil || apiError.Code == 0 {
+		apiError.Code = resp.StatusCode
+		apiError.Message = strings.TrimSpace(string(respBody))
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, apiError
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, apiError
+	}
+	return 0, apiError
+}
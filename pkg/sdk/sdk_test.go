@@ -0,0 +1,301 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	code   string
+	body   string
+}
+
+// apiServerRecorder is an API Server that returns the designated status codes, in order, and records the requests
+// GET requests return a DedicatedGameServer with the current resourceVersion, batch updates fail for the Sleeping state
+type apiServerRecorder struct {
+	mutex           sync.Mutex
+	codes           []int
+	requests        []recordedRequest
+	resourceVersion int
+}
+
+func (a *apiServerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.requests = append(a.requests, recordedRequest{r.Method, r.URL.Path, r.Header.Get(helpers.AccessCodeHeader), string(body)})
+	code := http.StatusOK
+	if len(a.codes) > 0 {
+		code, a.codes = a.codes[0], a.codes[1:]
+	}
+	if code != http.StatusOK {
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(helpers.APIError{Code: code, Reason: "Test", Message: "test error"})
+		return
+	}
+	if r.URL.Path == collectionURLPath {
+		var update helpers.DGSBatchUpdate
+		json.Unmarshal(body, &update)
+		result := helpers.DGSBatchUpdateResult{}
+		for _, item := range update.Items {
+			itemResult := helpers.DGSUpdateResult{Name: item.Name, Code: http.StatusOK}
+			if item.State != nil && *item.State == "Sleeping" {
+				itemResult.Code = http.StatusBadRequest
+				itemResult.Error = &helpers.APIError{Code: http.StatusBadRequest, Reason: "Test", Message: "wrong state"}
+			}
+			result.Items = append(result.Items, itemResult)
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+	dgs := dgsv1alpha1.DedicatedGameServer{}
+	dgs.Name = "dgs1"
+	dgs.ResourceVersion = strconv.Itoa(a.resourceVersion)
+	json.NewEncoder(w).Encode(dgs)
+}
+
+func (a *apiServerRecorder) recorded() []recordedRequest {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]recordedRequest{}, a.requests...)
+}
+
+func newTestSDK(t *testing.T, recorder *apiServerRecorder, config Config) (*SDK, func()) {
+	server := httptest.NewServer(recorder)
+	config.ServerName, config.Namespace, config.APIServerURL, config.Code = "dgs1", "default", server.URL+"/", "token1"
+	if config.RetryBaseDelay == 0 {
+		config.RetryBaseDelay = time.Millisecond
+	}
+	s, err := New(config)
+	assert.NoError(t, err)
+	return s, func() {
+		s.Close()
+		server.Close()
+	}
+}
+
+const (
+	collectionURLPath = "/api/v1/namespaces/default/dedicatedgameservers"
+	dgsURLPath        = collectionURLPath + "/dgs1"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	for name, value := range map[string]string{EnvServerName: "dgs1", EnvServerNamespace: "default", EnvAPIServerURL: "http://apiserver", EnvAPIServerCode: "token1"} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	config, err := ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Config{ServerName: "dgs1", Namespace: "default", APIServerURL: "http://apiserver", Code: "token1"}, config)
+
+	// the health pings of a DedicatedGameServer with a HealthCheck are heartbeats
+	os.Setenv(EnvHeartbeatPeriod, "5")
+	defer os.Unsetenv(EnvHeartbeatPeriod)
+	config, err = ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, config.HealthPingInterval)
+	assert.True(t, config.Heartbeats)
+
+	os.Setenv(EnvHeartbeatPeriod, "0")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+	os.Unsetenv(EnvHeartbeatPeriod)
+
+	os.Unsetenv(EnvAPIServerCode)
+	_, err = NewFromEnv()
+	assert.Error(t, err)
+}
+
+func TestReadyStartsHealthPings(t *testing.T) {
+	recorder := &apiServerRecorder{}
+	var checkMutex sync.Mutex
+	var failing bool
+	s, cleanup := newTestSDK(t, recorder, Config{
+		HealthPingInterval: 10 * time.Millisecond,
+		HealthCheck: func() error {
+			checkMutex.Lock()
+			defer checkMutex.Unlock()
+			if failing {
+				return assert.AnError
+			}
+			return nil
+		},
+	})
+	defer cleanup()
+
+	assert.NoError(t, s.Ready())
+	requests := recorder.recorded()
+	assert.Equal(t, recordedRequest{http.MethodPatch, dgsURLPath + "/status", "token1", `{"health":"Healthy"}`}, requests[0])
+
+	assert.NoError(t, wait.Poll(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(recorder.recorded()) >= 3, nil
+	}))
+	assert.Equal(t, `{"health":"Healthy"}`, recorder.recorded()[2].body)
+
+	checkMutex.Lock()
+	failing = true
+	checkMutex.Unlock()
+	assert.NoError(t, wait.Poll(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		requests := recorder.recorded()
+		return requests[len(requests)-1].body == `{"health":"Failed"}`, nil
+	}))
+
+	// the pings stop when the SDK is closed
+	s.Close()
+	time.Sleep(20 * time.Millisecond)
+	count := len(recorder.recorded())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, len(recorder.recorded()))
+}
+
+func TestHealthPingsSendHeartbeats(t *testing.T) {
+	recorder := &apiServerRecorder{}
+	var checkMutex sync.Mutex
+	var failing bool
+	s, cleanup := newTestSDK(t, recorder, Config{
+		HealthPingInterval: 10 * time.Millisecond,
+		Heartbeats:         true,
+		HealthCheck: func() error {
+			checkMutex.Lock()
+			defer checkMutex.Unlock()
+			if failing {
+				return assert.AnError
+			}
+			return nil
+		},
+	})
+	defer cleanup()
+
+	assert.NoError(t, s.Ready())
+	assert.NoError(t, wait.Poll(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(recorder.recorded()) >= 3, nil
+	}))
+	assert.Equal(t, recordedRequest{http.MethodPost, dgsURLPath + "/heartbeat", "token1", ""}, recorder.recorded()[2])
+
+	// a failed HealthCheck is still reported as the Failed Health
+	checkMutex.Lock()
+	failing = true
+	checkMutex.Unlock()
+	assert.NoError(t, wait.Poll(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		requests := recorder.recorded()
+		return requests[len(requests)-1].body == `{"health":"Failed"}`, nil
+	}))
+}
+
+func TestPlayers(t *testing.T) {
+	recorder := &apiServerRecorder{}
+	s, cleanup := newTestSDK(t, recorder, Config{})
+	defer cleanup()
+
+	assert.NoError(t, s.PlayerConnect("alice"))
+	assert.NoError(t, s.PlayerConnect("alice"))
+	assert.NoError(t, s.PlayerConnect("bob"))
+	assert.NoError(t, s.PlayerDisconnect("alice"))
+	assert.NoError(t, s.PlayerDisconnect("carol"))
+	assert.NoError(t, s.SetPlayers(5))
+	assert.NoError(t, s.PlayerConnect("alice"))
+
+	var bodies []string
+	for _, request := range recorder.recorded() {
+		assert.Equal(t, http.MethodPut, request.method)
+		assert.Equal(t, dgsURLPath+"/players", request.path)
+		bodies = append(bodies, request.body)
+	}
+	assert.Equal(t, []string{`{"playerCount":1}`, `{"playerCount":2}`, `{"playerCount":1}`, `{"playerCount":5}`, `{"playerCount":1}`}, bodies)
+}
+
+func TestRetries(t *testing.T) {
+	recorder := &apiServerRecorder{codes: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	s, cleanup := newTestSDK(t, recorder, Config{})
+	defer cleanup()
+
+	assert.NoError(t, s.SetState(dgsv1alpha1.DGSAssigned))
+	assert.Len(t, recorder.recorded(), 3)
+	assert.Equal(t, `{"state":"Assigned"}`, recorder.recorded()[2].body)
+
+	// client errors are not retried
+	recorder.mutex.Lock()
+	recorder.codes = []int{http.StatusBadRequest}
+	recorder.mutex.Unlock()
+	err := s.SetState("Sleeping")
+	if apiError, ok := err.(*Error); assert.True(t, ok, "The error should be an *Error") {
+		assert.Equal(t, http.StatusBadRequest, apiError.Code)
+		assert.Equal(t, "test error", apiError.Message)
+	}
+	assert.Len(t, recorder.recorded(), 4)
+
+	// the request fails once the retries are exhausted
+	recorder.mutex.Lock()
+	recorder.codes = []int{500, 500, 500, 500, 500, 500}
+	recorder.mutex.Unlock()
+	assert.Error(t, s.SetState(dgsv1alpha1.DGSRunning))
+	assert.Len(t, recorder.recorded(), 4+defaultMaxRetries+1)
+}
+
+func TestUpdate(t *testing.T) {
+	recorder := &apiServerRecorder{codes: []int{http.StatusServiceUnavailable}}
+	s, cleanup := newTestSDK(t, recorder, Config{})
+	defer cleanup()
+
+	state, players := "Running", 3
+	assert.NoError(t, s.Update(helpers.DGSUpdate{State: &state, ActivePlayers: &players}))
+	requests := recorder.recorded()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, recordedRequest{http.MethodPatch, collectionURLPath, "token1", `{"items":[{"name":"dgs1","state":"Running","activePlayers":3}]}`}, requests[1])
+
+	// the errors of the item are returned
+	state = "Sleeping"
+	err := s.Update(helpers.DGSUpdate{State: &state})
+	if apiError, ok := err.(*Error); assert.True(t, ok, "The error should be an *Error") {
+		assert.Equal(t, "wrong state", apiError.Message)
+	}
+	assert.False(t, IsRetryable(err))
+	assert.True(t, IsRetryable(&Error{helpers.APIError{Code: http.StatusServiceUnavailable}}))
+}
+
+func TestShutdownStopsWatchingSelf(t *testing.T) {
+	recorder := &apiServerRecorder{resourceVersion: 1}
+	s, cleanup := newTestSDK(t, recorder, Config{WatchInterval: 5 * time.Millisecond})
+	defer cleanup()
+
+	versions := make(chan string, 10)
+	assert.NoError(t, s.WatchSelf(func(dgs *dgsv1alpha1.DedicatedGameServer) {
+		versions <- dgs.ResourceVersion
+	}))
+	assert.Equal(t, "1", <-versions)
+
+	// unchanged versions are not reported
+	time.Sleep(20 * time.Millisecond)
+	recorder.mutex.Lock()
+	recorder.resourceVersion = 2
+	recorder.mutex.Unlock()
+	assert.Equal(t, "2", <-versions)
+
+	assert.NoError(t, s.Shutdown())
+	// the watcher may poll concurrently with the update
+	var shutdown *recordedRequest
+	for _, request := range recorder.recorded() {
+		if request.method == http.MethodPatch {
+			shutdown = &request
+		}
+	}
+	if assert.NotNil(t, shutdown) {
+		assert.Equal(t, `{"markedForDeletion":true}`, shutdown.body)
+	}
+	assert.Error(t, s.WatchSelf(func(*dgsv1alpha1.DedicatedGameServer) {}))
+}
@@ -1,39 +1,198 @@
 package shared
 
 import (
+	"crypto/subtle"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
 	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
-// AuthenticateWebServerCode authenticates the user request by comparing the given code with the actual
-func AuthenticateWebServerCode(code string) (bool, error) {
-	if accesscode == "" {
+// AccessCodeGracePeriod is the period during which the previous access code of a namespace is still accepted,
+// after the code has been rotated
+var AccessCodeGracePeriod = 10 * time.Minute
+
+// namespaceAccessCodes contains the current access code (or DGS token signing key) of a namespace and, after a rotation, the previous one
+type namespaceAccessCodes struct {
+	current        string
+	previous       string
+	previousExpiry time.Time
+	// missing is true if the namespace has no access code Secret, until the Secret informer adds it
+	missing bool
+}
+
+// accesscodes caches the API Server access codes per namespace
+var accesscodes = make(map[string]*namespaceAccessCodes)
+var accesscodesMutex sync.RWMutex
+var accesscodesClock clockwork.Clock = clockwork.NewRealClock()
+
+// AuthenticateWebServerCode authenticates the user request by comparing the given code with the actual one
+// for the designated namespace. An empty code is never valid
+func AuthenticateWebServerCode(code string, namespace string) (bool, error) {
+	if code == "" {
+		return false, nil
+	}
+	valid := false
+	err := forEachValidAccessCode(namespace, func(accesscode string) {
+		valid = valid || (accesscode != "" && subtle.ConstantTimeCompare([]byte(code), []byte(accesscode)) == 1)
+	})
+	return valid, err
+}
+
+// forEachValidAccessCode calls f for the current access code of the namespace
+// and for the previous one, if the code has been rotated within the AccessCodeGracePeriod
+// f is not called at all if the namespace has no access code
+func forEachValidAccessCode(namespace string, f func(accesscode string)) error {
+	codes, ok := getCachedAccessCodes(namespace)
+	if !ok {
 		client, _, err := GetClientSet()
 		if err != nil {
-			return false, err
+			return err
 		}
-		_, err = GetAccessCode(client)
-		if err != nil {
-			return false, err
+		if err := loadAccessCode(client, namespace); err != nil {
+			return err
 		}
+		codes, ok = getCachedAccessCodes(namespace)
 	}
-	return code == accesscode, nil
+
+	// the Secret may have been deleted in the meantime, in which case no code is valid
+	if !ok || codes.missing {
+		return nil
+	}
+
+	for _, code := range codes.valid() {
+		f(code)
+	}
+	return nil
+}
+
+// valid returns the current code and the previous one, if the code has been rotated within the AccessCodeGracePeriod
+func (codes namespaceAccessCodes) valid() []string {
+	if codes.previous != "" && accesscodesClock.Now().Before(codes.previousExpiry) {
+		return []string{codes.current, codes.previous}
+	}
+	return []string{codes.current}
 }
 
-var accesscode string
+// rotate sets the current code and keeps the replaced one as the previous one for the AccessCodeGracePeriod
+// It returns false if the code has not changed
+func (codes *namespaceAccessCodes) rotate(code string) bool {
+	if codes.current == code {
+		return false
+	}
+	codes.previous = codes.current
+	codes.previousExpiry = accesscodesClock.Now().Add(AccessCodeGracePeriod)
+	codes.current = code
+	return true
+}
 
-func GetAccessCode(client kubernetes.Interface) (string, error) {
-	if accesscode == "" { //if we haven't accessed the code
-		// client, _, err := GetClientSet()
-		// if err != nil {
-		// 	return "", err
-		// }
-		secret, err := client.Core().Secrets(GameNamespace).Get(APIAccessCodeSecretName, meta_v1.GetOptions{})
-		if err != nil {
-			log.Fatalf("Cannot get API Server access code due to %s", err.Error())
+// getCachedAccessCodes returns a copy of the cached access codes of the namespace
+func getCachedAccessCodes(namespace string) (namespaceAccessCodes, bool) {
+	accesscodesMutex.RLock()
+	defer accesscodesMutex.RUnlock()
+	codes, ok := accesscodes[namespace]
+	if !ok {
+		return namespaceAccessCodes{}, false
+	}
+	return *codes, true
+}
+
+// GetAccessCode returns the current API Server access code for the designated namespace
+// The code is kept in the APIAccessCodeSecretName Secret of each namespace
+func GetAccessCode(client kubernetes.Interface, namespace string) (string, error) {
+	codes, ok := getCachedAccessCodes(namespace)
+	if !ok {
+		if err := loadAccessCode(client, namespace); err != nil {
+			return "", err
 		}
-		accesscode = string(secret.Data["code"])
+		codes, ok = getCachedAccessCodes(namespace)
+	}
+	if !ok || codes.missing {
+		return "", fmt.Errorf("Namespace %s has no API Server access code Secret", namespace)
+	}
+	return codes.current, nil
+}
+
+// loadAccessCode reads the access code Secret of the namespace into the cache
+// A missing Secret is cached as well, so the requests of a namespace without an access code do not read the Secret again
+func loadAccessCode(client kubernetes.Interface, namespace string) error {
+	secret, err := client.CoreV1().Secrets(namespace).Get(APIAccessCodeSecretName, meta_v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		setMissingAccessCode(namespace)
+		return nil
 	}
-	return accesscode, nil
+	if err != nil {
+		return fmt.Errorf("Cannot get API Server access code for namespace %s due to %s", namespace, err.Error())
+	}
+
+	setAccessCode(namespace, string(secret.Data["code"]))
+	return nil
+}
+
+// setAccessCode sets the current access code of the namespace
+// If the namespace had a different code, it is kept as the previous one for the AccessCodeGracePeriod
+func setAccessCode(namespace string, code string) {
+	accesscodesMutex.Lock()
+	defer accesscodesMutex.Unlock()
+
+	codes, ok := accesscodes[namespace]
+	if !ok || codes.missing {
+		accesscodes[namespace] = &namespaceAccessCodes{current: code}
+		return
+	}
+	if codes.rotate(code) {
+		log.WithField("Namespace", namespace).Info("API Server access code has been rotated")
+	}
+}
+
+// setMissingAccessCode records that the namespace has no access code Secret, so no code is valid
+func setMissingAccessCode(namespace string) {
+	accesscodesMutex.Lock()
+	defer accesscodesMutex.Unlock()
+	accesscodes[namespace] = &namespaceAccessCodes{missing: true}
+}
+
+func deleteAccessCode(namespace string) {
+	accesscodesMutex.Lock()
+	defer accesscodesMutex.Unlock()
+	delete(accesscodes, namespace)
+}
+
+// WatchAccessCodes watches the APIAccessCodeSecretName Secrets in all namespaces and keeps the cached access codes up to date,
+// so the access code can be rotated without restarting the API Server
+func WatchAccessCodes(client kubernetes.Interface, stopCh <-chan struct{}) {
+	informerFactory := informers.NewFilteredSharedInformerFactory(client, 30*time.Minute, meta_v1.NamespaceAll, func(options *meta_v1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", APIAccessCodeSecretName).String()
+	})
+
+	informerFactory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			secret := obj.(*corev1.Secret)
+			setAccessCode(secret.Namespace, string(secret.Data["code"]))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			secret := newObj.(*corev1.Secret)
+			setAccessCode(secret.Namespace, string(secret.Data["code"]))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				setMissingAccessCode(secret.Namespace)
+			}
+		},
+	})
+
+	informerFactory.Start(stopCh)
 }
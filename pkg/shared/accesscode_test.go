@@ -1,19 +1,173 @@
 package shared
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
 
 func TestGetAccessCode(t *testing.T) {
-	accesscode = "code123!"
-	code, _ := GetAccessCode(nil)
-	if code != accesscode {
+	deleteAccessCode(GameNamespace)
+	setAccessCode(GameNamespace, "code123!")
+	code, _ := GetAccessCode(nil, GameNamespace)
+	if code != "code123!" {
 		t.Error("Codes should be the same")
 	}
 }
 
 func TestAuthenticateWebServerCode(t *testing.T) {
-	accesscode = "code123!"
-	result, _ := AuthenticateWebServerCode("code123!")
+	deleteAccessCode(GameNamespace)
+	setAccessCode(GameNamespace, "code123!")
+	result, _ := AuthenticateWebServerCode("code123!", GameNamespace)
 	if !result {
 		t.Error("Should be true")
 	}
 }
+
+func TestGetAccessCodePerNamespace(t *testing.T) {
+	client := k8sfake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      APIAccessCodeSecretName,
+			Namespace: "title1",
+		},
+		Data: map[string][]byte{"code": []byte("title1code")},
+	})
+
+	code, err := GetAccessCode(client, "title1")
+	if err != nil || code != "title1code" {
+		t.Errorf("Expected code title1code, got %s (error: %v)", code, err)
+	}
+
+	if _, err := GetAccessCode(client, "title2"); err == nil {
+		t.Error("Expected error for namespace without access code Secret")
+	}
+}
+
+func TestMissingAccessCodeIsCached(t *testing.T) {
+	client := k8sfake.NewSimpleClientset()
+	deleteAccessCode("nosecret")
+
+	if _, err := GetAccessCode(client, "nosecret"); err == nil {
+		t.Error("Expected error for namespace without access code Secret")
+	}
+	// the missing Secret is not read again, a request with a code is not authenticated
+	if result, err := AuthenticateWebServerCode("code123!", "nosecret"); result || err != nil {
+		t.Errorf("Expected an unauthenticated request without error, got %v (error: %v)", result, err)
+	}
+	if len(client.Actions()) != 1 {
+		t.Errorf("Expected a single read of the Secret, got %d actions", len(client.Actions()))
+	}
+
+	// the Secret informer adds the Secret
+	setAccessCode("nosecret", "code123!")
+	if result, _ := AuthenticateWebServerCode("code123!", "nosecret"); !result {
+		t.Error("The code of the added Secret should be accepted")
+	}
+}
+
+func TestEmptyAccessCodeIsRejected(t *testing.T) {
+	deleteAccessCode("emptycode")
+	setAccessCode("emptycode", "")
+	if result, _ := AuthenticateWebServerCode("", "emptycode"); result {
+		t.Error("An empty code should not be accepted")
+	}
+}
+
+func TestAccessCodeRotation(t *testing.T) {
+	fakeClock := clockwork.NewFakeClock()
+	accesscodesClock = fakeClock
+	defer func() { accesscodesClock = clockwork.NewRealClock() }()
+
+	deleteAccessCode("rotation")
+	setAccessCode("rotation", "oldcode")
+	setAccessCode("rotation", "newcode")
+
+	if code, _ := GetAccessCode(nil, "rotation"); code != "newcode" {
+		t.Errorf("Expected current code newcode, got %s", code)
+	}
+	if result, _ := AuthenticateWebServerCode("newcode", "rotation"); !result {
+		t.Error("New code should be accepted")
+	}
+	if result, _ := AuthenticateWebServerCode("oldcode", "rotation"); !result {
+		t.Error("Previous code should be accepted during the grace period")
+	}
+
+	fakeClock.Advance(AccessCodeGracePeriod + time.Second)
+
+	if result, _ := AuthenticateWebServerCode("oldcode", "rotation"); result {
+		t.Error("Previous code should not be accepted after the grace period")
+	}
+	if result, _ := AuthenticateWebServerCode("newcode", "rotation"); !result {
+		t.Error("New code should be accepted")
+	}
+}
+
+func TestAuthenticateWebServerCodeWithConcurrentDelete(t *testing.T) {
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+				setAccessCode("concurrent", "code123!")
+				deleteAccessCode("concurrent")
+			}
+		}
+	}()
+
+	for i := 0; i < 100000; i++ {
+		// a missing code must result in an error or in an unauthorized call, never in a panic
+		if result, err := AuthenticateWebServerCode("othercode", "concurrent"); err == nil && result {
+			t.Error("Wrong code should not be accepted")
+		}
+	}
+	close(stopCh)
+	<-done
+}
+
+func TestWatchAccessCodes(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      APIAccessCodeSecretName,
+			Namespace: "watched",
+		},
+		Data: map[string][]byte{"code": []byte("firstcode")},
+	}
+	client := k8sfake.NewSimpleClientset(secret)
+	deleteAccessCode("watched")
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	WatchAccessCodes(client, stopCh)
+
+	waitForAccessCode(t, "watched", "firstcode")
+
+	secret = secret.DeepCopy()
+	secret.Data["code"] = []byte("secondcode")
+	if _, err := client.CoreV1().Secrets("watched").Update(secret); err != nil {
+		t.Fatalf("Cannot update Secret: %v", err)
+	}
+
+	waitForAccessCode(t, "watched", "secondcode")
+	if result, _ := AuthenticateWebServerCode("firstcode", "watched"); !result {
+		t.Error("Previous code should be accepted during the grace period")
+	}
+}
+
+func waitForAccessCode(t *testing.T, namespace string, expected string) {
+	for i := 0; i < 50; i++ {
+		if codes, ok := getCachedAccessCodes(namespace); ok && codes.current == expected {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("Access code of namespace %s was not updated to %s", namespace, expected)
+}
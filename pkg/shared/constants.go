@@ -2,6 +2,10 @@ package shared
 
 const APIAccessCodeSecretName = "apiaccesscode"
 
+// DGSTokenSigningKeySecretName is the name of the Secret that holds the key which signs the DedicatedGameServer tokens of a namespace
+// It is created by the controller and kept separate from the access code, so tokens stay valid when the access code is rotated
+const DGSTokenSigningKeySecretName = "dgstokensigningkey"
+
 // make sure to change the host values if the K8s service name for the API Server is changed
 const (
 	APIServerURL = "http://aks-gaming-apiserver.dgs-system.svc.cluster.local"
@@ -26,6 +30,30 @@ const (
 	LabelOriginalDedicatedGameServerCollectionName = "OriginalDedicatedGameServerCollectionName"
 )
 
+const (
+	// AnnotationDedicatedGameServerInfo is the Pod annotation that holds the JSON serialized DGSInfo
+	AnnotationDedicatedGameServerInfo = "DedicatedGameServerInfo"
+	// AnnotationSDKSidecar is the DedicatedGameServerCollection annotation that, when "true", makes the webhook add the SDK sidecar container to the template
+	AnnotationSDKSidecar = "SDKSidecar"
+	// SDKSidecarContainerName is the name of the SDK sidecar container
+	SDKSidecarContainerName = "sdk-sidecar"
+	// EnvSDKSidecarURL is the environment variable that holds the URL of the SDK sidecar, it is set to the containers of the game server
+	EnvSDKSidecarURL = "SDK_SIDECAR_URL"
+	// EnvSDKSidecarGRPCAddress is the environment variable that holds the address of the gRPC API of the SDK sidecar, it is set to the containers of the game server
+	EnvSDKSidecarGRPCAddress = "SDK_SIDECAR_GRPC_ADDRESS"
+	// DGSInfoVolumeName is the name of the Downward API volume that exposes DGSInfo to the game server containers
+	DGSInfoVolumeName = "dgsinfo"
+	// DGSInfoMountPath is the directory in which DGSInfoFileName is mounted
+	DGSInfoMountPath = "/etc/dgs"
+	// DGSInfoFileName is the file that contains the JSON serialized DGSInfo. It is updated by the kubelet when the Pod annotation changes
+	DGSInfoFileName = "info.json"
+	// EnvHostPortPrefix is the prefix of the environment variables that hold the allocated HostPorts
+	// e.g. HOST_PORT_7777=20001 and HOST_PORT_GAME=20001 for a ContainerPort 7777 named "game"
+	EnvHostPortPrefix = "HOST_PORT_"
+	// EnvHeartbeatPeriodSeconds is the environment variable that holds the heartbeat period of the DGSs that have a HealthCheck
+	EnvHeartbeatPeriodSeconds = "HEARTBEAT_PERIOD_SECONDS"
+)
+
 const (
 	// SuccessSynced is used as part of the Event 'reason' when a CRD is synced
 	SuccessSynced = "Synced"
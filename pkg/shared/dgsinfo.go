@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DGSInfo contains the details that the game server process needs in order to advertise itself
+// It is kept as a Pod annotation and is mounted in the game server containers via the Downward API
+type DGSInfo struct {
+	PublicIP   string                   `json:"publicIP"`
+	PublicIPv6 string                   `json:"publicIPv6,omitempty"`
+	Addresses  []dgsv1alpha1.DGSAddress `json:"addresses,omitempty"`
+	NodeName   string                   `json:"nodeName"`
+	Ports      []dgsv1alpha1.DGSPort    `json:"ports"`
+}
+
+// NewDGSInfo returns the DGSInfo for the designated DedicatedGameServer, based on its Status
+func NewDGSInfo(dgs *dgsv1alpha1.DedicatedGameServer) DGSInfo {
+	return DGSInfo{
+		PublicIP:   dgs.Status.PublicIP,
+		PublicIPv6: dgs.Status.PublicIPv6,
+		Addresses:  dgs.Status.Addresses,
+		NodeName:   dgs.Status.NodeName,
+		Ports:      dgs.Status.Ports,
+	}
+}
+
+// Marshal returns the JSON representation of the DGSInfo, to be used as the Pod annotation value
+func (info DGSInfo) Marshal() (string, error) {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// GetDGSPorts returns all the ContainerPorts of the DedicatedGameServer along with their exposure mode
+// A port is exposed via HostPort if it is included in PortsToExpose and it has been assigned a HostPort
+func GetDGSPorts(dgs *dgsv1alpha1.DedicatedGameServer) []dgsv1alpha1.DGSPort {
+	ports := make([]dgsv1alpha1.DGSPort, 0)
+	for _, container := range dgs.Spec.Template.Containers {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			dgsPort := dgsv1alpha1.DGSPort{
+				Name:          port.Name,
+				Protocol:      protocol,
+				ContainerPort: port.ContainerPort,
+				ExposureMode:  dgsv1alpha1.DGSPortInternal,
+			}
+			if port.HostPort != 0 && SliceContains(dgs.Spec.PortsToExpose, port.ContainerPort) {
+				dgsPort.HostPort = port.HostPort
+				dgsPort.ExposureMode = dgsv1alpha1.DGSPortHostPort
+			}
+			ports = append(ports, dgsPort)
+		}
+	}
+	return ports
+}
+
+// getHostPortEnvVars returns an environment variable per exposed port, keyed by ContainerPort number
+// and, if the port is named, by port name as well
+func getHostPortEnvVars(dgs *dgsv1alpha1.DedicatedGameServer) []corev1.EnvVar {
+	envVars := make([]corev1.EnvVar, 0)
+	for _, port := range GetDGSPorts(dgs) {
+		if port.ExposureMode != dgsv1alpha1.DGSPortHostPort {
+			continue
+		}
+		hostPort := strconv.Itoa(int(port.HostPort))
+		envVars = append(envVars, corev1.EnvVar{Name: fmt.Sprintf("%s%d", EnvHostPortPrefix, port.ContainerPort), Value: hostPort})
+		if port.Name != "" {
+			envVars = append(envVars, corev1.EnvVar{Name: EnvHostPortPrefix + toEnvVarName(port.Name), Value: hostPort})
+		}
+	}
+	return envVars
+}
+
+// toEnvVarName converts a port name (IANA_SVC_NAME, e.g. "game-udp") to an environment variable friendly name (e.g. "GAME_UDP")
+func toEnvVarName(name string) string {
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// newDGSInfoVolume returns a Downward API volume that projects the DGSInfo Pod annotation to a file
+// The kubelet will refresh the file's contents whenever the annotation is updated
+func newDGSInfoVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: DGSInfoVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{
+					{
+						Path: DGSInfoFileName,
+						FieldRef: &corev1.ObjectFieldSelector{
+							FieldPath: fmt.Sprintf("metadata.annotations['%s']", AnnotationDedicatedGameServerInfo),
+						},
+					},
+				},
+			},
+		},
+	}
+}
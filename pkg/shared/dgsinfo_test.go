@@ -0,0 +1,48 @@
+package shared
+
+import (
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewPodExposesHostPorts(t *testing.T) {
+	dgs := NewDedicatedGameServerWithNoParent(GameNamespace, "test", corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Ports: []corev1.ContainerPort{
+					{Name: "game-udp", ContainerPort: 7777, HostPort: 20001, Protocol: corev1.ProtocolUDP},
+					{ContainerPort: 8080, HostPort: 20002},
+					{ContainerPort: 9090}, // not exposed
+				},
+			},
+		},
+	}, []int32{7777, 8080})
+
+	pod := NewPod(dgs, APIDetails{})
+
+	env := make(map[string]string)
+	for _, e := range pod.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "20001", env["HOST_PORT_7777"])
+	assert.Equal(t, "20001", env["HOST_PORT_GAME_UDP"])
+	assert.Equal(t, "20002", env["HOST_PORT_8080"])
+	assert.NotContains(t, env, "HOST_PORT_9090")
+
+	assert.Equal(t, DGSInfoMountPath, pod.Spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, DGSInfoVolumeName, pod.Spec.Volumes[0].Name)
+
+	// the DGS template should not be modified
+	assert.Empty(t, dgs.Spec.Template.Containers[0].Env)
+
+	assert.Equal(t, []dgsv1alpha1.DGSPort{
+		{Name: "game-udp", Protocol: corev1.ProtocolUDP, ContainerPort: 7777, HostPort: 20001, ExposureMode: dgsv1alpha1.DGSPortHostPort},
+		{Protocol: corev1.ProtocolTCP, ContainerPort: 8080, HostPort: 20002, ExposureMode: dgsv1alpha1.DGSPortHostPort},
+		{Protocol: corev1.ProtocolTCP, ContainerPort: 9090, ExposureMode: dgsv1alpha1.DGSPortInternal},
+	}, GetDGSPorts(dgs))
+}
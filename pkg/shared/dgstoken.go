@@ -0,0 +1,202 @@
+package shared
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// dgsTokenSigningKeySize is the size (in bytes) of the generated DedicatedGameServer token signing keys
+const dgsTokenSigningKeySize = 32
+
+// dgsTokenSigningKeys caches the DedicatedGameServer token signing keys per namespace
+// After a rotation, the tokens signed with the previous key are accepted for the AccessCodeGracePeriod, as the previous access codes
+var dgsTokenSigningKeys = make(map[string]*namespaceAccessCodes)
+var dgsTokenSigningKeysMutex sync.RWMutex
+
+// NewDGSToken returns a token that allows the designated DedicatedGameServer (and only this one) to update its status via the API Server
+// The token contains the DGS namespace/name, signed with HMAC-SHA256 using the namespace's DGS token signing key,
+// so neither the access code nor the signing key is passed to the game server Pods
+func NewDGSToken(key string, namespace string, name string) string {
+	subject := namespace + "/" + name
+	return base64.RawURLEncoding.EncodeToString([]byte(subject)) + "." + base64.RawURLEncoding.EncodeToString(signDGSToken(key, subject))
+}
+
+// ValidateDGSToken returns true if the token has been signed with the designated key and belongs to the designated DedicatedGameServer
+func ValidateDGSToken(key string, token string, namespace string, name string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return false
+	}
+
+	subject, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || string(subject) != namespace+"/"+name {
+		return false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(signature, signDGSToken(key, string(subject)))
+}
+
+func signDGSToken(key string, subject string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(subject))
+	return mac.Sum(nil)
+}
+
+// AuthenticateDGSToken authenticates the request of a DedicatedGameServer by validating the token that was given to its Pod
+// If the namespace has no DGS token signing key, no tokens have been issued for it, so the token is not valid
+// After a rotation of the key, the tokens signed with the previous key are valid for the AccessCodeGracePeriod
+func AuthenticateDGSToken(token string, namespace string, name string) (bool, error) {
+	keys, ok := getCachedDGSTokenSigningKeys(namespace)
+	if !ok {
+		client, _, err := GetClientSet()
+		if err != nil {
+			return false, err
+		}
+		secret, err := client.CoreV1().Secrets(namespace).Get(DGSTokenSigningKeySecretName, meta_v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		key, err := setDGSTokenSigningKeyFromSecret(secret, err)
+		if err != nil {
+			return false, err
+		}
+		keys = namespaceAccessCodes{current: key}
+	}
+	for _, key := range keys.valid() {
+		if ValidateDGSToken(key, token, namespace, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetDGSTokenSigningKey returns the key that signs the DedicatedGameServer tokens of the designated namespace
+// The key is kept in the DGSTokenSigningKeySecretName Secret of each namespace, which is created with a random key if it does not exist
+func GetDGSTokenSigningKey(client kubernetes.Interface, namespace string) (string, error) {
+	if key, ok := getCachedDGSTokenSigningKey(namespace); ok {
+		return key, nil
+	}
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(DGSTokenSigningKeySecretName, meta_v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret, err = createDGSTokenSigningKeySecret(client, namespace)
+		if apierrors.IsAlreadyExists(err) {
+			// another controller instance has just created it
+			secret, err = client.CoreV1().Secrets(namespace).Get(DGSTokenSigningKeySecretName, meta_v1.GetOptions{})
+		}
+	}
+	return setDGSTokenSigningKeyFromSecret(secret, err)
+}
+
+func createDGSTokenSigningKeySecret(client kubernetes.Interface, namespace string) (*corev1.Secret, error) {
+	key := make([]byte, dgsTokenSigningKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return client.CoreV1().Secrets(namespace).Create(&corev1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      DGSTokenSigningKeySecretName,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{"key": key},
+	})
+}
+
+// setDGSTokenSigningKeyFromSecret caches the key of the designated Secret, which has been retrieved with the designated error
+func setDGSTokenSigningKeyFromSecret(secret *corev1.Secret, err error) (string, error) {
+	if err != nil {
+		return "", fmt.Errorf("Cannot get DedicatedGameServer token signing key due to %s", err.Error())
+	}
+	key := string(secret.Data["key"])
+	if key == "" {
+		return "", fmt.Errorf("Secret %s of namespace %s has no key", DGSTokenSigningKeySecretName, secret.Namespace)
+	}
+	setDGSTokenSigningKey(secret.Namespace, key)
+	return key, nil
+}
+
+func getCachedDGSTokenSigningKey(namespace string) (string, bool) {
+	keys, ok := getCachedDGSTokenSigningKeys(namespace)
+	return keys.current, ok
+}
+
+// getCachedDGSTokenSigningKeys returns a copy of the cached signing keys of the namespace
+func getCachedDGSTokenSigningKeys(namespace string) (namespaceAccessCodes, bool) {
+	dgsTokenSigningKeysMutex.RLock()
+	defer dgsTokenSigningKeysMutex.RUnlock()
+	keys, ok := dgsTokenSigningKeys[namespace]
+	if !ok {
+		return namespaceAccessCodes{}, false
+	}
+	return *keys, true
+}
+
+// setDGSTokenSigningKey sets the current signing key of the namespace
+// If the namespace had a different key, it is kept as the previous one for the AccessCodeGracePeriod
+func setDGSTokenSigningKey(namespace string, key string) {
+	dgsTokenSigningKeysMutex.Lock()
+	defer dgsTokenSigningKeysMutex.Unlock()
+
+	keys, ok := dgsTokenSigningKeys[namespace]
+	if !ok {
+		dgsTokenSigningKeys[namespace] = &namespaceAccessCodes{current: key}
+		return
+	}
+	if keys.rotate(key) {
+		log.WithField("Namespace", namespace).Info("DedicatedGameServer token signing key has been rotated")
+	}
+}
+
+func deleteDGSTokenSigningKey(namespace string) {
+	dgsTokenSigningKeysMutex.Lock()
+	defer dgsTokenSigningKeysMutex.Unlock()
+	delete(dgsTokenSigningKeys, namespace)
+}
+
+// WatchDGSTokenSigningKeys watches the DGSTokenSigningKeySecretName Secrets in all namespaces and keeps the cached signing keys up to date
+func WatchDGSTokenSigningKeys(client kubernetes.Interface, stopCh <-chan struct{}) {
+	informerFactory := informers.NewFilteredSharedInformerFactory(client, 30*time.Minute, meta_v1.NamespaceAll, func(options *meta_v1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", DGSTokenSigningKeySecretName).String()
+	})
+
+	informerFactory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			setDGSTokenSigningKeyFromSecret(obj.(*corev1.Secret), nil)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			setDGSTokenSigningKeyFromSecret(newObj.(*corev1.Secret), nil)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				deleteDGSTokenSigningKey(secret.Namespace)
+			}
+		},
+	})
+
+	informerFactory.Start(stopCh)
+}
@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDGSToken(t *testing.T) {
+	token := NewDGSToken("code123!", "title1", "dgs1")
+
+	assert.True(t, ValidateDGSToken("code123!", token, "title1", "dgs1"))
+	assert.False(t, ValidateDGSToken("code123!", token, "title1", "dgs2"))
+	assert.False(t, ValidateDGSToken("code123!", token, "title2", "dgs1"))
+	assert.False(t, ValidateDGSToken("othercode", token, "title1", "dgs1"))
+	assert.False(t, ValidateDGSToken("code123!", "code123!", "title1", "dgs1"))
+	assert.False(t, ValidateDGSToken("code123!", token+"a", "title1", "dgs1"))
+}
+
+func TestNewPodDoesNotExposeAccessCode(t *testing.T) {
+	dgs := NewDedicatedGameServerWithNoParent(GameNamespace, "test", corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "test"}},
+	}, nil)
+
+	pod := NewPod(dgs, APIDetails{APIServerURL: "http://apiserver", TokenSigningKey: "code123!"})
+
+	for _, env := range pod.Spec.Containers[0].Env {
+		if env.Name == "API_SERVER_CODE" {
+			assert.NotEqual(t, "code123!", env.Value)
+			assert.True(t, ValidateDGSToken("code123!", env.Value, GameNamespace, "test"))
+			return
+		}
+	}
+	t.Error("API_SERVER_CODE environment variable was not set")
+}
+
+func TestGetDGSTokenSigningKey(t *testing.T) {
+	client := k8sfake.NewSimpleClientset()
+	deleteDGSTokenSigningKey("signing")
+
+	key, err := GetDGSTokenSigningKey(client, "signing")
+	assert.NoError(t, err)
+	assert.Len(t, key, dgsTokenSigningKeySize)
+
+	secret, err := client.CoreV1().Secrets("signing").Get(DGSTokenSigningKeySecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, key, string(secret.Data["key"]))
+
+	// the key is read from the existing Secret instead of being generated again
+	deleteDGSTokenSigningKey("signing")
+	sameKey, err := GetDGSTokenSigningKey(client, "signing")
+	assert.NoError(t, err)
+	assert.Equal(t, key, sameKey)
+}
+
+func TestDGSTokenSurvivesAccessCodeRotation(t *testing.T) {
+	fakeClock := clockwork.NewFakeClock()
+	accesscodesClock = fakeClock
+	defer func() { accesscodesClock = clockwork.NewRealClock() }()
+
+	deleteAccessCode("rotation")
+	setAccessCode("rotation", "oldcode")
+	setDGSTokenSigningKey("rotation", "signingkey")
+	token := NewDGSToken("signingkey", "rotation", "dgs1")
+
+	setAccessCode("rotation", "newcode")
+	fakeClock.Advance(AccessCodeGracePeriod + time.Second)
+
+	result, err := AuthenticateDGSToken(token, "rotation", "dgs1")
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	result, err = AuthenticateDGSToken(NewDGSToken("newcode", "rotation", "dgs1"), "rotation", "dgs1")
+	assert.NoError(t, err)
+	assert.False(t, result, "Tokens signed with the access code should not be accepted")
+}
+
+func TestDGSTokenSigningKeyRotation(t *testing.T) {
+	fakeClock := clockwork.NewFakeClock()
+	accesscodesClock = fakeClock
+	defer func() { accesscodesClock = clockwork.NewRealClock() }()
+
+	deleteDGSTokenSigningKey("keyrotation")
+	setDGSTokenSigningKey("keyrotation", "oldkey")
+	oldToken := NewDGSToken("oldkey", "keyrotation", "dgs1")
+	setDGSTokenSigningKey("keyrotation", "newkey")
+
+	// the new Pods get tokens signed with the new key
+	key, err := GetDGSTokenSigningKey(nil, "keyrotation")
+	assert.NoError(t, err)
+	assert.Equal(t, "newkey", key)
+
+	result, err := AuthenticateDGSToken(NewDGSToken("newkey", "keyrotation", "dgs1"), "keyrotation", "dgs1")
+	assert.NoError(t, err)
+	assert.True(t, result)
+	result, err = AuthenticateDGSToken(oldToken, "keyrotation", "dgs1")
+	assert.NoError(t, err)
+	assert.True(t, result, "Tokens signed with the previous key should be accepted during the grace period")
+
+	fakeClock.Advance(AccessCodeGracePeriod + time.Second)
+	result, err = AuthenticateDGSToken(oldToken, "keyrotation", "dgs1")
+	assert.NoError(t, err)
+	assert.False(t, result, "Tokens signed with the previous key should not be accepted after the grace period")
+}
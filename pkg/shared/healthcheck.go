@@ -0,0 +1,55 @@
+package shared
+
+import (
+	"strconv"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultHealthCheckPeriodSeconds is the heartbeat period of a DGSHealthCheck that does not set one
+	DefaultHealthCheckPeriodSeconds = 10
+	// DefaultHealthCheckFailureThreshold is the failure threshold of a DGSHealthCheck that does not set one
+	DefaultHealthCheckFailureThreshold = 3
+)
+
+// HealthCheckWithDefaults returns a copy of the DGSHealthCheck with the default values of the omitted fields
+func HealthCheckWithDefaults(healthCheck *dgsv1alpha1.DGSHealthCheck) dgsv1alpha1.DGSHealthCheck {
+	result := *healthCheck
+	if result.PeriodSeconds <= 0 {
+		result.PeriodSeconds = DefaultHealthCheckPeriodSeconds
+	}
+	if result.FailureThreshold <= 0 {
+		result.FailureThreshold = DefaultHealthCheckFailureThreshold
+	}
+	return result
+}
+
+// HeartbeatDeadline returns the time after which the DedicatedGameServer is considered Failed if no heartbeat arrives
+// podStartTime is the time that the Pod of the DedicatedGameServer started at. It returns false if the DedicatedGameServer has no HealthCheck
+func HeartbeatDeadline(dgs *dgsv1alpha1.DedicatedGameServer, podStartTime time.Time) (time.Time, bool) {
+	if dgs.Spec.HealthCheck == nil {
+		return time.Time{}, false
+	}
+	healthCheck := HealthCheckWithDefaults(dgs.Spec.HealthCheck)
+
+	// heartbeats are expected after the initial delay, the ones of a previous Pod are not taken into account
+	reference := podStartTime.Add(time.Duration(healthCheck.InitialDelaySeconds) * time.Second)
+	if dgs.Status.LastHeartbeat != nil && dgs.Status.LastHeartbeat.Time.After(reference) {
+		reference = dgs.Status.LastHeartbeat.Time
+	}
+	return reference.Add(time.Duration(healthCheck.PeriodSeconds*healthCheck.FailureThreshold) * time.Second), true
+}
+
+// getHeartbeatEnvVars returns the environment variable with the heartbeat period, if the DedicatedGameServer has a HealthCheck
+// The SDK sends its heartbeats at this period
+func getHeartbeatEnvVars(dgs *dgsv1alpha1.DedicatedGameServer) []corev1.EnvVar {
+	if dgs.Spec.HealthCheck == nil {
+		return nil
+	}
+	periodSeconds := HealthCheckWithDefaults(dgs.Spec.HealthCheck).PeriodSeconds
+	return []corev1.EnvVar{{Name: EnvHeartbeatPeriodSeconds, Value: strconv.Itoa(int(periodSeconds))}}
+}
@@ -0,0 +1,39 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHeartbeatDeadline(t *testing.T) {
+	dgs := NewDedicatedGameServerWithNoParent(GameNamespace, "test", newValidPodSpec(), []int32{7777})
+	podStartTime := time.Date(2018, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	_, ok := HeartbeatDeadline(dgs, podStartTime)
+	assert.False(t, ok)
+
+	// the defaults are a period of 10 seconds and a threshold of 3
+	dgs.Spec.HealthCheck = &dgsv1alpha1.DGSHealthCheck{InitialDelaySeconds: 60}
+	deadline, ok := HeartbeatDeadline(dgs, podStartTime)
+	assert.True(t, ok)
+	assert.Equal(t, podStartTime.Add(90*time.Second), deadline)
+
+	// a heartbeat before the Pod started is ignored
+	dgs.Status.LastHeartbeat = &metav1.Time{Time: podStartTime.Add(-time.Hour)}
+	deadline, _ = HeartbeatDeadline(dgs, podStartTime)
+	assert.Equal(t, podStartTime.Add(90*time.Second), deadline)
+
+	dgs.Spec.HealthCheck = &dgsv1alpha1.DGSHealthCheck{PeriodSeconds: 5, FailureThreshold: 2}
+	dgs.Status.LastHeartbeat = &metav1.Time{Time: podStartTime.Add(time.Minute)}
+	deadline, _ = HeartbeatDeadline(dgs, podStartTime)
+	assert.Equal(t, podStartTime.Add(70*time.Second), deadline)
+
+	pod := NewPod(dgs, APIDetails{})
+	assert.Contains(t, pod.Spec.Containers[0].Env, corev1.EnvVar{Name: EnvHeartbeatPeriodSeconds, Value: "5"})
+}
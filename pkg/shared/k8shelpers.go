@@ -1,12 +1,14 @@
 package shared
 
 import (
+	"encoding/json"
+
 	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/util/retry"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // NewDedicatedGameServerCollection creates a new DedicatedGameServerCollection with the specified parameters
@@ -48,8 +50,10 @@ func NewDedicatedGameServer(dgsCol *dgsv1alpha1.DedicatedGameServerCollection, t
 			},
 		},
 		Spec: dgsv1alpha1.DedicatedGameServerSpec{
-			Template:      *template.DeepCopy(),
-			PortsToExpose: dgsCol.Spec.PortsToExpose,
+			Template:            *template.DeepCopy(),
+			PortsToExpose:       dgsCol.Spec.PortsToExpose,
+			AddressTypePriority: dgsCol.Spec.AddressTypePriority,
+			HealthCheck:         dgsCol.Spec.HealthCheck.DeepCopy(),
 		},
 		Status: dgsv1alpha1.DedicatedGameServerStatus{
 			Health:        initialHealth,
@@ -85,13 +89,17 @@ func NewDedicatedGameServerWithNoParent(namespace string, name string, template
 }
 
 // APIDetails contains the information that allows our DedicatedGameServer to communicate with the API Server
+// TokenSigningKey is the namespace's DGS token signing key, which is used to sign the DedicatedGameServer's token
 type APIDetails struct {
-	APIServerURL string
-	Code         string
+	APIServerURL    string
+	TokenSigningKey string
 }
 
 // NewPod returns a Kubernetes Pod struct
 // It also sets a label called "DedicatedGameServer" with the value of the corresponding DedicatedGameServer resource
+// Allocated HostPorts are passed to the containers as environment variables, whereas the Node's Public IP
+// is made available (once the Pod is scheduled) via the DGSInfo file mounted on DGSInfoMountPath
+// The containers get a token (in the API_SERVER_CODE environment variable) that only allows updating this DedicatedGameServer
 func NewPod(dgs *dgsv1alpha1.DedicatedGameServer, apiDetails APIDetails) *corev1.Pod {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -107,111 +115,140 @@ func NewPod(dgs *dgsv1alpha1.DedicatedGameServer, apiDetails APIDetails) *corev1
 				}),
 			},
 		},
-		Spec: dgs.Spec.Template,
+		Spec: *dgs.Spec.Template.DeepCopy(),
 	}
 
+	hostPortEnvVars := getHostPortEnvVars(dgs)
+	heartbeatEnvVars := getHeartbeatEnvVars(dgs)
+	token := NewDGSToken(apiDetails.TokenSigningKey, dgs.Namespace, dgs.Name)
+
 	for i := 0; i < len(pod.Spec.Containers); i++ {
 		// assign special ENV
 		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: "SERVER_NAME", Value: dgs.Name})
 		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: "SERVER_NAMESPACE", Value: dgs.Namespace})
 		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: "API_SERVER_URL", Value: apiDetails.APIServerURL})
-		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: "API_SERVER_CODE", Value: apiDetails.Code})
+		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: "API_SERVER_CODE", Value: token})
+		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, hostPortEnvVars...)
+		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, heartbeatEnvVars...)
+		// mount the file that contains the Node's Public IP and the exposed ports
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      DGSInfoVolumeName,
+			MountPath: DGSInfoMountPath,
+			ReadOnly:  true,
+		})
 	}
 
+	pod.Spec.Volumes = append(pod.Spec.Volumes, newDGSInfoVolume())
+
 	pod.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet //https://kubernetes.io/docs/concepts/services-networking/dns-pod-service/
 	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
 
 	return pod
 }
 
-// UpdateActivePlayers updates the active players count for the server with name serverName
-func UpdateActivePlayers(serverName string, namespace string, activePlayers int) error {
-	return UpdateDGSStatus(serverName, namespace, DGSStatusFields{
-		ActivePlayers: &activePlayers,
-	})
-}
-
-func UpdateGameServerMarkedForDeletion(serverName string, namespace string, markedForDeletion bool) error {
-	return UpdateDGSStatus(serverName, namespace, DGSStatusFields{
-		MarkedForDeletion: &markedForDeletion,
-	})
-}
-
-// UpdateGameServerState updates the DedicatedGameServer with the serverName state
-func UpdateGameServerState(serverName string, namespace string, serverState dgsv1alpha1.DGSState) error {
-	return UpdateDGSStatus(serverName, namespace, DGSStatusFields{
-		DGSState: &serverState,
-	})
-}
-
-// UpdateGameServerHealth updates the DedicatedGameServer with the serverName health
-func UpdateGameServerHealth(serverName string, namespace string, serverHealth dgsv1alpha1.DGSHealth) error {
-	return UpdateDGSStatus(serverName, namespace, DGSStatusFields{
-		DGSHealth: &serverHealth,
-	})
-}
-
+// DGSStatusFields contains the DedicatedGameServer Status fields to be updated. Nil fields are not modified
 type DGSStatusFields struct {
 	MarkedForDeletion *bool
 	DGSHealth         *dgsv1alpha1.DGSHealth
 	DGSState          *dgsv1alpha1.DGSState
 	ActivePlayers     *int
+	LastHeartbeat     *metav1.Time
+	// Labels contains the labels to be set. Labels with a nil value are removed
+	Labels map[string]*string
+	// ResourceVersion is a precondition: if it is set, the update fails with a Conflict if the DedicatedGameServer has been modified since this version
+	ResourceVersion string
 }
 
-func UpdateDGSStatus(serverName string, namespace string, fields DGSStatusFields) error {
-	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		_, dgsClient, err := GetClientSet()
-		if err != nil {
-			return err
+// Merge returns the fields with the non-nil values of other applied on top of them
+func (fields DGSStatusFields) Merge(other DGSStatusFields) DGSStatusFields {
+	if other.MarkedForDeletion != nil {
+		fields.MarkedForDeletion = other.MarkedForDeletion
+	}
+	if other.DGSHealth != nil {
+		fields.DGSHealth = other.DGSHealth
+	}
+	if other.DGSState != nil {
+		fields.DGSState = other.DGSState
+	}
+	if other.ActivePlayers != nil {
+		fields.ActivePlayers = other.ActivePlayers
+	}
+	if other.LastHeartbeat != nil {
+		fields.LastHeartbeat = other.LastHeartbeat
+	}
+	if len(other.Labels) > 0 {
+		labels := make(map[string]*string, len(fields.Labels)+len(other.Labels))
+		for k, v := range fields.Labels {
+			labels[k] = v
 		}
-		dgs, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).Get(serverName, metav1.GetOptions{})
-		if err != nil {
-			return err
+		for k, v := range other.Labels {
+			labels[k] = v
 		}
+		fields.Labels = labels
+	}
+	if other.ResourceVersion != "" {
+		fields.ResourceVersion = other.ResourceVersion
+	}
+	return fields
+}
 
-		if fields.DGSHealth != nil {
-			dgs.Status.Health = *fields.DGSHealth
-		}
-		if fields.MarkedForDeletion != nil {
-			dgs.Status.MarkedForDeletion = *fields.MarkedForDeletion
-		}
-		if fields.DGSState != nil {
-			dgs.Status.DGSState = *fields.DGSState
-		}
-		if fields.ActivePlayers != nil {
-			dgs.Status.ActivePlayers = *fields.ActivePlayers
-		}
+// dgsStatusPatch is the JSON merge patch that updates the DGSStatusFields
+type dgsStatusPatch struct {
+	Metadata *dgsMetadataPatch    `json:"metadata,omitempty"`
+	Status   dgsStatusFieldsPatch `json:"status"`
+}
 
-		_, err = dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).Update(dgs)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-	return retryErr
+type dgsMetadataPatch struct {
+	Labels          map[string]*string `json:"labels,omitempty"`
+	ResourceVersion string             `json:"resourceVersion,omitempty"`
+}
+
+type dgsStatusFieldsPatch struct {
+	Health            *dgsv1alpha1.DGSHealth `json:"health,omitempty"`
+	DGSState          *dgsv1alpha1.DGSState  `json:"dgsState,omitempty"`
+	MarkedForDeletion *bool                  `json:"markedForDeletion,omitempty"`
+	ActivePlayers     *int                   `json:"activePlayers,omitempty"`
+	LastHeartbeat     *metav1.Time           `json:"lastHeartbeat,omitempty"`
 }
 
-// GetReadyDGSs returns a list of DGS that are "PodRunning", "Healthy" and not "MarkedForDeletion"
-func GetReadyDGSs() ([]dgsv1alpha1.DedicatedGameServer, error) {
+// NewDGSStatusPatch returns the JSON merge patch (RFC 7386) that updates the designated fields of the DedicatedGameServer
+func NewDGSStatusPatch(fields DGSStatusFields) ([]byte, error) {
+	patch := dgsStatusPatch{
+		Status: dgsStatusFieldsPatch{
+			Health:            fields.DGSHealth,
+			DGSState:          fields.DGSState,
+			MarkedForDeletion: fields.MarkedForDeletion,
+			ActivePlayers:     fields.ActivePlayers,
+			LastHeartbeat:     fields.LastHeartbeat,
+		},
+	}
+	// the Kubernetes API rejects a patch that contains a resourceVersion other than the current one
+	if len(fields.Labels) > 0 || fields.ResourceVersion != "" {
+		patch.Metadata = &dgsMetadataPatch{Labels: fields.Labels, ResourceVersion: fields.ResourceVersion}
+	}
+	return json.Marshal(patch)
+}
+
+// UpdateDGSStatus updates the designated Status fields (and labels) of the DedicatedGameServer and returns the updated object
+// The fields are updated with a single JSON merge patch, so there is no need to get the object first or to retry on conflicts,
+// unless the fields contain a ResourceVersion precondition
+func UpdateDGSStatus(serverName string, namespace string, fields DGSStatusFields) (*dgsv1alpha1.DedicatedGameServer, error) {
 	_, dgsClient, err := GetClientSet()
 	if err != nil {
 		return nil, err
 	}
 
-	dgss, err := dgsClient.AzuregamingV1alpha1().DedicatedGameServers(GameNamespace).List(metav1.ListOptions{})
+	patch, err := NewDGSStatusPatch(fields)
 	if err != nil {
 		return nil, err
 	}
 
-	dgsToReturn := make([]dgsv1alpha1.DedicatedGameServer, 0)
-
-	for _, dgs := range dgss.Items {
-		if dgs.Status.Health == dgsv1alpha1.DGSHealthy &&
-			dgs.Status.PodPhase == corev1.PodRunning &&
-			!dgs.Status.MarkedForDeletion {
-			dgsToReturn = append(dgsToReturn, dgs)
-		}
-	}
+	return dgsClient.AzuregamingV1alpha1().DedicatedGameServers(namespace).Patch(serverName, types.MergePatchType, patch)
+}
 
-	return dgsToReturn, nil
+// IsDGSReady returns true if the DedicatedGameServer is "PodRunning", "Healthy" and not "MarkedForDeletion"
+func IsDGSReady(dgs *dgsv1alpha1.DedicatedGameServer) bool {
+	return dgs.Status.Health == dgsv1alpha1.DGSHealthy &&
+		dgs.Status.PodPhase == corev1.PodRunning &&
+		!dgs.Status.MarkedForDeletion
 }
@@ -0,0 +1,46 @@
+package shared
+
+import (
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDGSStatusPatch(t *testing.T) {
+	health := dgsv1alpha1.DGSHealthy
+	players := 0
+	patch, err := NewDGSStatusPatch(DGSStatusFields{DGSHealth: &health, ActivePlayers: &players})
+	assert.NoError(t, err)
+	// zero values are kept, since the fields are set
+	assert.JSONEq(t, `{"status":{"health":"Healthy","activePlayers":0}}`, string(patch))
+
+	mode := "ranked"
+	patch, err = NewDGSStatusPatch(DGSStatusFields{Labels: map[string]*string{"mode": &mode, "map": nil}})
+	assert.NoError(t, err)
+	// null removes the label
+	assert.JSONEq(t, `{"metadata":{"labels":{"mode":"ranked","map":null}},"status":{}}`, string(patch))
+
+	state := dgsv1alpha1.DGSAssigned
+	patch, err = NewDGSStatusPatch(DGSStatusFields{DGSState: &state, ResourceVersion: "42"})
+	assert.NoError(t, err)
+	// the labels are not touched by a precondition
+	assert.JSONEq(t, `{"metadata":{"resourceVersion":"42"},"status":{"dgsState":"Assigned"}}`, string(patch))
+}
+
+func TestMergeDGSStatusFields(t *testing.T) {
+	players1, players2 := 5, 6
+	health := dgsv1alpha1.DGSHealthy
+	mode, region := "ranked", "eu"
+
+	fields := DGSStatusFields{ActivePlayers: &players1, DGSHealth: &health, Labels: map[string]*string{"mode": &mode}}
+	merged := fields.Merge(DGSStatusFields{ActivePlayers: &players2, Labels: map[string]*string{"region": &region}})
+
+	assert.Equal(t, 6, *merged.ActivePlayers)
+	assert.Equal(t, dgsv1alpha1.DGSHealthy, *merged.DGSHealth)
+	assert.Len(t, merged.Labels, 2)
+	// the original fields are not modified
+	assert.Len(t, fields.Labels, 1)
+	assert.Equal(t, 5, *fields.ActivePlayers)
+}
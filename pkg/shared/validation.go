@@ -0,0 +1,168 @@
+package shared
+
+import (
+	"fmt"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateDedicatedGameServerCollection checks the DedicatedGameServerCollection Spec for errors
+// The same rules are used by the admission webhook and by the API Server
+func ValidateDedicatedGameServerCollection(dgsCol *dgsv1alpha1.DedicatedGameServerCollection) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if dgsCol.Name == "" && dgsCol.GenerateName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("metadata", "name"), "name or generateName is required"))
+	}
+
+	if dgsCol.Spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), dgsCol.Spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if dgsCol.Spec.DGSFailBehavior != "" && dgsCol.Spec.DGSFailBehavior != dgsv1alpha1.Delete && dgsCol.Spec.DGSFailBehavior != dgsv1alpha1.Remove {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("dgsFailBehavior"), dgsCol.Spec.DGSFailBehavior,
+			[]string{string(dgsv1alpha1.Delete), string(dgsv1alpha1.Remove)}))
+	}
+
+	if dgsCol.Spec.DGSMaxFailures < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("dgsMaxFailures"), dgsCol.Spec.DGSMaxFailures, "must be greater than or equal to 0"))
+	}
+
+	if details := dgsCol.Spec.DGSActivePlayersAutoScalerDetails; details != nil && details.Enabled {
+		allErrs = append(allErrs, validateAutoScalerDetails(details, specPath.Child("dgsActivePlayersAutoScalerDetails"))...)
+	}
+
+	allErrs = append(allErrs, validateAddressTypePriority(dgsCol.Spec.AddressTypePriority, specPath.Child("addressTypePriority"))...)
+	allErrs = append(allErrs, validateHealthCheck(dgsCol.Spec.HealthCheck, specPath.Child("healthCheck"))...)
+	allErrs = append(allErrs, validatePodSpec(dgsCol.Spec.Template, dgsCol.Spec.PortsToExpose, specPath)...)
+
+	return allErrs
+}
+
+// ValidateDedicatedGameServer checks the DedicatedGameServer Spec for errors
+// The same rules are used by the admission webhook and by the API Server
+func ValidateDedicatedGameServer(dgs *dgsv1alpha1.DedicatedGameServer) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if dgs.Name == "" && dgs.GenerateName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("metadata", "name"), "name or generateName is required"))
+	}
+
+	allErrs = append(allErrs, validateAddressTypePriority(dgs.Spec.AddressTypePriority, field.NewPath("spec", "addressTypePriority"))...)
+	allErrs = append(allErrs, validateHealthCheck(dgs.Spec.HealthCheck, field.NewPath("spec", "healthCheck"))...)
+	allErrs = append(allErrs, validatePodSpec(dgs.Spec.Template, dgs.Spec.PortsToExpose, field.NewPath("spec"))...)
+
+	return allErrs
+}
+
+// validateHealthCheck checks that the fields of the DGSHealthCheck are not negative, zero values get the defaults
+func validateHealthCheck(healthCheck *dgsv1alpha1.DGSHealthCheck, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if healthCheck == nil {
+		return allErrs
+	}
+
+	if healthCheck.PeriodSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("periodSeconds"), healthCheck.PeriodSeconds, "must be greater than or equal to 0"))
+	}
+	if healthCheck.FailureThreshold < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failureThreshold"), healthCheck.FailureThreshold, "must be greater than or equal to 0"))
+	}
+	if healthCheck.InitialDelaySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("initialDelaySeconds"), healthCheck.InitialDelaySeconds, "must be greater than or equal to 0"))
+	}
+
+	return allErrs
+}
+
+func validateAutoScalerDetails(details *dgsv1alpha1.DGSActivePlayersAutoScalerDetails, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if details.MinimumReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minimumReplicas"), details.MinimumReplicas, "must be greater than 0"))
+	}
+	if details.MaximumReplicas < details.MinimumReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maximumReplicas"), details.MaximumReplicas, "must be greater than or equal to minimumReplicas"))
+	}
+	if details.ScaleInThreshold < 1 || details.ScaleInThreshold > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("scaleInThreshold"), details.ScaleInThreshold, "must be between 1 and 100"))
+	}
+	if details.ScaleOutThreshold < 1 || details.ScaleOutThreshold > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("scaleOutThreshold"), details.ScaleOutThreshold, "must be between 1 and 100"))
+	}
+	if details.ScaleInThreshold >= details.ScaleOutThreshold {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("scaleInThreshold"), details.ScaleInThreshold, "must be less than scaleOutThreshold"))
+	}
+	if details.CoolDownInMinutes < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("coolDownInMinutes"), details.CoolDownInMinutes, "must be greater than or equal to 0"))
+	}
+	if details.MaxPlayersPerServer < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxPlayersPerServer"), details.MaxPlayersPerServer, "must be greater than 0"))
+	}
+
+	return allErrs
+}
+
+func validateAddressTypePriority(priority []corev1.NodeAddressType, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	supported := []string{string(corev1.NodeExternalIP), string(corev1.NodeInternalIP), string(corev1.NodeExternalDNS), string(corev1.NodeInternalDNS), string(corev1.NodeHostName)}
+
+	for i, addressType := range priority {
+		found := false
+		for _, s := range supported {
+			if string(addressType) == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), addressType, supported))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePodSpec checks that all the containers have CPU and Memory requests and limits set
+// and that all the PortsToExpose refer to a ContainerPort
+func validatePodSpec(podSpec corev1.PodSpec, portsToExpose []int32, specPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	containersPath := specPath.Child("template", "containers")
+
+	if len(podSpec.Containers) == 0 {
+		allErrs = append(allErrs, field.Required(containersPath, "at least one container is required"))
+	}
+
+	containerPorts := make([]int32, 0)
+	for i, container := range podSpec.Containers {
+		resourcesPath := containersPath.Index(i).Child("resources")
+
+		//check for requests
+		if container.Resources.Requests.Cpu().IsZero() || container.Resources.Requests.Memory().IsZero() {
+			allErrs = append(allErrs, field.Required(resourcesPath.Child("requests"),
+				fmt.Sprintf("Container called %s does not have Cpu and/or Memory requests defined", container.Name)))
+		}
+
+		//check for limits
+		if container.Resources.Limits.Cpu().IsZero() || container.Resources.Limits.Memory().IsZero() {
+			allErrs = append(allErrs, field.Required(resourcesPath.Child("limits"),
+				fmt.Sprintf("Container called %s does not have Cpu and/or Memory limits defined", container.Name)))
+		}
+
+		for _, port := range container.Ports {
+			containerPorts = append(containerPorts, port.ContainerPort)
+		}
+	}
+
+	for i, port := range portsToExpose {
+		if !SliceContains(containerPorts, port) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("portsToExpose").Index(i), port, "must be a ContainerPort of the template"))
+		}
+	}
+
+	return allErrs
+}
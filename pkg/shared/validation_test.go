@@ -0,0 +1,73 @@
+package shared
+
+import (
+	"testing"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func newValidPodSpec() corev1.PodSpec {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("64Mi"),
+	}
+	return corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:      "game",
+				Ports:     []corev1.ContainerPort{{ContainerPort: 7777}},
+				Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources},
+			},
+		},
+	}
+}
+
+func TestValidateDedicatedGameServerCollection(t *testing.T) {
+	dgsCol := NewDedicatedGameServerCollection("test", GameNamespace, 2, newValidPodSpec())
+	dgsCol.Spec.PortsToExpose = []int32{7777}
+	assert.Empty(t, ValidateDedicatedGameServerCollection(dgsCol))
+
+	invalid := dgsCol.DeepCopy()
+	invalid.Spec.Replicas = -1
+	invalid.Spec.PortsToExpose = []int32{8888}
+	invalid.Spec.DGSFailBehavior = "Foo"
+	invalid.Spec.AddressTypePriority = []corev1.NodeAddressType{"Foo"}
+	invalid.Spec.DGSActivePlayersAutoScalerDetails = &dgsv1alpha1.DGSActivePlayersAutoScalerDetails{
+		Enabled:           true,
+		MinimumReplicas:   3,
+		MaximumReplicas:   1,
+		ScaleInThreshold:  60,
+		ScaleOutThreshold: 80,
+	}
+	invalid.Spec.HealthCheck = &dgsv1alpha1.DGSHealthCheck{PeriodSeconds: -1}
+	errs := ValidateDedicatedGameServerCollection(invalid)
+	fields := make([]string, 0)
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"spec.replicas",
+		"spec.portsToExpose[0]",
+		"spec.dgsFailBehavior",
+		"spec.addressTypePriority[0]",
+		"spec.dgsActivePlayersAutoScalerDetails.maximumReplicas",
+		"spec.dgsActivePlayersAutoScalerDetails.maxPlayersPerServer",
+		"spec.healthCheck.periodSeconds",
+	}, fields)
+}
+
+func TestValidateDedicatedGameServerRequiresResources(t *testing.T) {
+	dgs := NewDedicatedGameServerWithNoParent(GameNamespace, "test", newValidPodSpec(), []int32{7777})
+	assert.Empty(t, ValidateDedicatedGameServer(dgs))
+
+	dgs.Spec.Template.Containers[0].Resources = corev1.ResourceRequirements{}
+	errs := ValidateDedicatedGameServer(dgs)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.template.containers[0].resources.requests", errs[0].Field)
+	assert.Equal(t, "spec.template.containers[0].resources.limits", errs[1].Field)
+}
@@ -0,0 +1,88 @@
+package sidecar
+
+import (
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	grpcapi "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/grpcapi"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sidecar/sidecarapi"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultGRPCPort is the port of the gRPC API of the sidecar on localhost
+const DefaultGRPCPort = 9359
+
+// grpcService implements the SDK service of the gRPC API of the sidecar (see sidecarapi/sidecar.proto)
+// Its methods apply the same operations as the HTTP methods, so the updates of both APIs are sent in the same batches
+type grpcService struct {
+	server *Server
+}
+
+// GRPCServer returns the gRPC server of the sidecar
+func (s *Server) GRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	sidecarapi.RegisterSDKServer(server, &grpcService{server: s})
+	return server
+}
+
+// Ready sets the Health to Healthy and starts the health pings
+func (g *grpcService) Ready(ctx context.Context, request *sidecarapi.Empty) (*sidecarapi.Empty, error) {
+	g.server.markReady()
+	return &sidecarapi.Empty{}, nil
+}
+
+// Health is a health ping of the game server
+func (g *grpcService) Health(ctx context.Context, request *sidecarapi.Empty) (*sidecarapi.Empty, error) {
+	g.server.healthPing()
+	return &sidecarapi.Empty{}, nil
+}
+
+// SetState sets the state of the DedicatedGameServer
+func (g *grpcService) SetState(ctx context.Context, request *sidecarapi.SetStateRequest) (*sidecarapi.Empty, error) {
+	if err := g.server.setState(dgsv1alpha1.DGSState(grpcapi.DGSState(request.State))); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &sidecarapi.Empty{}, nil
+}
+
+// SetPlayers sets the number of active players of the DedicatedGameServer
+func (g *grpcService) SetPlayers(ctx context.Context, request *sidecarapi.SetPlayersRequest) (*sidecarapi.Empty, error) {
+	if err := g.server.setPlayers(int(request.PlayerCount)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &sidecarapi.Empty{}, nil
+}
+
+// PlayerConnect adds the player to the active players
+func (g *grpcService) PlayerConnect(ctx context.Context, request *sidecarapi.PlayerRequest) (*sidecarapi.Empty, error) {
+	return g.updatePlayer(request.PlayerId, true)
+}
+
+// PlayerDisconnect removes the player from the active players
+func (g *grpcService) PlayerDisconnect(ctx context.Context, request *sidecarapi.PlayerRequest) (*sidecarapi.Empty, error) {
+	return g.updatePlayer(request.PlayerId, false)
+}
+
+func (g *grpcService) updatePlayer(playerID string, connected bool) (*sidecarapi.Empty, error) {
+	if err := g.server.updatePlayer(playerID, connected); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &sidecarapi.Empty{}, nil
+}
+
+// Shutdown sets MarkedForDeletion
+func (g *grpcService) Shutdown(ctx context.Context, request *sidecarapi.Empty) (*sidecarapi.Empty, error) {
+	g.server.shutdown()
+	return &sidecarapi.Empty{}, nil
+}
+
+// GetDedicatedGameServer returns the latest version of the DedicatedGameServer
+func (g *grpcService) GetDedicatedGameServer(ctx context.Context, request *sidecarapi.Empty) (*grpcapi.DedicatedGameServer, error) {
+	dgs := g.server.currentDGS()
+	if dgs == nil {
+		return nil, status.Error(codes.Unavailable, "the DedicatedGameServer has not been read yet")
+	}
+	return grpcapi.FromDGS(dgs), nil
+}
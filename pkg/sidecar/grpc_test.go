@@ -0,0 +1,92 @@
+package sidecar
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	grpcapi "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/grpcapi"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk/fake"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sidecar/sidecarapi"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startTestGRPCServer serves the gRPC API of a sidecar on a local port and returns a client of it, along with a function that stops both
+func startTestGRPCServer(t *testing.T, config Config) (*fake.SDK, sidecarapi.SDKClient, func()) {
+	fakeSDK := fake.NewSDK("default", "dgs1")
+	server := NewServer(fakeSDK, config)
+	stopCh := make(chan struct{})
+	go server.Run(stopCh)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return fakeSDK, sidecarapi.NewSDKClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+		close(stopCh)
+	}
+}
+
+func TestSidecarGRPCSendsUpdates(t *testing.T) {
+	fakeSDK, client, stop := startTestGRPCServer(t, Config{BatchInterval: time.Millisecond})
+	defer stop()
+	ctx := context.Background()
+
+	_, err := client.Ready(ctx, &sidecarapi.Empty{})
+	assert.NoError(t, err)
+	_, err = client.SetState(ctx, &sidecarapi.SetStateRequest{State: grpcapi.State_ASSIGNED})
+	assert.NoError(t, err)
+	_, err = client.PlayerConnect(ctx, &sidecarapi.PlayerRequest{PlayerId: "alice"})
+	assert.NoError(t, err)
+	_, err = client.PlayerConnect(ctx, &sidecarapi.PlayerRequest{PlayerId: "bob"})
+	assert.NoError(t, err)
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool {
+		return status.Health == dgsv1alpha1.DGSHealthy && status.DGSState == dgsv1alpha1.DGSAssigned && status.ActivePlayers == 2
+	})
+
+	_, err = client.PlayerDisconnect(ctx, &sidecarapi.PlayerRequest{PlayerId: "alice"})
+	assert.NoError(t, err)
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.ActivePlayers == 1 })
+	_, err = client.SetPlayers(ctx, &sidecarapi.SetPlayersRequest{PlayerCount: 7})
+	assert.NoError(t, err)
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.ActivePlayers == 7 })
+
+	// the DedicatedGameServer is watched
+	dgs, err := client.GetDedicatedGameServer(ctx, &sidecarapi.Empty{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "dgs1", dgs.Name)
+		assert.Equal(t, int32(7), dgs.Status.ActivePlayers)
+	}
+
+	_, err = client.Shutdown(ctx, &sidecarapi.Empty{})
+	assert.NoError(t, err)
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.MarkedForDeletion })
+}
+
+func TestSidecarGRPCValidatesRequests(t *testing.T) {
+	_, client, stop := startTestGRPCServer(t, Config{})
+	defer stop()
+	ctx := context.Background()
+
+	_, err := client.SetState(ctx, &sidecarapi.SetStateRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.SetPlayers(ctx, &sidecarapi.SetPlayersRequest{PlayerCount: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.PlayerConnect(ctx, &sidecarapi.PlayerRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,420 @@
+// Package sidecar contains the SDK sidecar, which exposes the SDK operations on localhost to game servers written in any language
+// The game server does not need to know the URL of the API Server or the token of the DedicatedGameServer
+package sidecar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	helpers "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/helpers"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk"
+	shared "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/shared"
+
+	logrus "github.com/sirupsen/logrus"
+)
+
+// DefaultPort is the port that the sidecar listens at on localhost
+const DefaultPort = 9358
+
+const (
+	defaultBatchInterval      = 100 * time.Millisecond
+	defaultHealthPingInterval = 30 * time.Second
+	defaultHealthTimeout      = time.Minute
+	retryBaseDelay            = 500 * time.Millisecond
+	retryMaxDelay             = 30 * time.Second
+)
+
+// Config contains the settings of the sidecar
+type Config struct {
+	// BatchInterval is the time that the updates are collected for before they are sent to the API Server in one request, default is 100ms
+	BatchInterval time.Duration
+	// HealthPingInterval is the interval that the Health of the DedicatedGameServer is reported at, after the game server is ready, default is 30s
+	HealthPingInterval time.Duration
+	// HealthTimeout is the time after the last POST /health of the game server that the DedicatedGameServer is reported as Failed, default is 1m
+	// Game servers that never call POST /health are reported as Healthy as long as the sidecar runs
+	HealthTimeout time.Duration
+	// Heartbeats makes the sidecar send heartbeats instead of the Healthy Health, which is required for DedicatedGameServers with a HealthCheck
+	// The heartbeats stop when the game server stops sending health pings, so the DedicatedGameServer controller sets the Health to Failed
+	Heartbeats bool
+}
+
+// Server is the sidecar, it accepts the updates of the game server and sends them to the API Server in batches
+// Updates are accepted immediately, the ones that fail with a retryable error are retried with exponential backoff
+type Server struct {
+	sdk    sdk.Interface
+	config Config
+	logger *logrus.Logger
+
+	mutex sync.Mutex
+	// pending contains the changes that have not been sent yet, newer changes of the same field replace the older ones
+	pending    helpers.DGSUpdate
+	hasPending bool
+	players    map[string]bool
+	ready      bool
+	// lastHealthPing is the time of the last POST /health, zero if the game server does not send health pings
+	lastHealthPing time.Time
+	dgs            *dgsv1alpha1.DedicatedGameServer
+
+	flushCh chan struct{}
+}
+
+// NewServer returns a sidecar that sends the updates via the SDK
+// The omitted settings of the Config get their default values
+func NewServer(s sdk.Interface, config Config) *Server {
+	if config.BatchInterval <= 0 {
+		config.BatchInterval = defaultBatchInterval
+	}
+	if config.HealthPingInterval <= 0 {
+		config.HealthPingInterval = defaultHealthPingInterval
+	}
+	if config.HealthTimeout <= 0 {
+		config.HealthTimeout = defaultHealthTimeout
+	}
+	return &Server{
+		sdk:     s,
+		config:  config,
+		logger:  shared.Logger(),
+		players: make(map[string]bool),
+		flushCh: make(chan struct{}, 1),
+	}
+}
+
+// Handler returns the HTTP handler of the sidecar
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", s.post(s.readyHandler))
+	mux.HandleFunc("/health", s.post(s.healthHandler))
+	mux.HandleFunc("/state", s.post(s.stateHandler))
+	mux.HandleFunc("/players", s.post(s.playersHandler))
+	mux.HandleFunc("/players/connect", s.post(s.playerConnectHandler))
+	mux.HandleFunc("/players/disconnect", s.post(s.playerDisconnectHandler))
+	mux.HandleFunc("/shutdown", s.post(s.shutdownHandler))
+	mux.HandleFunc("/dedicatedgameserver", s.dgsHandler)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// Run sends the pending updates and the health pings until stopCh is closed
+// The DedicatedGameServer is watched, so the game server can read it via GET /dedicatedgameserver
+func (s *Server) Run(stopCh <-chan struct{}) {
+	if err := s.sdk.WatchSelf(s.setDGS); err != nil {
+		s.logger.Errorf("Cannot watch the DedicatedGameServer: %s", err.Error())
+	}
+	defer s.sdk.Close()
+
+	pings := time.NewTicker(s.config.HealthPingInterval)
+	defer pings.Stop()
+	var retry <-chan time.Time
+	delay := retryBaseDelay
+
+	for {
+		select {
+		case <-stopCh:
+			// the pending updates are sent before the sidecar exits
+			s.flush()
+			return
+		case <-pings.C:
+			s.ping()
+			continue
+		case <-s.flushCh:
+			if retry != nil {
+				// the failed updates are sent along with the new ones when the retry is due
+				continue
+			}
+			// collect the updates that follow shortly
+			select {
+			case <-time.After(s.config.BatchInterval):
+			case <-stopCh:
+			}
+		case <-retry:
+		}
+
+		retry = nil
+		if s.flush() {
+			delay = retryBaseDelay
+			continue
+		}
+		retry = time.After(delay)
+		if delay *= 2; delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
+
+// flush sends the pending updates, it returns false if they have to be retried
+func (s *Server) flush() bool {
+	s.mutex.Lock()
+	update, hasPending := s.pending, s.hasPending
+	s.pending, s.hasPending = helpers.DGSUpdate{}, false
+	s.mutex.Unlock()
+	if !hasPending {
+		return true
+	}
+
+	err := s.sdk.Update(update)
+	if err == nil {
+		return true
+	}
+	if !sdk.IsRetryable(err) {
+		s.logger.Errorf("The API Server rejected the update of the DedicatedGameServer: %s", err.Error())
+		return true
+	}
+	s.logger.Warnf("Cannot update the DedicatedGameServer, will retry: %s", err.Error())
+
+	// the changes that were made in the meantime are newer than the failed ones
+	s.mutex.Lock()
+	s.pending, s.hasPending = merge(update, s.pending), true
+	s.mutex.Unlock()
+	return false
+}
+
+// merge returns the update with the non-nil fields of newer applied to it
+func merge(update, newer helpers.DGSUpdate) helpers.DGSUpdate {
+	if newer.Health != nil {
+		update.Health = newer.Health
+	}
+	if newer.State != nil {
+		update.State = newer.State
+	}
+	if newer.MarkedForDeletion != nil {
+		update.MarkedForDeletion = newer.MarkedForDeletion
+	}
+	if newer.ActivePlayers != nil {
+		update.ActivePlayers = newer.ActivePlayers
+	}
+	return update
+}
+
+// queue adds the changes to the pending updates and wakes up the sender
+// It must be called with the mutex held
+func (s *Server) queue(changes helpers.DGSUpdate) {
+	s.pending, s.hasPending = merge(s.pending, changes), true
+	select {
+	case s.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+// ping reports the Health of the game server, if it is ready
+// A healthy game server is reported with a heartbeat if the Heartbeats setting is true, otherwise its Health is queued
+func (s *Server) ping() {
+	s.mutex.Lock()
+	if !s.ready {
+		s.mutex.Unlock()
+		return
+	}
+	healthy := s.lastHealthPing.IsZero() || time.Since(s.lastHealthPing) <= s.config.HealthTimeout
+	if !healthy || !s.config.Heartbeats {
+		health := string(dgsv1alpha1.DGSHealthy)
+		if !healthy {
+			health = string(dgsv1alpha1.DGSFailed)
+		}
+		s.queue(helpers.DGSUpdate{Health: &health})
+		s.mutex.Unlock()
+		return
+	}
+	s.mutex.Unlock()
+
+	// a failed heartbeat is not queued, the next one is sent on the next tick
+	if err := s.sdk.Heartbeat(); err != nil {
+		s.logger.Warnf("Cannot send the heartbeat of the DedicatedGameServer: %s", err.Error())
+	}
+}
+
+func (s *Server) setDGS(dgs *dgsv1alpha1.DedicatedGameServer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dgs = dgs
+}
+
+// post wraps the handler so that it only accepts POST requests
+func (s *Server) post(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "only POST is allowed")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// markReady sets the Health of the DedicatedGameServer to Healthy and starts the health pings
+func (s *Server) markReady() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ready = true
+	health := string(dgsv1alpha1.DGSHealthy)
+	s.queue(helpers.DGSUpdate{Health: &health})
+}
+
+// healthPing records a health ping of the game server
+func (s *Server) healthPing() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastHealthPing = time.Now()
+}
+
+// setState queues the state of the DedicatedGameServer, it returns an error if the state is not valid
+func (s *Server) setState(state dgsv1alpha1.DGSState) error {
+	if !state.IsValid() {
+		return fmt.Errorf("Wrong value for state: %s", state)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value := string(state)
+	s.queue(helpers.DGSUpdate{State: &value})
+	return nil
+}
+
+// setPlayers queues the number of active players and forgets the connected players, it returns an error if the number is negative
+func (s *Server) setPlayers(playerCount int) error {
+	if playerCount < 0 {
+		return errors.New("playerCount must not be negative")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.players = make(map[string]bool)
+	s.queue(helpers.DGSUpdate{ActivePlayers: &playerCount})
+	return nil
+}
+
+// updatePlayer adds or removes the player and queues the number of active players, if it changed
+// It returns an error if the player ID is empty
+func (s *Server) updatePlayer(playerID string, connected bool) error {
+	if playerID == "" {
+		return errors.New("playerID is required")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.players[playerID] != connected {
+		if connected {
+			s.players[playerID] = true
+		} else {
+			delete(s.players, playerID)
+		}
+		count := len(s.players)
+		s.queue(helpers.DGSUpdate{ActivePlayers: &count})
+	}
+	return nil
+}
+
+// shutdown queues MarkedForDeletion
+func (s *Server) shutdown() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	markedForDeletion := true
+	s.queue(helpers.DGSUpdate{MarkedForDeletion: &markedForDeletion})
+}
+
+// currentDGS returns the latest version of the DedicatedGameServer, nil if it has not been read yet
+func (s *Server) currentDGS() *dgsv1alpha1.DedicatedGameServer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dgs
+}
+
+func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
+	s.markReady()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	s.healthPing()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) stateHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		State dgsv1alpha1.DGSState `json:"state"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if err := s.setState(body.State); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) playersHandler(w http.ResponseWriter, r *http.Request) {
+	var body helpers.DGSPlayers
+	if !decode(w, r, &body) {
+		return
+	}
+	if err := s.setPlayers(body.PlayerCount); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type playerRequest struct {
+	PlayerID string `json:"playerID"`
+}
+
+func (s *Server) playerConnectHandler(w http.ResponseWriter, r *http.Request) {
+	s.updatePlayers(w, r, true)
+}
+
+func (s *Server) playerDisconnectHandler(w http.ResponseWriter, r *http.Request) {
+	s.updatePlayers(w, r, false)
+}
+
+// updatePlayers adds or removes the player of the request
+func (s *Server) updatePlayers(w http.ResponseWriter, r *http.Request, connected bool) {
+	var body playerRequest
+	if !decode(w, r, &body) {
+		return
+	}
+	if err := s.updatePlayer(body.PlayerID, connected); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) shutdownHandler(w http.ResponseWriter, r *http.Request) {
+	s.shutdown()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// dgsHandler returns the latest version of the DedicatedGameServer
+func (s *Server) dgsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "only GET is allowed")
+		return
+	}
+	dgs := s.currentDGS()
+	if dgs == nil {
+		writeError(w, http.StatusServiceUnavailable, "the DedicatedGameServer has not been read yet")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dgs)
+}
+
+// decode decodes the JSON request body, it writes a BadRequest response and returns false if it is invalid
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Incorrect arguments: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(helpers.APIError{Code: code, Reason: http.StatusText(code), Message: message})
+}
@@ -0,0 +1,146 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dgsv1alpha1 "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apis/azuregaming/v1alpha1"
+	"github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sdk/fake"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func startTestServer(config Config) (*fake.SDK, *httptest.Server, chan struct{}) {
+	fakeSDK := fake.NewSDK("default", "dgs1")
+	server := NewServer(fakeSDK, config)
+	stopCh := make(chan struct{})
+	go server.Run(stopCh)
+	return fakeSDK, httptest.NewServer(server.Handler()), stopCh
+}
+
+func post(t *testing.T, server *httptest.Server, path string, body string) int {
+	resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// waitForStatus waits until the DedicatedGameServer Status of the fake SDK passes the condition
+func waitForStatus(t *testing.T, fakeSDK *fake.SDK, condition func(dgsv1alpha1.DedicatedGameServerStatus) bool) {
+	err := wait.Poll(5*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return condition(fakeSDK.DedicatedGameServer().Status), nil
+	})
+	assert.NoError(t, err, "Timed out waiting for the DedicatedGameServer Status, it is %+v", fakeSDK.DedicatedGameServer().Status)
+}
+
+func TestSidecarSendsUpdates(t *testing.T) {
+	fakeSDK, server, stopCh := startTestServer(Config{BatchInterval: time.Millisecond})
+	defer server.Close()
+	defer close(stopCh)
+
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/ready", ""))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/state", `{"state":"Assigned"}`))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players/connect", `{"playerID":"alice"}`))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players/connect", `{"playerID":"bob"}`))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players/connect", `{"playerID":"bob"}`))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool {
+		return status.Health == dgsv1alpha1.DGSHealthy && status.DGSState == dgsv1alpha1.DGSAssigned && status.ActivePlayers == 2
+	})
+
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players/disconnect", `{"playerID":"alice"}`))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.ActivePlayers == 1 })
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players", `{"playerCount":7}`))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.ActivePlayers == 7 })
+
+	// the DedicatedGameServer is watched
+	resp, err := http.Get(server.URL + "/dedicatedgameserver")
+	if assert.NoError(t, err) {
+		var dgs dgsv1alpha1.DedicatedGameServer
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&dgs))
+		resp.Body.Close()
+		assert.Equal(t, "dgs1", dgs.Name)
+	}
+
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/shutdown", ""))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.MarkedForDeletion })
+}
+
+func TestSidecarValidatesRequests(t *testing.T) {
+	_, server, stopCh := startTestServer(Config{})
+	defer server.Close()
+	defer close(stopCh)
+
+	assert.Equal(t, http.StatusBadRequest, post(t, server, "/state", `{"state":"Sleeping"}`))
+	assert.Equal(t, http.StatusBadRequest, post(t, server, "/players", `{"playerCount":-1}`))
+	assert.Equal(t, http.StatusBadRequest, post(t, server, "/players/connect", `{}`))
+	assert.Equal(t, http.StatusBadRequest, post(t, server, "/players", `not json`))
+
+	resp, err := http.Get(server.URL + "/ready")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestSidecarRetriesFailedUpdates(t *testing.T) {
+	fakeSDK, server, stopCh := startTestServer(Config{BatchInterval: time.Millisecond})
+	defer server.Close()
+	defer close(stopCh)
+
+	fakeSDK.SetError(assert.AnError)
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players", `{"playerCount":3}`))
+	time.Sleep(50 * time.Millisecond)
+	// newer changes of the same field replace the failed ones
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/players", `{"playerCount":4}`))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/state", `{"state":"Running"}`))
+	fakeSDK.SetError(nil)
+
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool {
+		return status.ActivePlayers == 4 && status.DGSState == dgsv1alpha1.DGSRunning
+	})
+}
+
+func TestSidecarHealthPings(t *testing.T) {
+	fakeSDK, server, stopCh := startTestServer(Config{BatchInterval: time.Millisecond, HealthPingInterval: 10 * time.Millisecond, HealthTimeout: 50 * time.Millisecond})
+	defer server.Close()
+	defer close(stopCh)
+
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/health", ""))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/ready", ""))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool {
+		return status.Health == dgsv1alpha1.DGSHealthy
+	})
+
+	// the game server stopped sending health pings
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.Health == dgsv1alpha1.DGSFailed })
+
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/health", ""))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool {
+		return status.Health == dgsv1alpha1.DGSHealthy
+	})
+}
+
+func TestSidecarSendsHeartbeats(t *testing.T) {
+	fakeSDK, server, stopCh := startTestServer(Config{BatchInterval: time.Millisecond, HealthPingInterval: 10 * time.Millisecond, HealthTimeout: 50 * time.Millisecond, Heartbeats: true})
+	defer server.Close()
+	defer close(stopCh)
+
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/health", ""))
+	assert.Equal(t, http.StatusAccepted, post(t, server, "/ready", ""))
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool {
+		return status.Health == dgsv1alpha1.DGSHealthy && status.LastHeartbeat != nil
+	})
+
+	// the game server stopped sending health pings, so the heartbeats stop as well
+	waitForStatus(t, fakeSDK, func(status dgsv1alpha1.DedicatedGameServerStatus) bool { return status.Health == dgsv1alpha1.DGSFailed })
+	lastHeartbeat := fakeSDK.DedicatedGameServer().Status.LastHeartbeat
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, lastHeartbeat, fakeSDK.DedicatedGameServer().Status.LastHeartbeat)
+}
@@ -0,0 +1,527 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: sidecar.proto
+
+package sidecarapi // import "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/sidecar/sidecarapi"
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import grpcapi "github.com/dgkanatsios/azuregameserversscalingkubernetes/pkg/apiserver/grpcapi"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Empty struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()    {}
+func (*Empty) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sidecar_6916431636db9742, []int{0}
+}
+func (m *Empty) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Empty.Unmarshal(m, b)
+}
+func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+}
+func (dst *Empty) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Empty.Merge(dst, src)
+}
+func (m *Empty) XXX_Size() int {
+	return xxx_messageInfo_Empty.Size(m)
+}
+func (m *Empty) XXX_DiscardUnknown() {
+	xxx_messageInfo_Empty.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Empty proto.InternalMessageInfo
+
+type SetStateRequest struct {
+	State                grpcapi.State `protobuf:"varint,1,opt,name=state,proto3,enum=azuregaming.apiserver.v1.State" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SetStateRequest) Reset()         { *m = SetStateRequest{} }
+func (m *SetStateRequest) String() string { return proto.CompactTextString(m) }
+func (*SetStateRequest) ProtoMessage()    {}
+func (*SetStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sidecar_6916431636db9742, []int{1}
+}
+func (m *SetStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetStateRequest.Unmarshal(m, b)
+}
+func (m *SetStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetStateRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetStateRequest.Merge(dst, src)
+}
+func (m *SetStateRequest) XXX_Size() int {
+	return xxx_messageInfo_SetStateRequest.Size(m)
+}
+func (m *SetStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetStateRequest proto.InternalMessageInfo
+
+func (m *SetStateRequest) GetState() grpcapi.State {
+	if m != nil {
+		return m.State
+	}
+	return grpcapi.State_STATE_UNSPECIFIED
+}
+
+type SetPlayersRequest struct {
+	PlayerCount          int32    `protobuf:"varint,1,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPlayersRequest) Reset()         { *m = SetPlayersRequest{} }
+func (m *SetPlayersRequest) String() string { return proto.CompactTextString(m) }
+func (*SetPlayersRequest) ProtoMessage()    {}
+func (*SetPlayersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sidecar_6916431636db9742, []int{2}
+}
+func (m *SetPlayersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPlayersRequest.Unmarshal(m, b)
+}
+func (m *SetPlayersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPlayersRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetPlayersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPlayersRequest.Merge(dst, src)
+}
+func (m *SetPlayersRequest) XXX_Size() int {
+	return xxx_messageInfo_SetPlayersRequest.Size(m)
+}
+func (m *SetPlayersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPlayersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPlayersRequest proto.InternalMessageInfo
+
+func (m *SetPlayersRequest) GetPlayerCount() int32 {
+	if m != nil {
+		return m.PlayerCount
+	}
+	return 0
+}
+
+type PlayerRequest struct {
+	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PlayerRequest) Reset()         { *m = PlayerRequest{} }
+func (m *PlayerRequest) String() string { return proto.CompactTextString(m) }
+func (*PlayerRequest) ProtoMessage()    {}
+func (*PlayerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sidecar_6916431636db9742, []int{3}
+}
+func (m *PlayerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PlayerRequest.Unmarshal(m, b)
+}
+func (m *PlayerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PlayerRequest.Marshal(b, m, deterministic)
+}
+func (dst *PlayerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PlayerRequest.Merge(dst, src)
+}
+func (m *PlayerRequest) XXX_Size() int {
+	return xxx_messageInfo_PlayerRequest.Size(m)
+}
+func (m *PlayerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PlayerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PlayerRequest proto.InternalMessageInfo
+
+func (m *PlayerRequest) GetPlayerId() string {
+	if m != nil {
+		return m.PlayerId
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "azuregaming.sidecar.v1.Empty")
+	proto.RegisterType((*SetStateRequest)(nil), "azuregaming.sidecar.v1.SetStateRequest")
+	proto.RegisterType((*SetPlayersRequest)(nil), "azuregaming.sidecar.v1.SetPlayersRequest")
+	proto.RegisterType((*PlayerRequest)(nil), "azuregaming.sidecar.v1.PlayerRequest")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// SDKClient is the client API for SDK service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SDKClient interface {
+	// Ready sets the Health to Healthy and starts the health pings
+	Ready(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Health is a health ping of the game server. Once the game server has sent one, the DedicatedGameServer is reported as Failed if the pings stop
+	Health(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// SetState sets the state of the DedicatedGameServer
+	SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*Empty, error)
+	// SetPlayers sets the number of active players of the DedicatedGameServer
+	SetPlayers(ctx context.Context, in *SetPlayersRequest, opts ...grpc.CallOption) (*Empty, error)
+	// PlayerConnect adds the player to the active players
+	PlayerConnect(ctx context.Context, in *PlayerRequest, opts ...grpc.CallOption) (*Empty, error)
+	// PlayerDisconnect removes the player from the active players
+	PlayerDisconnect(ctx context.Context, in *PlayerRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Shutdown sets MarkedForDeletion
+	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// GetDedicatedGameServer returns the DedicatedGameServer, as last seen by the sidecar
+	// It fails with UNAVAILABLE until the sidecar has read the DedicatedGameServer
+	GetDedicatedGameServer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*grpcapi.DedicatedGameServer, error)
+}
+
+type sDKClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSDKClient(cc *grpc.ClientConn) SDKClient {
+	return &sDKClient{cc}
+}
+
+func (c *sDKClient) Ready(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/Ready", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) Health(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/SetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) SetPlayers(ctx context.Context, in *SetPlayersRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/SetPlayers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) PlayerConnect(ctx context.Context, in *PlayerRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/PlayerConnect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) PlayerDisconnect(ctx context.Context, in *PlayerRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/PlayerDisconnect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/Shutdown", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sDKClient) GetDedicatedGameServer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*grpcapi.DedicatedGameServer, error) {
+	out := new(grpcapi.DedicatedGameServer)
+	err := c.cc.Invoke(ctx, "/azuregaming.sidecar.v1.SDK/GetDedicatedGameServer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SDKServer is the server API for SDK service.
+type SDKServer interface {
+	// Ready sets the Health to Healthy and starts the health pings
+	Ready(context.Context, *Empty) (*Empty, error)
+	// Health is a health ping of the game server. Once the game server has sent one, the DedicatedGameServer is reported as Failed if the pings stop
+	Health(context.Context, *Empty) (*Empty, error)
+	// SetState sets the state of the DedicatedGameServer
+	SetState(context.Context, *SetStateRequest) (*Empty, error)
+	// SetPlayers sets the number of active players of the DedicatedGameServer
+	SetPlayers(context.Context, *SetPlayersRequest) (*Empty, error)
+	// PlayerConnect adds the player to the active players
+	PlayerConnect(context.Context, *PlayerRequest) (*Empty, error)
+	// PlayerDisconnect removes the player from the active players
+	PlayerDisconnect(context.Context, *PlayerRequest) (*Empty, error)
+	// Shutdown sets MarkedForDeletion
+	Shutdown(context.Context, *Empty) (*Empty, error)
+	// GetDedicatedGameServer returns the DedicatedGameServer, as last seen by the sidecar
+	// It fails with UNAVAILABLE until the sidecar has read the DedicatedGameServer
+	GetDedicatedGameServer(context.Context, *Empty) (*grpcapi.DedicatedGameServer, error)
+}
+
+func RegisterSDKServer(s *grpc.Server, srv SDKServer) {
+	s.RegisterService(&_SDK_serviceDesc, srv)
+}
+
+func _SDK_Ready_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).Ready(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/Ready",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).Ready(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).Health(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_SetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).SetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/SetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).SetState(ctx, req.(*SetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_SetPlayers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPlayersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).SetPlayers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/SetPlayers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).SetPlayers(ctx, req.(*SetPlayersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_PlayerConnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).PlayerConnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/PlayerConnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).PlayerConnect(ctx, req.(*PlayerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_PlayerDisconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).PlayerDisconnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/PlayerDisconnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).PlayerDisconnect(ctx, req.(*PlayerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/Shutdown",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).Shutdown(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SDK_GetDedicatedGameServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SDKServer).GetDedicatedGameServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/azuregaming.sidecar.v1.SDK/GetDedicatedGameServer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SDKServer).GetDedicatedGameServer(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SDK_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "azuregaming.sidecar.v1.SDK",
+	HandlerType: (*SDKServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ready",
+			Handler:    _SDK_Ready_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _SDK_Health_Handler,
+		},
+		{
+			MethodName: "SetState",
+			Handler:    _SDK_SetState_Handler,
+		},
+		{
+			MethodName: "SetPlayers",
+			Handler:    _SDK_SetPlayers_Handler,
+		},
+		{
+			MethodName: "PlayerConnect",
+			Handler:    _SDK_PlayerConnect_Handler,
+		},
+		{
+			MethodName: "PlayerDisconnect",
+			Handler:    _SDK_PlayerDisconnect_Handler,
+		},
+		{
+			MethodName: "Shutdown",
+			Handler:    _SDK_Shutdown_Handler,
+		},
+		{
+			MethodName: "GetDedicatedGameServer",
+			Handler:    _SDK_GetDedicatedGameServer_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "sidecar.proto",
+}
+
+func init() { proto.RegisterFile("sidecar.proto", fileDescriptor_sidecar_6916431636db9742) }
+
+var fileDescriptor_sidecar_6916431636db9742 = []byte{
+	// 384 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x93, 0xe1, 0x6b, 0xda, 0x40,
+	0x18, 0xc6, 0x91, 0x11, 0xa7, 0xef, 0xe6, 0xdc, 0xee, 0x83, 0x0c, 0xc7, 0xd8, 0x16, 0x18, 0xdb,
+	0x60, 0x4b, 0xd0, 0xd1, 0x7e, 0xe9, 0xb7, 0xaa, 0xd5, 0xd2, 0x2f, 0x25, 0xa1, 0xa5, 0x48, 0xa1,
+	0x9c, 0xb9, 0xb7, 0xf1, 0xd0, 0x5c, 0xd2, 0xdc, 0x1b, 0x8b, 0xfd, 0x77, 0xfa, 0x8f, 0x16, 0x13,
+	0x83, 0xb5, 0xa8, 0xf9, 0x60, 0x3f, 0x25, 0x79, 0xee, 0x79, 0x7e, 0xe1, 0x9e, 0xbb, 0x17, 0x6a,
+	0x5a, 0x0a, 0xf4, 0x78, 0x6c, 0x45, 0x71, 0x48, 0x21, 0x6b, 0xf0, 0x87, 0x24, 0x46, 0x9f, 0x07,
+	0x52, 0xf9, 0x56, 0xbe, 0x34, 0x6b, 0x35, 0xeb, 0x3c, 0x92, 0x1a, 0xe3, 0x19, 0x2e, 0x8d, 0xe6,
+	0x5b, 0x30, 0x7a, 0x41, 0x44, 0x73, 0x73, 0x00, 0x75, 0x17, 0xc9, 0x25, 0x4e, 0xe8, 0xe0, 0x5d,
+	0x82, 0x9a, 0xd8, 0x01, 0x18, 0x7a, 0xf1, 0xfd, 0xb9, 0xf4, 0xbd, 0xf4, 0xfb, 0x43, 0xfb, 0x9b,
+	0xf5, 0x1c, 0xba, 0x02, 0xcd, 0x5a, 0x56, 0x16, 0xcb, 0xdc, 0xe6, 0x21, 0x7c, 0x72, 0x91, 0xce,
+	0xa7, 0x7c, 0x8e, 0xb1, 0xce, 0x59, 0x3f, 0xe0, 0x7d, 0x94, 0x2a, 0x37, 0x5e, 0x98, 0x28, 0x4a,
+	0x91, 0x86, 0xf3, 0x2e, 0xd3, 0x3a, 0x0b, 0xc9, 0xfc, 0x0b, 0xb5, 0x2c, 0x94, 0x67, 0xbe, 0x40,
+	0x75, 0x99, 0x91, 0x22, 0x0d, 0x54, 0x9d, 0x4a, 0x26, 0x9c, 0x8a, 0xf6, 0xa3, 0x01, 0x6f, 0xdc,
+	0xee, 0x19, 0xeb, 0x81, 0xe1, 0x20, 0x17, 0x73, 0xf6, 0xd5, 0xda, 0xbc, 0x67, 0x2b, 0xdd, 0x5f,
+	0x73, 0xf7, 0x32, 0x3b, 0x81, 0xf2, 0x00, 0xf9, 0x94, 0xc6, 0x7b, 0x72, 0x1c, 0xa8, 0xe4, 0x35,
+	0xb2, 0x5f, 0xdb, 0xac, 0x2f, 0x8a, 0x2e, 0x62, 0x5e, 0x02, 0xac, 0x0a, 0x65, 0x7f, 0x76, 0x50,
+	0xd7, 0x4b, 0x2f, 0xe2, 0x5e, 0xe4, 0x85, 0x77, 0x42, 0xa5, 0xd0, 0x23, 0xf6, 0x73, 0x9b, 0x7f,
+	0xed, 0x5c, 0x8a, 0xb0, 0x57, 0xf0, 0x31, 0xf3, 0x77, 0xa5, 0xf6, 0x5e, 0x95, 0x3c, 0x80, 0x8a,
+	0x3b, 0x4e, 0x48, 0x84, 0xf7, 0x6a, 0xcf, 0x63, 0xba, 0x85, 0x46, 0x1f, 0xa9, 0x8b, 0x42, 0x7a,
+	0x9c, 0x50, 0xf4, 0x79, 0x80, 0x6e, 0x7a, 0x9b, 0x8b, 0xb8, 0xff, 0xb6, 0x0f, 0xc1, 0x06, 0xda,
+	0xf1, 0xf5, 0x70, 0xe8, 0x4b, 0x1a, 0x27, 0x23, 0xcb, 0x0b, 0x03, 0x5b, 0xf8, 0x13, 0xae, 0x38,
+	0x69, 0x19, 0x6a, 0x3b, 0xc7, 0x60, 0x06, 0xd0, 0xda, 0xe3, 0x53, 0xa9, 0xfc, 0x49, 0x32, 0xc2,
+	0x58, 0x21, 0xa1, 0xb6, 0xa3, 0x89, 0x6f, 0x2f, 0xff, 0x9f, 0x3f, 0x79, 0x24, 0x8f, 0x56, 0xaf,
+	0xa3, 0x72, 0x3a, 0xc3, 0xff, 0x9f, 0x06, 0x00, 0x6e, 0x26, 0x6a, 0x2c, 0xfd, 0x03, 0x00, 0x00,
+}
@@ -15,6 +15,9 @@ sed -i "s#docker.io/dgkanatsios/aks_gaming_controller:$1#docker.io/dgkanatsios/a
 sed -i "s#docker.io/dgkanatsios/aks_gaming_apiserver:$1#docker.io/dgkanatsios/aks_gaming_apiserver:$2#g" $DIR/../artifacts/deploy.apiserver-controller.yaml
 sed -i "s#docker.io/dgkanatsios/aks_gaming_controller:$1#docker.io/dgkanatsios/aks_gaming_controller:$2#g" $DIR/../artifacts/deploy.apiserver-controller.no-rbac.yaml
 sed -i "s#docker.io/dgkanatsios/aks_gaming_apiserver:$1#docker.io/dgkanatsios/aks_gaming_apiserver:$2#g" $DIR/../artifacts/deploy.apiserver-controller.no-rbac.yaml
+sed -i "s#docker.io/dgkanatsios/aks_gaming_sdksidecar:$1#docker.io/dgkanatsios/aks_gaming_sdksidecar:$2#g" $DIR/../artifacts/deploy.apiserver-controller.yaml
+sed -i "s#docker.io/dgkanatsios/aks_gaming_sdksidecar:$1#docker.io/dgkanatsios/aks_gaming_sdksidecar:$2#g" $DIR/../artifacts/deploy.apiserver-controller.no-rbac.yaml
+sed -i "s#docker.io/dgkanatsios/aks_gaming_logprocessor:$1#docker.io/dgkanatsios/aks_gaming_logprocessor:$2#g" $DIR/../demos/openarena/Dockerfile
 sed -i "s/VERSION=$1/VERSION=$2/g" $DIR/../Makefile
 
 echo "Changed from $1 to $2"
\ No newline at end of file